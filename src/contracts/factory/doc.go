@@ -0,0 +1,7 @@
+// Package factory contains the Go binding of the DopamintNFTFactory contract,
+// generated with abigen from NFTFactory.abi.json
+// The ABI mirrors NFTFactoryAbi in src/contracts/abis.ts; regenerate the
+// binding after changing either
+package factory
+
+//go:generate abigen --abi NFTFactory.abi.json --pkg factory --type Factory --out factory.go
@@ -0,0 +1,1640 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package factory
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// FactoryMetaData contains all meta data concerning the Factory contract.
+var FactoryMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[],\"name\":\"InvalidInitialization\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"NotInitializing\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"OwnableInvalidOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"OwnableUnauthorizedAccount\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"newFee\",\"type\":\"uint256\"}],\"name\":\"AIGenerationFeeDefaultUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"modelId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"newFee\",\"type\":\"uint256\"}],\"name\":\"AIGenerationFeeUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"newFee\",\"type\":\"uint256\"}],\"name\":\"CreationFeeUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldPrice\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"newPrice\",\"type\":\"uint256\"}],\"name\":\"InitNFTPriceUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint64\",\"name\":\"version\",\"type\":\"uint64\"}],\"name\":\"Initialized\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"collectionId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"contractAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"creator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"symbol\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"baseURI\",\"type\":\"string\"}],\"name\":\"NFTContractCreated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"contractAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"eventType\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"generateId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"protocolFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"creatorFee\",\"type\":\"uint256\"}],\"name\":\"NFTEvent\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"oldOperator\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOperator\",\"type\":\"address\"}],\"name\":\"OperatorUpdated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"oldWallet\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newWallet\",\"type\":\"address\"}],\"name\":\"ProtocolWalletUpdated\",\"type\":\"event\"}]",
+}
+
+// FactoryABI is the input ABI used to generate the binding from.
+// Deprecated: Use FactoryMetaData.ABI instead.
+var FactoryABI = FactoryMetaData.ABI
+
+// Factory is an auto generated Go binding around an Ethereum contract.
+type Factory struct {
+	FactoryCaller     // Read-only binding to the contract
+	FactoryTransactor // Write-only binding to the contract
+	FactoryFilterer   // Log filterer for contract events
+}
+
+// FactoryCaller is an auto generated read-only Go binding around an Ethereum contract.
+type FactoryCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FactoryTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type FactoryTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FactoryFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type FactoryFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FactorySession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type FactorySession struct {
+	Contract     *Factory          // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// FactoryCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type FactoryCallerSession struct {
+	Contract *FactoryCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts  // Call options to use throughout this session
+}
+
+// FactoryTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type FactoryTransactorSession struct {
+	Contract     *FactoryTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts  // Transaction auth options to use throughout this session
+}
+
+// FactoryRaw is an auto generated low-level Go binding around an Ethereum contract.
+type FactoryRaw struct {
+	Contract *Factory // Generic contract binding to access the raw methods on
+}
+
+// FactoryCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type FactoryCallerRaw struct {
+	Contract *FactoryCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// FactoryTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type FactoryTransactorRaw struct {
+	Contract *FactoryTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewFactory creates a new instance of Factory, bound to a specific deployed contract.
+func NewFactory(address common.Address, backend bind.ContractBackend) (*Factory, error) {
+	contract, err := bindFactory(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Factory{FactoryCaller: FactoryCaller{contract: contract}, FactoryTransactor: FactoryTransactor{contract: contract}, FactoryFilterer: FactoryFilterer{contract: contract}}, nil
+}
+
+// NewFactoryCaller creates a new read-only instance of Factory, bound to a specific deployed contract.
+func NewFactoryCaller(address common.Address, caller bind.ContractCaller) (*FactoryCaller, error) {
+	contract, err := bindFactory(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryCaller{contract: contract}, nil
+}
+
+// NewFactoryTransactor creates a new write-only instance of Factory, bound to a specific deployed contract.
+func NewFactoryTransactor(address common.Address, transactor bind.ContractTransactor) (*FactoryTransactor, error) {
+	contract, err := bindFactory(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryTransactor{contract: contract}, nil
+}
+
+// NewFactoryFilterer creates a new log filterer instance of Factory, bound to a specific deployed contract.
+func NewFactoryFilterer(address common.Address, filterer bind.ContractFilterer) (*FactoryFilterer, error) {
+	contract, err := bindFactory(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryFilterer{contract: contract}, nil
+}
+
+// bindFactory binds a generic wrapper to an already deployed contract.
+func bindFactory(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := FactoryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Factory *FactoryRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Factory.Contract.FactoryCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Factory *FactoryRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Factory.Contract.FactoryTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Factory *FactoryRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Factory.Contract.FactoryTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Factory *FactoryCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Factory.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Factory *FactoryTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Factory.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Factory *FactoryTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Factory.Contract.contract.Transact(opts, method, params...)
+}
+
+// FactoryAIGenerationFeeDefaultUpdatedIterator is returned from FilterAIGenerationFeeDefaultUpdated and is used to iterate over the raw logs and unpacked data for AIGenerationFeeDefaultUpdated events raised by the Factory contract.
+type FactoryAIGenerationFeeDefaultUpdatedIterator struct {
+	Event *FactoryAIGenerationFeeDefaultUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryAIGenerationFeeDefaultUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryAIGenerationFeeDefaultUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryAIGenerationFeeDefaultUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryAIGenerationFeeDefaultUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryAIGenerationFeeDefaultUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryAIGenerationFeeDefaultUpdated represents a AIGenerationFeeDefaultUpdated event raised by the Factory contract.
+type FactoryAIGenerationFeeDefaultUpdated struct {
+	OldFee *big.Int
+	NewFee *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterAIGenerationFeeDefaultUpdated is a free log retrieval operation binding the contract event 0x7d4107492b948560f0f75d322f3476fb2a771cc4c53ad4bad01f5353b41e66a2.
+//
+// Solidity: event AIGenerationFeeDefaultUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) FilterAIGenerationFeeDefaultUpdated(opts *bind.FilterOpts) (*FactoryAIGenerationFeeDefaultUpdatedIterator, error) {
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "AIGenerationFeeDefaultUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryAIGenerationFeeDefaultUpdatedIterator{contract: _Factory.contract, event: "AIGenerationFeeDefaultUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchAIGenerationFeeDefaultUpdated is a free log subscription operation binding the contract event 0x7d4107492b948560f0f75d322f3476fb2a771cc4c53ad4bad01f5353b41e66a2.
+//
+// Solidity: event AIGenerationFeeDefaultUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) WatchAIGenerationFeeDefaultUpdated(opts *bind.WatchOpts, sink chan<- *FactoryAIGenerationFeeDefaultUpdated) (event.Subscription, error) {
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "AIGenerationFeeDefaultUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryAIGenerationFeeDefaultUpdated)
+				if err := _Factory.contract.UnpackLog(event, "AIGenerationFeeDefaultUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAIGenerationFeeDefaultUpdated is a log parse operation binding the contract event 0x7d4107492b948560f0f75d322f3476fb2a771cc4c53ad4bad01f5353b41e66a2.
+//
+// Solidity: event AIGenerationFeeDefaultUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) ParseAIGenerationFeeDefaultUpdated(log types.Log) (*FactoryAIGenerationFeeDefaultUpdated, error) {
+	event := new(FactoryAIGenerationFeeDefaultUpdated)
+	if err := _Factory.contract.UnpackLog(event, "AIGenerationFeeDefaultUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryAIGenerationFeeUpdatedIterator is returned from FilterAIGenerationFeeUpdated and is used to iterate over the raw logs and unpacked data for AIGenerationFeeUpdated events raised by the Factory contract.
+type FactoryAIGenerationFeeUpdatedIterator struct {
+	Event *FactoryAIGenerationFeeUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryAIGenerationFeeUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryAIGenerationFeeUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryAIGenerationFeeUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryAIGenerationFeeUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryAIGenerationFeeUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryAIGenerationFeeUpdated represents a AIGenerationFeeUpdated event raised by the Factory contract.
+type FactoryAIGenerationFeeUpdated struct {
+	ModelId *big.Int
+	OldFee  *big.Int
+	NewFee  *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterAIGenerationFeeUpdated is a free log retrieval operation binding the contract event 0x16d1383124edfc71e943038bfd066a925e6dd0f3704a990a8b0526b63e829af6.
+//
+// Solidity: event AIGenerationFeeUpdated(uint256 modelId, uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) FilterAIGenerationFeeUpdated(opts *bind.FilterOpts) (*FactoryAIGenerationFeeUpdatedIterator, error) {
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "AIGenerationFeeUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryAIGenerationFeeUpdatedIterator{contract: _Factory.contract, event: "AIGenerationFeeUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchAIGenerationFeeUpdated is a free log subscription operation binding the contract event 0x16d1383124edfc71e943038bfd066a925e6dd0f3704a990a8b0526b63e829af6.
+//
+// Solidity: event AIGenerationFeeUpdated(uint256 modelId, uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) WatchAIGenerationFeeUpdated(opts *bind.WatchOpts, sink chan<- *FactoryAIGenerationFeeUpdated) (event.Subscription, error) {
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "AIGenerationFeeUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryAIGenerationFeeUpdated)
+				if err := _Factory.contract.UnpackLog(event, "AIGenerationFeeUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAIGenerationFeeUpdated is a log parse operation binding the contract event 0x16d1383124edfc71e943038bfd066a925e6dd0f3704a990a8b0526b63e829af6.
+//
+// Solidity: event AIGenerationFeeUpdated(uint256 modelId, uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) ParseAIGenerationFeeUpdated(log types.Log) (*FactoryAIGenerationFeeUpdated, error) {
+	event := new(FactoryAIGenerationFeeUpdated)
+	if err := _Factory.contract.UnpackLog(event, "AIGenerationFeeUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryCreationFeeUpdatedIterator is returned from FilterCreationFeeUpdated and is used to iterate over the raw logs and unpacked data for CreationFeeUpdated events raised by the Factory contract.
+type FactoryCreationFeeUpdatedIterator struct {
+	Event *FactoryCreationFeeUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryCreationFeeUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryCreationFeeUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryCreationFeeUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryCreationFeeUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryCreationFeeUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryCreationFeeUpdated represents a CreationFeeUpdated event raised by the Factory contract.
+type FactoryCreationFeeUpdated struct {
+	OldFee *big.Int
+	NewFee *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterCreationFeeUpdated is a free log retrieval operation binding the contract event 0x5de302eeb1c80d4fb0c0953b692353f09ddf431411b8eb2034d5e85769561912.
+//
+// Solidity: event CreationFeeUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) FilterCreationFeeUpdated(opts *bind.FilterOpts) (*FactoryCreationFeeUpdatedIterator, error) {
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "CreationFeeUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryCreationFeeUpdatedIterator{contract: _Factory.contract, event: "CreationFeeUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchCreationFeeUpdated is a free log subscription operation binding the contract event 0x5de302eeb1c80d4fb0c0953b692353f09ddf431411b8eb2034d5e85769561912.
+//
+// Solidity: event CreationFeeUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) WatchCreationFeeUpdated(opts *bind.WatchOpts, sink chan<- *FactoryCreationFeeUpdated) (event.Subscription, error) {
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "CreationFeeUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryCreationFeeUpdated)
+				if err := _Factory.contract.UnpackLog(event, "CreationFeeUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCreationFeeUpdated is a log parse operation binding the contract event 0x5de302eeb1c80d4fb0c0953b692353f09ddf431411b8eb2034d5e85769561912.
+//
+// Solidity: event CreationFeeUpdated(uint256 oldFee, uint256 newFee)
+func (_Factory *FactoryFilterer) ParseCreationFeeUpdated(log types.Log) (*FactoryCreationFeeUpdated, error) {
+	event := new(FactoryCreationFeeUpdated)
+	if err := _Factory.contract.UnpackLog(event, "CreationFeeUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryInitNFTPriceUpdatedIterator is returned from FilterInitNFTPriceUpdated and is used to iterate over the raw logs and unpacked data for InitNFTPriceUpdated events raised by the Factory contract.
+type FactoryInitNFTPriceUpdatedIterator struct {
+	Event *FactoryInitNFTPriceUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryInitNFTPriceUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryInitNFTPriceUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryInitNFTPriceUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryInitNFTPriceUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryInitNFTPriceUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryInitNFTPriceUpdated represents a InitNFTPriceUpdated event raised by the Factory contract.
+type FactoryInitNFTPriceUpdated struct {
+	OldPrice *big.Int
+	NewPrice *big.Int
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitNFTPriceUpdated is a free log retrieval operation binding the contract event 0x8c718b55b7be210a30c232f30384bff430b547f24fb1fa9c42bef7d4a8350d16.
+//
+// Solidity: event InitNFTPriceUpdated(uint256 oldPrice, uint256 newPrice)
+func (_Factory *FactoryFilterer) FilterInitNFTPriceUpdated(opts *bind.FilterOpts) (*FactoryInitNFTPriceUpdatedIterator, error) {
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "InitNFTPriceUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryInitNFTPriceUpdatedIterator{contract: _Factory.contract, event: "InitNFTPriceUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchInitNFTPriceUpdated is a free log subscription operation binding the contract event 0x8c718b55b7be210a30c232f30384bff430b547f24fb1fa9c42bef7d4a8350d16.
+//
+// Solidity: event InitNFTPriceUpdated(uint256 oldPrice, uint256 newPrice)
+func (_Factory *FactoryFilterer) WatchInitNFTPriceUpdated(opts *bind.WatchOpts, sink chan<- *FactoryInitNFTPriceUpdated) (event.Subscription, error) {
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "InitNFTPriceUpdated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryInitNFTPriceUpdated)
+				if err := _Factory.contract.UnpackLog(event, "InitNFTPriceUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitNFTPriceUpdated is a log parse operation binding the contract event 0x8c718b55b7be210a30c232f30384bff430b547f24fb1fa9c42bef7d4a8350d16.
+//
+// Solidity: event InitNFTPriceUpdated(uint256 oldPrice, uint256 newPrice)
+func (_Factory *FactoryFilterer) ParseInitNFTPriceUpdated(log types.Log) (*FactoryInitNFTPriceUpdated, error) {
+	event := new(FactoryInitNFTPriceUpdated)
+	if err := _Factory.contract.UnpackLog(event, "InitNFTPriceUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryInitializedIterator is returned from FilterInitialized and is used to iterate over the raw logs and unpacked data for Initialized events raised by the Factory contract.
+type FactoryInitializedIterator struct {
+	Event *FactoryInitialized // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryInitializedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryInitialized)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryInitialized)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryInitializedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryInitializedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryInitialized represents a Initialized event raised by the Factory contract.
+type FactoryInitialized struct {
+	Version uint64
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitialized is a free log retrieval operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Factory *FactoryFilterer) FilterInitialized(opts *bind.FilterOpts) (*FactoryInitializedIterator, error) {
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "Initialized")
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryInitializedIterator{contract: _Factory.contract, event: "Initialized", logs: logs, sub: sub}, nil
+}
+
+// WatchInitialized is a free log subscription operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Factory *FactoryFilterer) WatchInitialized(opts *bind.WatchOpts, sink chan<- *FactoryInitialized) (event.Subscription, error) {
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "Initialized")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryInitialized)
+				if err := _Factory.contract.UnpackLog(event, "Initialized", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitialized is a log parse operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Factory *FactoryFilterer) ParseInitialized(log types.Log) (*FactoryInitialized, error) {
+	event := new(FactoryInitialized)
+	if err := _Factory.contract.UnpackLog(event, "Initialized", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryNFTContractCreatedIterator is returned from FilterNFTContractCreated and is used to iterate over the raw logs and unpacked data for NFTContractCreated events raised by the Factory contract.
+type FactoryNFTContractCreatedIterator struct {
+	Event *FactoryNFTContractCreated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryNFTContractCreatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryNFTContractCreated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryNFTContractCreated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryNFTContractCreatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryNFTContractCreatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryNFTContractCreated represents a NFTContractCreated event raised by the Factory contract.
+type FactoryNFTContractCreated struct {
+	CollectionId    *big.Int
+	ContractAddress common.Address
+	Creator         common.Address
+	Name            string
+	Symbol          string
+	BaseURI         string
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterNFTContractCreated is a free log retrieval operation binding the contract event 0x67d1cd8e714f3ce1835cc3191f9ea9595c4642e2137a7f18e604a6b82d59ad41.
+//
+// Solidity: event NFTContractCreated(uint256 collectionId, address indexed contractAddress, address indexed creator, string name, string symbol, string baseURI)
+func (_Factory *FactoryFilterer) FilterNFTContractCreated(opts *bind.FilterOpts, contractAddress []common.Address, creator []common.Address) (*FactoryNFTContractCreatedIterator, error) {
+
+	var contractAddressRule []interface{}
+	for _, contractAddressItem := range contractAddress {
+		contractAddressRule = append(contractAddressRule, contractAddressItem)
+	}
+	var creatorRule []interface{}
+	for _, creatorItem := range creator {
+		creatorRule = append(creatorRule, creatorItem)
+	}
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "NFTContractCreated", contractAddressRule, creatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryNFTContractCreatedIterator{contract: _Factory.contract, event: "NFTContractCreated", logs: logs, sub: sub}, nil
+}
+
+// WatchNFTContractCreated is a free log subscription operation binding the contract event 0x67d1cd8e714f3ce1835cc3191f9ea9595c4642e2137a7f18e604a6b82d59ad41.
+//
+// Solidity: event NFTContractCreated(uint256 collectionId, address indexed contractAddress, address indexed creator, string name, string symbol, string baseURI)
+func (_Factory *FactoryFilterer) WatchNFTContractCreated(opts *bind.WatchOpts, sink chan<- *FactoryNFTContractCreated, contractAddress []common.Address, creator []common.Address) (event.Subscription, error) {
+
+	var contractAddressRule []interface{}
+	for _, contractAddressItem := range contractAddress {
+		contractAddressRule = append(contractAddressRule, contractAddressItem)
+	}
+	var creatorRule []interface{}
+	for _, creatorItem := range creator {
+		creatorRule = append(creatorRule, creatorItem)
+	}
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "NFTContractCreated", contractAddressRule, creatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryNFTContractCreated)
+				if err := _Factory.contract.UnpackLog(event, "NFTContractCreated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseNFTContractCreated is a log parse operation binding the contract event 0x67d1cd8e714f3ce1835cc3191f9ea9595c4642e2137a7f18e604a6b82d59ad41.
+//
+// Solidity: event NFTContractCreated(uint256 collectionId, address indexed contractAddress, address indexed creator, string name, string symbol, string baseURI)
+func (_Factory *FactoryFilterer) ParseNFTContractCreated(log types.Log) (*FactoryNFTContractCreated, error) {
+	event := new(FactoryNFTContractCreated)
+	if err := _Factory.contract.UnpackLog(event, "NFTContractCreated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryNFTEventIterator is returned from FilterNFTEvent and is used to iterate over the raw logs and unpacked data for NFTEvent events raised by the Factory contract.
+type FactoryNFTEventIterator struct {
+	Event *FactoryNFTEvent // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryNFTEventIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryNFTEvent)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryNFTEvent)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryNFTEventIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryNFTEventIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryNFTEvent represents a NFTEvent event raised by the Factory contract.
+type FactoryNFTEvent struct {
+	ContractAddress common.Address
+	From            common.Address
+	To              common.Address
+	EventType       *big.Int
+	TokenId         *big.Int
+	GenerateId      *big.Int
+	Price           *big.Int
+	ProtocolFee     *big.Int
+	CreatorFee      *big.Int
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterNFTEvent is a free log retrieval operation binding the contract event 0x60a30daa78b5b5e15a226ec30583b945c020aa8614cce28d770af713bf4a1260.
+//
+// Solidity: event NFTEvent(address indexed contractAddress, address indexed from, address indexed to, uint256 eventType, uint256 tokenId, uint256 generateId, uint256 price, uint256 protocolFee, uint256 creatorFee)
+func (_Factory *FactoryFilterer) FilterNFTEvent(opts *bind.FilterOpts, contractAddress []common.Address, from []common.Address, to []common.Address) (*FactoryNFTEventIterator, error) {
+
+	var contractAddressRule []interface{}
+	for _, contractAddressItem := range contractAddress {
+		contractAddressRule = append(contractAddressRule, contractAddressItem)
+	}
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "NFTEvent", contractAddressRule, fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryNFTEventIterator{contract: _Factory.contract, event: "NFTEvent", logs: logs, sub: sub}, nil
+}
+
+// WatchNFTEvent is a free log subscription operation binding the contract event 0x60a30daa78b5b5e15a226ec30583b945c020aa8614cce28d770af713bf4a1260.
+//
+// Solidity: event NFTEvent(address indexed contractAddress, address indexed from, address indexed to, uint256 eventType, uint256 tokenId, uint256 generateId, uint256 price, uint256 protocolFee, uint256 creatorFee)
+func (_Factory *FactoryFilterer) WatchNFTEvent(opts *bind.WatchOpts, sink chan<- *FactoryNFTEvent, contractAddress []common.Address, from []common.Address, to []common.Address) (event.Subscription, error) {
+
+	var contractAddressRule []interface{}
+	for _, contractAddressItem := range contractAddress {
+		contractAddressRule = append(contractAddressRule, contractAddressItem)
+	}
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "NFTEvent", contractAddressRule, fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryNFTEvent)
+				if err := _Factory.contract.UnpackLog(event, "NFTEvent", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseNFTEvent is a log parse operation binding the contract event 0x60a30daa78b5b5e15a226ec30583b945c020aa8614cce28d770af713bf4a1260.
+//
+// Solidity: event NFTEvent(address indexed contractAddress, address indexed from, address indexed to, uint256 eventType, uint256 tokenId, uint256 generateId, uint256 price, uint256 protocolFee, uint256 creatorFee)
+func (_Factory *FactoryFilterer) ParseNFTEvent(log types.Log) (*FactoryNFTEvent, error) {
+	event := new(FactoryNFTEvent)
+	if err := _Factory.contract.UnpackLog(event, "NFTEvent", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryOperatorUpdatedIterator is returned from FilterOperatorUpdated and is used to iterate over the raw logs and unpacked data for OperatorUpdated events raised by the Factory contract.
+type FactoryOperatorUpdatedIterator struct {
+	Event *FactoryOperatorUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryOperatorUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryOperatorUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryOperatorUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryOperatorUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryOperatorUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryOperatorUpdated represents a OperatorUpdated event raised by the Factory contract.
+type FactoryOperatorUpdated struct {
+	OldOperator common.Address
+	NewOperator common.Address
+	Raw         types.Log // Blockchain specific contextual infos
+}
+
+// FilterOperatorUpdated is a free log retrieval operation binding the contract event 0xfbe5b6cbafb274f445d7fed869dc77a838d8243a22c460de156560e8857cad03.
+//
+// Solidity: event OperatorUpdated(address indexed oldOperator, address indexed newOperator)
+func (_Factory *FactoryFilterer) FilterOperatorUpdated(opts *bind.FilterOpts, oldOperator []common.Address, newOperator []common.Address) (*FactoryOperatorUpdatedIterator, error) {
+
+	var oldOperatorRule []interface{}
+	for _, oldOperatorItem := range oldOperator {
+		oldOperatorRule = append(oldOperatorRule, oldOperatorItem)
+	}
+	var newOperatorRule []interface{}
+	for _, newOperatorItem := range newOperator {
+		newOperatorRule = append(newOperatorRule, newOperatorItem)
+	}
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "OperatorUpdated", oldOperatorRule, newOperatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryOperatorUpdatedIterator{contract: _Factory.contract, event: "OperatorUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchOperatorUpdated is a free log subscription operation binding the contract event 0xfbe5b6cbafb274f445d7fed869dc77a838d8243a22c460de156560e8857cad03.
+//
+// Solidity: event OperatorUpdated(address indexed oldOperator, address indexed newOperator)
+func (_Factory *FactoryFilterer) WatchOperatorUpdated(opts *bind.WatchOpts, sink chan<- *FactoryOperatorUpdated, oldOperator []common.Address, newOperator []common.Address) (event.Subscription, error) {
+
+	var oldOperatorRule []interface{}
+	for _, oldOperatorItem := range oldOperator {
+		oldOperatorRule = append(oldOperatorRule, oldOperatorItem)
+	}
+	var newOperatorRule []interface{}
+	for _, newOperatorItem := range newOperator {
+		newOperatorRule = append(newOperatorRule, newOperatorItem)
+	}
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "OperatorUpdated", oldOperatorRule, newOperatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryOperatorUpdated)
+				if err := _Factory.contract.UnpackLog(event, "OperatorUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOperatorUpdated is a log parse operation binding the contract event 0xfbe5b6cbafb274f445d7fed869dc77a838d8243a22c460de156560e8857cad03.
+//
+// Solidity: event OperatorUpdated(address indexed oldOperator, address indexed newOperator)
+func (_Factory *FactoryFilterer) ParseOperatorUpdated(log types.Log) (*FactoryOperatorUpdated, error) {
+	event := new(FactoryOperatorUpdated)
+	if err := _Factory.contract.UnpackLog(event, "OperatorUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the Factory contract.
+type FactoryOwnershipTransferredIterator struct {
+	Event *FactoryOwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryOwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryOwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryOwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryOwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryOwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryOwnershipTransferred represents a OwnershipTransferred event raised by the Factory contract.
+type FactoryOwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Factory *FactoryFilterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*FactoryOwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryOwnershipTransferredIterator{contract: _Factory.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Factory *FactoryFilterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *FactoryOwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryOwnershipTransferred)
+				if err := _Factory.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Factory *FactoryFilterer) ParseOwnershipTransferred(log types.Log) (*FactoryOwnershipTransferred, error) {
+	event := new(FactoryOwnershipTransferred)
+	if err := _Factory.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FactoryProtocolWalletUpdatedIterator is returned from FilterProtocolWalletUpdated and is used to iterate over the raw logs and unpacked data for ProtocolWalletUpdated events raised by the Factory contract.
+type FactoryProtocolWalletUpdatedIterator struct {
+	Event *FactoryProtocolWalletUpdated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FactoryProtocolWalletUpdatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FactoryProtocolWalletUpdated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FactoryProtocolWalletUpdated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FactoryProtocolWalletUpdatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FactoryProtocolWalletUpdatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FactoryProtocolWalletUpdated represents a ProtocolWalletUpdated event raised by the Factory contract.
+type FactoryProtocolWalletUpdated struct {
+	OldWallet common.Address
+	NewWallet common.Address
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterProtocolWalletUpdated is a free log retrieval operation binding the contract event 0x82034a08527e1556af5c41ac50e5176dc09e241059cf64334802c9ab02935aee.
+//
+// Solidity: event ProtocolWalletUpdated(address indexed oldWallet, address indexed newWallet)
+func (_Factory *FactoryFilterer) FilterProtocolWalletUpdated(opts *bind.FilterOpts, oldWallet []common.Address, newWallet []common.Address) (*FactoryProtocolWalletUpdatedIterator, error) {
+
+	var oldWalletRule []interface{}
+	for _, oldWalletItem := range oldWallet {
+		oldWalletRule = append(oldWalletRule, oldWalletItem)
+	}
+	var newWalletRule []interface{}
+	for _, newWalletItem := range newWallet {
+		newWalletRule = append(newWalletRule, newWalletItem)
+	}
+
+	logs, sub, err := _Factory.contract.FilterLogs(opts, "ProtocolWalletUpdated", oldWalletRule, newWalletRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FactoryProtocolWalletUpdatedIterator{contract: _Factory.contract, event: "ProtocolWalletUpdated", logs: logs, sub: sub}, nil
+}
+
+// WatchProtocolWalletUpdated is a free log subscription operation binding the contract event 0x82034a08527e1556af5c41ac50e5176dc09e241059cf64334802c9ab02935aee.
+//
+// Solidity: event ProtocolWalletUpdated(address indexed oldWallet, address indexed newWallet)
+func (_Factory *FactoryFilterer) WatchProtocolWalletUpdated(opts *bind.WatchOpts, sink chan<- *FactoryProtocolWalletUpdated, oldWallet []common.Address, newWallet []common.Address) (event.Subscription, error) {
+
+	var oldWalletRule []interface{}
+	for _, oldWalletItem := range oldWallet {
+		oldWalletRule = append(oldWalletRule, oldWalletItem)
+	}
+	var newWalletRule []interface{}
+	for _, newWalletItem := range newWallet {
+		newWalletRule = append(newWalletRule, newWalletItem)
+	}
+
+	logs, sub, err := _Factory.contract.WatchLogs(opts, "ProtocolWalletUpdated", oldWalletRule, newWalletRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FactoryProtocolWalletUpdated)
+				if err := _Factory.contract.UnpackLog(event, "ProtocolWalletUpdated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseProtocolWalletUpdated is a log parse operation binding the contract event 0x82034a08527e1556af5c41ac50e5176dc09e241059cf64334802c9ab02935aee.
+//
+// Solidity: event ProtocolWalletUpdated(address indexed oldWallet, address indexed newWallet)
+func (_Factory *FactoryFilterer) ParseProtocolWalletUpdated(log types.Log) (*FactoryProtocolWalletUpdated, error) {
+	event := new(FactoryProtocolWalletUpdated)
+	if err := _Factory.contract.UnpackLog(event, "ProtocolWalletUpdated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
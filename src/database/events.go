@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultEventsCollection is the default collection holding NFT mint and transfer events
+const defaultEventsCollection = "events"
+
+// defaultProcessedLogsCollection is the default collection recording logs already handled
+const defaultProcessedLogsCollection = "processed_logs"
+
+// processedLogsIndex makes marking a log processed twice a duplicate key error
+var processedLogsIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "chainId", Value: 1}, {Key: "txHash", Value: 1}, {Key: "logIndex", Value: 1}},
+	Options: options.Index().SetName("chainId_txHash_logIndex").SetUnique(true),
+}
+
+// NFT event types
+const (
+	EventTypeMint     = "mint"
+	EventTypeTransfer = "transfer"
+	EventTypeBurn     = "burn"
+)
+
+// NFTEventDocument represents an NFT mint, transfer or burn event in MongoDB
+// TokenID is stored as a decimal string since token IDs are uint256
+type NFTEventDocument struct {
+	ID              interface{} `bson:"_id,omitempty"`
+	ContractAddress string      `bson:"contractAddress"`
+	TokenID         string      `bson:"tokenId"`
+	EventType       string      `bson:"eventType"`
+	From            string      `bson:"from"`
+	To              string      `bson:"to"`
+	BlockNumber     uint64      `bson:"blockNumber"`
+	TxHash          string      `bson:"txHash"`
+	LogIndex        uint        `bson:"logIndex"`
+	Timestamp       time.Time   `bson:"timestamp"`
+	ChainID         int64       `bson:"chainId"`
+}
+
+// events returns the NFT events collection
+func (m *DopamintMongoClient) events() *mongo.Collection {
+	return m.collection(m.config.EventsCollection)
+}
+
+// processedLogs returns the processed logs collection
+func (m *DopamintMongoClient) processedLogs() *mongo.Collection {
+	return m.collection(m.config.ProcessedLogsCollection)
+}
+
+// legacyEventIndex is the unique events and processed logs index from before
+// chainId was part of the key; it made the same tx hash and log index on two
+// chains collide
+const legacyEventIndex = "txHash_logIndex"
+
+// Server error codes of dropping an index that does not exist
+var indexNotFoundErrorCodes = []int{
+	26, // NamespaceNotFound
+	27, // IndexNotFound
+}
+
+// ensureEventIndexes creates the indexes of the events and processed logs
+// collections; their unique chainId/txHash/logIndex indexes make re-processing
+// a log a no-op
+func (m *DopamintMongoClient) ensureEventIndexes(ctx context.Context) ([]string, error) {
+	if err := dropIndexIfExists(ctx, m.events(), legacyEventIndex); err != nil {
+		return nil, err
+	}
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "chainId", Value: 1}, {Key: "txHash", Value: 1}, {Key: "logIndex", Value: 1}},
+			Options: options.Index().SetName("chainId_txHash_logIndex").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "contractAddress", Value: 1}, {Key: "tokenId", Value: 1}},
+			Options: options.Index().SetName("contractAddress_tokenId"),
+		},
+		{
+			Keys:    bson.D{{Key: "chainId", Value: 1}, {Key: "blockNumber", Value: 1}},
+			Options: options.Index().SetName("chainId_blockNumber"),
+		},
+	}
+
+	names, err := m.events().Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := m.ensureProcessedLogsIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(names, m.config.ProcessedLogsCollection+"."+name), nil
+}
+
+// ensureProcessedLogsIndex replaces the legacy processed logs index with
+// processedLogsIndex, returning its name
+func (m *DopamintMongoClient) ensureProcessedLogsIndex(ctx context.Context) (string, error) {
+	if err := dropIndexIfExists(ctx, m.processedLogs(), legacyEventIndex); err != nil {
+		return "", err
+	}
+
+	name, err := m.processedLogs().Indexes().CreateOne(ctx, processedLogsIndex)
+	if err != nil {
+		return "", err
+	}
+	m.processedIndex.Store(true)
+
+	return name, nil
+}
+
+// dropIndexIfExists drops the named index of coll, ignoring a missing index
+// or collection
+func dropIndexIfExists(ctx context.Context, coll *mongo.Collection, name string) error {
+	_, err := coll.Indexes().DropOne(ctx, name)
+	if err == nil {
+		return nil
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range indexNotFoundErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("failed to drop index %s.%s: %w", coll.Name(), name, err)
+}
+
+// normalizeEvent lowercases the addresses and hash of an event document
+func normalizeEvent(event NFTEventDocument) NFTEventDocument {
+	event.ContractAddress = NormalizeAddress(event.ContractAddress)
+	event.From = NormalizeAddress(event.From)
+	event.To = NormalizeAddress(event.To)
+	event.TxHash = normalizeHash(event.TxHash)
+	return event
+}
+
+// normalizeHash returns the canonical form used to store transaction hashes
+func normalizeHash(hash string) string {
+	return strings.ToLower(strings.TrimSpace(hash))
+}
+
+// InsertNFTEvent stores an NFT event
+// Returns false without an error if the event was already stored
+func (m *DopamintMongoClient) InsertNFTEvent(ctx context.Context, event NFTEventDocument) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := m.events().InsertOne(ctx, normalizeEvent(event)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	return true, nil
+}
+
+// BulkInsertNFTEvents stores multiple NFT events in a single unordered write
+// Events that were already stored are skipped
+// Returns the number of events actually inserted, also when the write fails
+// for some of them
+func (m *DopamintMongoClient) BulkInsertNFTEvents(ctx context.Context, events []NFTEventDocument) (int64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	docs := make([]interface{}, len(events))
+	for i, event := range events {
+		docs[i] = normalizeEvent(event)
+	}
+
+	result, err := m.events().InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return int64(len(result.InsertedIDs)), nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || result == nil {
+		return 0, fmt.Errorf("failed to insert events: %w", err)
+	}
+
+	// An unordered write attempts every document, so all those without a
+	// write error were inserted
+	inserted := int64(len(result.InsertedIDs) - len(bulkErr.WriteErrors))
+
+	// Duplicates are expected when re-processing; anything else is a failure
+	if bulkErr.WriteConcernError != nil {
+		return inserted, fmt.Errorf("failed to insert events: %w", err)
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return inserted, fmt.Errorf("failed to insert events: %w", err)
+		}
+	}
+
+	return inserted, nil
+}
+
+// DeleteEventsAboveBlock removes the events of a chain stored for blocks
+// after blockNumber, so the range can be re-indexed cleanly after a reorg
+// Returns the number of deleted events
+func (m *DopamintMongoClient) DeleteEventsAboveBlock(ctx context.Context, chainID int64, blockNumber uint64) (int64, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"chainId":     chainID,
+		"blockNumber": bson.M{"$gt": blockNumber},
+	}
+
+	result, err := m.events().DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events above block %d: %w", blockNumber, err)
+	}
+
+	m.logger.Info("Deleted events above block", "chainId", chainID, "block", blockNumber, "deleted", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+// MarkLogProcessed records that a log of a chain has been handled, so retries
+// and reorg recovery can skip logs they already processed
+// Returns true if the log had already been marked before this call. The
+// unique index the check relies on is created first if EnsureIndexes has not
+// run, failing the call when it cannot be
+func (m *DopamintMongoClient) MarkLogProcessed(ctx context.Context, chainID int64, txHash common.Hash, logIndex uint) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if !m.processedIndex.Load() {
+		if _, err := m.ensureProcessedLogsIndex(ctx); err != nil {
+			return false, fmt.Errorf("failed to ensure processed logs index: %w", err)
+		}
+	}
+
+	doc := bson.M{
+		"chainId":     chainID,
+		"txHash":      normalizeHash(txHash.Hex()),
+		"logIndex":    logIndex,
+		"processedAt": time.Now(),
+	}
+
+	if _, err := m.processedLogs().InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to mark log processed: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestNormalizeEvent(t *testing.T) {
+	event := normalizeEvent(NFTEventDocument{
+		ContractAddress: "0xABCDEF0000000000000000000000000000000001",
+		From:            "0x0000000000000000000000000000000000000000",
+		To:              " 0xABCDEF0000000000000000000000000000000002 ",
+		TxHash:          " 0xABCD ",
+	})
+
+	want := NFTEventDocument{
+		ContractAddress: "0xabcdef0000000000000000000000000000000001",
+		From:            "0x0000000000000000000000000000000000000000",
+		To:              "0xabcdef0000000000000000000000000000000002",
+		TxHash:          "0xabcd",
+	}
+	if event != want {
+		t.Errorf("normalizeEvent() = %+v, want %+v", event, want)
+	}
+}
+
+// testEvent returns a mint on chain 8453 with log index n of one transaction
+func testEvent(n uint) NFTEventDocument {
+	return NFTEventDocument{
+		ContractAddress: fmt.Sprintf("0x%040x", 1),
+		TokenID:         fmt.Sprint(n),
+		EventType:       EventTypeMint,
+		From:            fmt.Sprintf("0x%040x", 0),
+		To:              fmt.Sprintf("0x%040x", 0xb0b),
+		BlockNumber:     100,
+		TxHash:          fmt.Sprintf("0x%064x", 0xabc),
+		LogIndex:        n,
+		ChainID:         8453,
+	}
+}
+
+func TestInsertNFTEventTwiceIsNoOp(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+	if err := m.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	otherChain := testEvent(0)
+	otherChain.ChainID = 84532
+	upperCase := testEvent(0)
+	upperCase.TxHash = fmt.Sprintf("0x%064X", 0xabc)
+
+	tests := []struct {
+		name  string
+		event NFTEventDocument
+		want  bool
+	}{
+		{name: "first insert", event: testEvent(0), want: true},
+		{name: "same event", event: testEvent(0), want: false},
+		{name: "same event with another hash casing", event: upperCase, want: false},
+		{name: "another log of the transaction", event: testEvent(1), want: true},
+		{name: "same log on another chain", event: otherChain, want: true},
+	}
+
+	for _, tt := range tests {
+		inserted, err := m.InsertNFTEvent(ctx, tt.event)
+		if err != nil {
+			t.Fatalf("%s: InsertNFTEvent: %v", tt.name, err)
+		}
+		if inserted != tt.want {
+			t.Errorf("%s: InsertNFTEvent = %v, want %v", tt.name, inserted, tt.want)
+		}
+	}
+
+	count, err := m.events().CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("CountDocuments: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("stored %d events, want 3", count)
+	}
+}
+
+func TestBulkInsertNFTEventsTwiceIsNoOp(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+	if err := m.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		events []NFTEventDocument
+		want   int64
+	}{
+		{name: "new events", events: []NFTEventDocument{testEvent(0), testEvent(1), testEvent(2)}, want: 3},
+		{name: "same events again", events: []NFTEventDocument{testEvent(0), testEvent(1), testEvent(2)}, want: 0},
+		{name: "partly stored", events: []NFTEventDocument{testEvent(2), testEvent(3)}, want: 1},
+		{name: "empty batch", want: 0},
+	}
+
+	for _, tt := range tests {
+		inserted, err := m.BulkInsertNFTEvents(ctx, tt.events)
+		if err != nil {
+			t.Fatalf("%s: BulkInsertNFTEvents: %v", tt.name, err)
+		}
+		if inserted != tt.want {
+			t.Errorf("%s: BulkInsertNFTEvents = %d, want %d", tt.name, inserted, tt.want)
+		}
+	}
+
+	count, err := m.events().CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("CountDocuments: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("stored %d events, want 4", count)
+	}
+}
+
+func TestEnsureIndexesDropsLegacyEventIndex(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	legacy := mongo.IndexModel{
+		Keys:    bson.D{{Key: "txHash", Value: 1}, {Key: "logIndex", Value: 1}},
+		Options: options.Index().SetName(legacyEventIndex).SetUnique(true),
+	}
+	if _, err := m.events().Indexes().CreateOne(ctx, legacy); err != nil {
+		t.Fatalf("create legacy index: %v", err)
+	}
+	if err := m.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	// The legacy index would reject the same log on a second chain
+	event := testEvent(0)
+	for _, chainID := range []int64{8453, 84532} {
+		event.ChainID = chainID
+		inserted, err := m.InsertNFTEvent(ctx, event)
+		if err != nil || !inserted {
+			t.Fatalf("InsertNFTEvent on chain %d = %v, %v, want inserted", chainID, inserted, err)
+		}
+	}
+}
+
+func TestMarkLogProcessed(t *testing.T) {
+	// EnsureIndexes is not called, so the first mark creates the index
+	m := newTestClient(t)
+	ctx := context.Background()
+	txHash := common.HexToHash("0xabc")
+
+	tests := []struct {
+		name     string
+		chainID  int64
+		txHash   common.Hash
+		logIndex uint
+		want     bool
+	}{
+		{name: "first mark", chainID: 8453, txHash: txHash, logIndex: 0, want: false},
+		{name: "second mark", chainID: 8453, txHash: txHash, logIndex: 0, want: true},
+		{name: "another log index", chainID: 8453, txHash: txHash, logIndex: 1, want: false},
+		{name: "another transaction", chainID: 8453, txHash: common.HexToHash("0xdef"), logIndex: 0, want: false},
+		{name: "same log on another chain", chainID: 84532, txHash: txHash, logIndex: 0, want: false},
+		{name: "second mark on another chain", chainID: 84532, txHash: txHash, logIndex: 0, want: true},
+	}
+
+	for _, tt := range tests {
+		got, err := m.MarkLogProcessed(ctx, tt.chainID, tt.txHash, tt.logIndex)
+		if err != nil {
+			t.Fatalf("%s: MarkLogProcessed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: MarkLogProcessed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
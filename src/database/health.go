@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+)
+
+// reconnectAfterFailures is the number of consecutive failed pings after
+// which the health check replaces the client with a freshly dialed one
+const reconnectAfterFailures = 3
+
+// defaultHealthCheckInterval is used when StartHealthCheck gets a non-positive interval
+const defaultHealthCheckInterval = 30 * time.Second
+
+// IsHealthy reports whether the last health check ping succeeded
+// It is true right after connecting and before any health check has run
+func (m *DopamintMongoClient) IsHealthy() bool {
+	return m.healthy.Load()
+}
+
+// StartHealthCheck pings MongoDB every interval in a background goroutine
+// After several consecutive failures it dials a new client and swaps it in,
+// so a connection the driver has given up on is replaced
+// The returned channel is closed once the goroutine exits after ctx is cancelled
+func (m *DopamintMongoClient) StartHealthCheck(ctx context.Context, interval time.Duration) <-chan struct{} {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		failures := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := m.Ping(ctx)
+			if err == nil {
+				if !m.healthy.Swap(true) {
+					m.logger.Info("Connection healthy again")
+				}
+				failures = 0
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			failures++
+			m.healthy.Store(false)
+			m.logger.Warn("Health check failed", "consecutiveFailures", failures, "error", err)
+
+			if failures >= reconnectAfterFailures {
+				if err := m.reconnect(ctx); err != nil {
+					m.logger.Error("Reconnect failed", "error", err)
+					continue
+				}
+				failures = 0
+				m.healthy.Store(true)
+			}
+		}
+	}()
+
+	return done
+}
+
+// Ping checks that the current connection reaches the server
+func (m *DopamintMongoClient) Ping(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.connMu.RLock()
+	client := m.client
+	m.connMu.RUnlock()
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w: %w", errdefs.ErrConnection, err)
+	}
+	return nil
+}
+
+// reconnect dials a new client and swaps it in, disconnecting the old one
+// Change streams still open on the old client end with it and are reopened
+// on the new one by WatchNFTContractsWithOptions
+func (m *DopamintMongoClient) reconnect(ctx context.Context) error {
+	client, err := connectMongo(m.config)
+	if err != nil {
+		return err
+	}
+
+	previous := m.setClient(client)
+	if previous != nil {
+		disconnectCtx, cancel := m.withTimeout(ctx)
+		defer cancel()
+		if err := previous.Disconnect(disconnectCtx); err != nil {
+			m.logger.Warn("Failed to disconnect previous client", "error", err)
+		}
+	}
+
+	m.logger.Info("Reconnected", "database", m.config.Database, "collection", m.config.ContractsCollection)
+	return nil
+}
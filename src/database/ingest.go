@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+)
+
+// NewNFTContractDocument builds the document of a contract discovered through
+// an NFTContractCreated event, including the discovery provenance
+func NewNFTContractDocument(event *filters.NFTContractCreatedEvent, chainID int64, network string) NFTContractDocument {
+	doc := NFTContractDocument{
+		ContractAddress:   NormalizeAddress(event.ContractAddress.Hex()),
+		Creator:           NormalizeAddress(event.Creator.Hex()),
+		Name:              event.Name,
+		Symbol:            event.Symbol,
+		BaseURI:           event.BaseURI,
+		ChainID:           chainID,
+		Network:           network,
+		Status:            StatusActive,
+		CreatedAt:         time.Now(),
+		SourceBlock:       event.BlockNumber,
+		DiscoveryBlock:    event.BlockNumber,
+		DiscoveryTxHash:   strings.ToLower(event.TxHash.Hex()),
+		DiscoveryLogIndex: event.LogIndex,
+	}
+	if event.CollectionID != nil && event.CollectionID.IsInt64() {
+		doc.CollectionID = event.CollectionID.Int64()
+	}
+
+	return doc
+}
+
+// backendOwnedFields are managed by the backend once it stores a contract,
+// so discovery writes only set them on contracts it has not stored yet
+var backendOwnedFields = []string{"status", "modelId", "name", "symbol", "baseURI", "network"}
+
+// UpsertDiscoveredContract stores a contract discovered through an
+// NFTContractCreated event, unless a newer write for it already exists
+// Contracts the backend already stored keep their backend-owned fields, see
+// backendOwnedFields. Returns whether the write was applied
+func (m *DopamintMongoClient) UpsertDiscoveredContract(ctx context.Context, event *filters.NFTContractCreatedEvent, chainID int64, network string) (bool, error) {
+	doc := NewNFTContractDocument(event, chainID, network)
+	filter, update, err := contractUpsert(doc, backendOwnedFields...)
+	if err != nil {
+		return false, err
+	}
+	return m.upsertIfNewer(ctx, doc, filter, update)
+}
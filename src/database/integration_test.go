@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+)
+
+// mongoTestURIEnv names the environment variable with the URI of the MongoDB
+// server used by the integration tests, which are skipped when it is unset
+const mongoTestURIEnv = "MONGODB_TEST_URI"
+
+// newTestClient connects to the test server with a fresh database that is
+// dropped when the test ends
+func newTestClient(t *testing.T) *DopamintMongoClient {
+	t.Helper()
+
+	uri := os.Getenv(mongoTestURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", mongoTestURIEnv)
+	}
+
+	m, err := NewDopamintMongoClient(MongoDBConfig{
+		URI:                    uri,
+		Database:               fmt.Sprintf("dopamint_test_%d", time.Now().UnixNano()),
+		Collection:             "nft_contracts",
+		ConnectTimeout:         5 * time.Second,
+		ServerSelectionTimeout: 5 * time.Second,
+		Logger:                 logging.New(io.Discard, "test", slog.LevelDebug),
+	})
+	if err != nil {
+		t.Fatalf("NewDopamintMongoClient: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if err := m.db().Drop(ctx); err != nil {
+			t.Errorf("failed to drop test database: %v", err)
+		}
+		m.Close(ctx)
+	})
+	return m
+}
+
+// upsertContracts stores contracts through UpsertNFTContract
+func upsertContracts(t *testing.T, m *DopamintMongoClient, contracts ...NFTContractDocument) {
+	t.Helper()
+
+	for _, contract := range contracts {
+		if err := m.UpsertNFTContract(context.Background(), contract); err != nil {
+			t.Fatalf("UpsertNFTContract(%s): %v", contract.ContractAddress, err)
+		}
+	}
+}
+
+// testContract returns an active contract on chain 8453 with address n
+func testContract(n int) NFTContractDocument {
+	return NFTContractDocument{
+		ContractAddress: fmt.Sprintf("0x%040x", n),
+		Creator:         fmt.Sprintf("0x%040x", 0xc0de),
+		ChainID:         8453,
+		Status:          StatusActive,
+	}
+}
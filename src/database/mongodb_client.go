@@ -2,29 +2,155 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
 	"github.com/ethereum/go-ethereum/common"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 // MongoDBConfig holds MongoDB connection configuration
 type MongoDBConfig struct {
-	URI            string
-	Database       string
-	Collection     string
-	ConnectTimeout time.Duration
+	URI                     string
+	Database                string
+	Collection              string // contracts collection, kept for compatibility
+	ContractsCollection     string // defaults to Collection
+	EventsCollection        string // defaults to "events"
+	CheckpointsCollection   string // defaults to "checkpoints"
+	ProcessedLogsCollection string // defaults to "processed_logs"
+	ConnectTimeout          time.Duration
+	MaxPoolSize             uint64
+	MinPoolSize             uint64
+	MaxConnIdleTime         time.Duration
+	ServerSelectionTimeout  time.Duration
+	OperationTimeout        time.Duration // upper bound for a single query
+	TextSearch              bool          // create a text index on name/symbol and search with it
+	// WriteConcern and ReadPreference override the URI and driver defaults
+	// when set
+	WriteConcern   *writeconcern.WriteConcern
+	ReadPreference *readpref.ReadPref
+	// Logger receives the client's log output; defaults to logging.Default("MongoDB")
+	Logger logging.Logger
+}
+
+// Defaults applied to zero-valued MongoDBConfig fields
+const (
+	defaultConnectTimeout         = 10 * time.Second
+	defaultMaxPoolSize            = 100
+	defaultMaxConnIdleTime        = 5 * time.Minute
+	defaultServerSelectionTimeout = 30 * time.Second
+	defaultOperationTimeout       = 30 * time.Second
+)
+
+// withDefaults returns a copy of the config with zero values replaced by defaults
+func (c MongoDBConfig) withDefaults() MongoDBConfig {
+	if c.ConnectTimeout == 0 {
+		c.ConnectTimeout = defaultConnectTimeout
+	}
+	if c.MaxPoolSize == 0 {
+		c.MaxPoolSize = defaultMaxPoolSize
+	}
+	if c.MaxConnIdleTime == 0 {
+		c.MaxConnIdleTime = defaultMaxConnIdleTime
+	}
+	if c.ServerSelectionTimeout == 0 {
+		c.ServerSelectionTimeout = defaultServerSelectionTimeout
+	}
+	if c.OperationTimeout == 0 {
+		c.OperationTimeout = defaultOperationTimeout
+	}
+	if c.Logger == nil {
+		c.Logger = logging.Default("MongoDB")
+	}
+	if c.ContractsCollection == "" {
+		c.ContractsCollection = c.Collection
+	}
+	if c.Collection == "" {
+		c.Collection = c.ContractsCollection
+	}
+	if c.EventsCollection == "" {
+		c.EventsCollection = defaultEventsCollection
+	}
+	if c.CheckpointsCollection == "" {
+		c.CheckpointsCollection = defaultCheckpointsCollection
+	}
+	if c.ProcessedLogsCollection == "" {
+		c.ProcessedLogsCollection = defaultProcessedLogsCollection
+	}
+	return c
+}
+
+// clientOptions builds the driver options for a config
+func (c MongoDBConfig) clientOptions() *options.ClientOptions {
+	opts := options.Client().
+		ApplyURI(c.URI).
+		SetConnectTimeout(c.ConnectTimeout).
+		SetMaxPoolSize(c.MaxPoolSize).
+		SetMinPoolSize(c.MinPoolSize).
+		SetMaxConnIdleTime(c.MaxConnIdleTime).
+		SetServerSelectionTimeout(c.ServerSelectionTimeout)
+
+	if c.WriteConcern != nil {
+		opts.SetWriteConcern(c.WriteConcern)
+	}
+	if c.ReadPreference != nil {
+		opts.SetReadPreference(c.ReadPreference)
+	}
+	return opts
+}
+
+// QueryOption configures a single read query
+type QueryOption func(*queryOptions)
+
+// queryOptions holds the per-query settings applied by QueryOption
+type queryOptions struct {
+	readPreference *readpref.ReadPref
+}
+
+// WithReadPreference routes a read query according to rp, e.g.
+// readpref.SecondaryPreferred() to offload stats and listings from the primary
+func WithReadPreference(rp *readpref.ReadPref) QueryOption {
+	return func(q *queryOptions) {
+		q.readPreference = rp
+	}
+}
+
+// readCollection returns the contracts collection configured for a read query
+func (m *DopamintMongoClient) readCollection(opts []QueryOption) *mongo.Collection {
+	var q queryOptions
+	for _, opt := range opts {
+		opt(&q)
+	}
+
+	if q.readPreference == nil {
+		return m.contracts()
+	}
+	return m.db().Collection(m.config.ContractsCollection, options.Collection().SetReadPreference(q.readPreference))
 }
 
 // DopamintMongoClient manages MongoDB connection for Dopamint data
 type DopamintMongoClient struct {
-	client     *mongo.Client
-	database   *mongo.Database
-	collection *mongo.Collection
-	config     MongoDBConfig
+	connMu         sync.RWMutex // guards the connection, which may be replaced on reconnect
+	client         *mongo.Client
+	database       *mongo.Database
+	collections    map[string]*mongo.Collection // handles of the current connection by name
+	connGen        uint64                       // bumped every time the connection is replaced
+	config         MongoDBConfig
+	logger         logging.Logger
+	healthy        atomic.Bool
+	uniqueIndex    atomic.Bool // contractUniqueIndex is known to exist
+	processedIndex atomic.Bool // processedLogsIndex is known to exist
 }
 
 // NFTContractDocument represents the NFT contract document in MongoDB
@@ -41,77 +167,305 @@ type NFTContractDocument struct {
 	UpdatedAt       time.Time   `bson:"updatedAt"`
 	ChainID         int64       `bson:"chainId"`
 	Network         string      `bson:"network"`
-	Status          string      `bson:"status"` // active, inactive, etc.
+	Status          string      `bson:"status"`      // active, inactive, etc.
+	Version         int64       `bson:"version"`     // incremented on every update
+	SourceBlock     uint64      `bson:"sourceBlock"` // block the data was derived from; only moves forward
+
+	// Provenance of the NFTContractCreated log that discovered the contract
+	DiscoveryBlock    uint64 `bson:"discoveryBlock,omitempty"`
+	DiscoveryTxHash   string `bson:"discoveryTxHash,omitempty"`
+	DiscoveryLogIndex uint   `bson:"discoveryLogIndex,omitempty"`
+
+	// LastActivityBlock is the latest block at which the contract emitted an
+	// indexed log; it only moves forward, see UpdateLastActivity
+	LastActivityBlock uint64 `bson:"lastActivityBlock,omitempty"`
 }
 
 // NewDopamintMongoClient creates a new MongoDB client
 func NewDopamintMongoClient(config MongoDBConfig) (*DopamintMongoClient, error) {
+	config = config.withDefaults()
+
+	client, err := connectMongo(config)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &DopamintMongoClient{config: config, logger: config.Logger}
+	m.logger.Info("Connected", "database", config.Database, "collection", config.ContractsCollection)
+
+	m.setClient(client)
+	m.healthy.Store(true)
+	return m, nil
+}
+
+// connectMongo dials MongoDB and verifies the connection with a ping
+func connectMongo(config MongoDBConfig) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
 	defer cancel()
 
-	clientOptions := options.Client().ApplyURI(config.URI)
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, config.clientOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w: %w", errdefs.ErrConnection, err)
 	}
 
 	// Ping to verify connection
 	if err := client.Ping(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w: %w", errdefs.ErrConnection, err)
 	}
 
-	database := client.Database(config.Database)
-	collection := database.Collection(config.Collection)
+	return client, nil
+}
 
-	fmt.Printf("[MongoDB] Connected to database: %s, collection: %s\n", config.Database, config.Collection)
+// setClient swaps in a connected client and its database, dropping the
+// collection handles of the previous one, and returns the previous client if any
+func (m *DopamintMongoClient) setClient(client *mongo.Client) *mongo.Client {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
 
-	return &DopamintMongoClient{
-		client:     client,
-		database:   database,
-		collection: collection,
-		config:     config,
-	}, nil
+	previous := m.client
+	m.client = client
+	m.database = client.Database(m.config.Database)
+	m.collections = make(map[string]*mongo.Collection)
+	m.connGen++
+	return previous
+}
+
+// connGeneration returns the generation of the current connection, which
+// changes whenever the health check swaps in a new client
+func (m *DopamintMongoClient) connGeneration() uint64 {
+	m.connMu.RLock()
+	defer m.connMu.RUnlock()
+	return m.connGen
+}
+
+// collection returns the named collection of the current connection,
+// obtaining the handle on first use
+func (m *DopamintMongoClient) collection(name string) *mongo.Collection {
+	m.connMu.RLock()
+	coll := m.collections[name]
+	m.connMu.RUnlock()
+	if coll != nil {
+		return coll
+	}
+
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	if coll = m.collections[name]; coll == nil {
+		coll = m.database.Collection(name)
+		m.collections[name] = coll
+	}
+	return coll
+}
+
+// contracts returns the contracts collection of the current connection
+func (m *DopamintMongoClient) contracts() *mongo.Collection {
+	return m.collection(m.config.ContractsCollection)
+}
+
+// checkpoints returns the checkpoints collection of the current connection
+func (m *DopamintMongoClient) checkpoints() *mongo.Collection {
+	return m.collection(m.config.CheckpointsCollection)
+}
+
+// db returns the database of the current connection
+func (m *DopamintMongoClient) db() *mongo.Database {
+	m.connMu.RLock()
+	defer m.connMu.RUnlock()
+	return m.database
+}
+
+// withTimeout derives a context bounded by the configured operation timeout
+// A caller deadline that is already shorter is left untouched
+func (m *DopamintMongoClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := m.config.OperationTimeout
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contractUniqueIndex keeps one document per contract and chain, which
+// UpsertNFTContractIfNewer relies on to detect stale writes
+var contractUniqueIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "contractAddress", Value: 1}, {Key: "chainId", Value: 1}},
+	Options: options.Index().SetName("contractAddress_chainId").SetUnique(true),
+}
+
+// EnsureIndexes creates the indexes used by the contract and event queries
+// It is idempotent and safe to call on every startup
+func (m *DopamintMongoClient) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		contractUniqueIndex,
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().SetName("status"),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("createdAt_desc"),
+		},
+		{
+			Keys:    bson.D{{Key: "creator", Value: 1}, {Key: "chainId", Value: 1}},
+			Options: options.Index().SetName("creator_chainId"),
+		},
+		{
+			Keys:    bson.D{{Key: "chainId", Value: 1}, {Key: "updatedAt", Value: 1}},
+			Options: options.Index().SetName("chainId_updatedAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "modelId", Value: 1}, {Key: "chainId", Value: 1}},
+			Options: options.Index().SetName("modelId_chainId"),
+		},
+		{
+			Keys:    bson.D{{Key: "chainId", Value: 1}, {Key: "network", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("chainId_network_status"),
+		},
+		{
+			Keys:    bson.D{{Key: "contractAddress", Value: 1}, {Key: "network", Value: 1}},
+			Options: options.Index().SetName("contractAddress_network"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("status_createdAt_id"),
+		},
+	}
+
+	if m.config.TextSearch {
+		indexes = append(indexes, contractTextIndex)
+	}
+
+	names, err := m.contracts().Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	m.uniqueIndex.Store(true)
+
+	eventIndexes, err := m.ensureEventIndexes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create event indexes: %w", err)
+	}
+	names = append(names, eventIndexes...)
+
+	m.logger.Info("Ensured indexes", "indexes", strings.Join(names, ","))
+	return nil
 }
 
 // GetNFTContractAddresses fetches all NFT contract addresses from MongoDB
 func (m *DopamintMongoClient) GetNFTContractAddresses(ctx context.Context) ([]common.Address, error) {
+	var addresses []common.Address
+	err := m.StreamNFTContractAddresses(ctx, func(address common.Address) error {
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// GetActiveNFTContractAddresses fetches the addresses of active NFT contracts
+func (m *DopamintMongoClient) GetActiveNFTContractAddresses(ctx context.Context) ([]common.Address, error) {
+	var addresses []common.Address
+	err := m.streamAddresses(ctx, bson.M{"status": StatusActive}, func(address common.Address) error {
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// StreamNFTContractAddresses iterates all NFT contract addresses, invoking fn
+// for each one as it is read from the cursor
+// Iteration stops at the first error returned by fn, which is returned as is
+func (m *DopamintMongoClient) StreamNFTContractAddresses(ctx context.Context, fn func(address common.Address) error) error {
 	filter := bson.M{
-		"status": bson.M{"$ne": "deleted"}, // Exclude deleted contracts
+		"status": bson.M{"$ne": StatusDeleted}, // Exclude deleted contracts
 	}
 
-	cursor, err := m.collection.Find(ctx, filter)
+	return m.streamAddresses(ctx, filter, fn)
+}
+
+// GetActiveNFTContractAddressesByChain fetches the addresses of active NFT
+// contracts partitioned by chain ID
+func (m *DopamintMongoClient) GetActiveNFTContractAddressesByChain(ctx context.Context) (map[int64][]common.Address, error) {
+	byChain := make(map[int64][]common.Address)
+	err := m.streamChainAddresses(ctx, bson.M{"status": StatusActive}, func(address common.Address, chainID int64) error {
+		byChain[chainID] = append(byChain[chainID], address)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query MongoDB: %w", err)
+		return nil, err
+	}
+
+	return byChain, nil
+}
+
+// streamAddresses iterates the addresses of the contracts matching filter
+func (m *DopamintMongoClient) streamAddresses(ctx context.Context, filter bson.M, fn func(address common.Address) error) error {
+	return m.streamChainAddresses(ctx, filter, func(address common.Address, _ int64) error {
+		return fn(address)
+	})
+}
+
+// streamChainAddresses iterates the addresses and chain IDs of the contracts
+// matching filter
+func (m *DopamintMongoClient) streamChainAddresses(ctx context.Context, filter bson.M, fn func(address common.Address, chainID int64) error) error {
+	// Only the initial query is bounded; iteration runs for as long as fn needs
+	findCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	projection := bson.M{"contractAddress": 1, "chainId": 1}
+	cursor, err := m.contracts().Find(findCtx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return fmt.Errorf("failed to query MongoDB: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	var addresses []common.Address
 	for cursor.Next(ctx) {
 		var doc NFTContractDocument
 		if err := cursor.Decode(&doc); err != nil {
-			fmt.Printf("[MongoDB] Failed to decode document: %v\n", err)
+			m.logger.Warn("Failed to decode document", "error", err)
 			continue
 		}
 
 		if doc.ContractAddress != "" && common.IsHexAddress(doc.ContractAddress) {
-			addresses = append(addresses, common.HexToAddress(doc.ContractAddress))
+			if err := fn(common.HexToAddress(doc.ContractAddress), doc.ChainID); err != nil {
+				return err
+			}
 		}
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, fmt.Errorf("cursor error: %w", err)
+		return fmt.Errorf("cursor error: %w", err)
 	}
 
-	return addresses, nil
+	return nil
 }
 
 // GetActiveNFTContracts fetches only active NFT contracts
-func (m *DopamintMongoClient) GetActiveNFTContracts(ctx context.Context) ([]NFTContractDocument, error) {
+func (m *DopamintMongoClient) GetActiveNFTContracts(ctx context.Context, opts ...QueryOption) ([]NFTContractDocument, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	filter := bson.M{
-		"status": "active",
+		"status": StatusActive,
 	}
 
-	cursor, err := m.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	cursor, err := m.readCollection(opts).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query MongoDB: %w", err)
 	}
@@ -125,120 +479,1366 @@ func (m *DopamintMongoClient) GetActiveNFTContracts(ctx context.Context) ([]NFTC
 	return contracts, nil
 }
 
-// UpsertNFTContract inserts or updates an NFT contract
-func (m *DopamintMongoClient) UpsertNFTContract(ctx context.Context, contract NFTContractDocument) error {
-	contract.UpdatedAt = time.Now()
-	if contract.CreatedAt.IsZero() {
-		contract.CreatedAt = time.Now()
+// GetActiveNFTContractsForNetwork fetches active NFT contracts of a single
+// network, newest first
+// An empty network or a zero chainID is not filtered on, so either can be
+// used alone
+func (m *DopamintMongoClient) GetActiveNFTContractsForNetwork(ctx context.Context, network string, chainID int64) ([]NFTContractDocument, error) {
+	filter := bson.M{
+		"status": StatusActive,
+	}
+	if network != "" {
+		filter["network"] = network
+	}
+	if chainID != 0 {
+		filter["chainId"] = chainID
+	}
+
+	return m.findContracts(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+// GetActiveNFTContractsPaged fetches one page of active NFT contracts, newest first
+// Results are ordered by createdAt then _id so pages never overlap
+func (m *DopamintMongoClient) GetActiveNFTContractsPaged(ctx context.Context, skip, limit int64) ([]NFTContractDocument, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"status": StatusActive,
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	cursor, err := m.contracts().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var contracts []NFTContractDocument
+	if err := cursor.All(ctx, &contracts); err != nil {
+		return nil, fmt.Errorf("failed to decode contracts: %w", err)
+	}
+
+	return contracts, nil
+}
+
+// GetActiveNFTContractsAfter fetches the page of active NFT contracts that
+// follows the given cursor, newest first, using keyset pagination on
+// createdAt and _id so deep pages cost the same as the first one
+// The cursor of the next page is the CreatedAt and ID of the last document
+// returned; pass a zero afterCreatedAt to fetch the first page
+// A page shorter than limit is the last one
+func (m *DopamintMongoClient) GetActiveNFTContractsAfter(ctx context.Context, afterCreatedAt time.Time, afterID interface{}, limit int64) ([]NFTContractDocument, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid page limit: %d", limit)
 	}
 
 	filter := bson.M{
-		"contractAddress": contract.ContractAddress,
-		"chainId":         contract.ChainID,
+		"status": StatusActive,
+	}
+	if !afterCreatedAt.IsZero() {
+		if afterID == nil {
+			return nil, fmt.Errorf("page cursor has a createdAt but no _id")
+		}
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": afterCreatedAt}},
+			bson.M{"createdAt": afterCreatedAt, "_id": bson.M{"$lt": afterID}},
+		}
 	}
 
-	update := bson.M{
-		"$set": contract,
-		"$setOnInsert": bson.M{
-			"createdAt": contract.CreatedAt,
-		},
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	return m.findContracts(ctx, filter, opts)
+}
+
+// CountActiveNFTContracts returns the number of active NFT contracts
+func (m *DopamintMongoClient) CountActiveNFTContracts(ctx context.Context) (int64, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	count, err := m.contracts().CountDocuments(ctx, bson.M{"status": StatusActive})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active documents: %w", err)
+	}
+	return count, nil
+}
+
+// UpsertNFTContract inserts or updates an NFT contract
+func (m *DopamintMongoClient) UpsertNFTContract(ctx context.Context, contract NFTContractDocument) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter, update, err := contractUpsert(contract)
+	if err != nil {
+		return err
 	}
 
 	opts := options.Update().SetUpsert(true)
-	result, err := m.collection.UpdateOne(ctx, filter, update, opts)
+	result, err := m.contracts().UpdateOne(ctx, filter, update, opts)
 	if err != nil {
 		return fmt.Errorf("failed to upsert contract: %w", err)
 	}
 
 	if result.UpsertedCount > 0 {
-		fmt.Printf("[MongoDB] Inserted new contract: %s\n", contract.ContractAddress)
+		m.logger.Info("Inserted new contract", "address", contract.ContractAddress)
 	} else if result.ModifiedCount > 0 {
-		fmt.Printf("[MongoDB] Updated contract: %s\n", contract.ContractAddress)
+		m.logger.Info("Updated contract", "address", contract.ContractAddress)
 	}
 
 	return nil
 }
 
-// GetContractByAddress fetches a contract by address
-func (m *DopamintMongoClient) GetContractByAddress(ctx context.Context, address string, chainID int64) (*NFTContractDocument, error) {
-	filter := bson.M{
-		"contractAddress": address,
-		"chainId":         chainID,
+// BulkUpsertNFTContracts inserts or updates many NFT contracts in a single
+// unordered bulk write, returning how many were inserted and modified
+func (m *DopamintMongoClient) BulkUpsertNFTContracts(ctx context.Context, contracts []NFTContractDocument) (inserted, modified int64, err error) {
+	if len(contracts) == 0 {
+		return 0, 0, nil
 	}
 
-	var contract NFTContractDocument
-	err := m.collection.FindOne(ctx, filter).Decode(&contract)
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(contracts))
+	for _, contract := range contracts {
+		filter, update, err := contractUpsert(contract)
+		if err != nil {
+			return 0, 0, err
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	result, err := m.contracts().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if result != nil {
+		inserted, modified = result.UpsertedCount, result.ModifiedCount
+	}
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+		return inserted, modified, fmt.Errorf("failed to bulk upsert contracts: %w", err)
+	}
+
+	m.logger.Info("Bulk upserted contracts", "count", len(contracts), "inserted", inserted, "modified", modified)
+
+	return inserted, modified, nil
+}
+
+// contractUpsert builds the filter and update document for upserting a contract
+// The insertOnly fields are only written when the upsert inserts the contract
+func contractUpsert(contract NFTContractDocument, insertOnly ...string) (filter, update bson.M, err error) {
+	contract.ContractAddress = NormalizeAddress(contract.ContractAddress)
+	contract.Creator = NormalizeAddress(contract.Creator)
+	contract.UpdatedAt = time.Now()
+	if contract.CreatedAt.IsZero() {
+		contract.CreatedAt = time.Now()
+	}
+
+	filter = bson.M{
+		"contractAddress": contract.ContractAddress,
+		"chainId":         contract.ChainID,
+	}
+
+	// createdAt must only appear in $setOnInsert, otherwise MongoDB rejects
+	// the upsert with a path conflict on insert
+	setFields, err := toBSONMap(contract)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode contract: %w", err)
+	}
+	delete(setFields, "_id")
+	delete(setFields, "createdAt")
+	delete(setFields, "version")
+	delete(setFields, "sourceBlock")
+	delete(setFields, "lastActivityBlock")
+	// Writes without provenance must not erase the recorded discovery
+	if contract.DiscoveryBlock == 0 {
+		delete(setFields, "discoveryBlock")
+		delete(setFields, "discoveryTxHash")
+		delete(setFields, "discoveryLogIndex")
+	}
+
+	insertFields := bson.M{"createdAt": contract.CreatedAt}
+	for _, field := range insertOnly {
+		if value, ok := setFields[field]; ok {
+			insertFields[field] = value
+			delete(setFields, field)
 		}
-		return nil, fmt.Errorf("failed to fetch contract: %w", err)
 	}
 
-	return &contract, nil
+	// Block markers only move forward, whatever order writes arrive in
+	maxFields := bson.M{"sourceBlock": contract.SourceBlock}
+	if contract.LastActivityBlock > 0 {
+		maxFields["lastActivityBlock"] = contract.LastActivityBlock
+	}
+
+	update = bson.M{
+		"$set":         setFields,
+		"$setOnInsert": insertFields,
+		"$inc":         bson.M{"version": 1},
+		"$max":         maxFields,
+	}
+
+	return filter, update, nil
 }
 
-// GetStats returns statistics about NFT contracts
-func (m *DopamintMongoClient) GetStats(ctx context.Context) (map[string]interface{}, error) {
-	totalCount, err := m.collection.CountDocuments(ctx, bson.M{})
+// UpsertNFTContractIfNewer inserts a contract or overwrites the stored one
+// only if the incoming document is newer: its SourceBlock is higher, or it is
+// the same and the stored Version is not ahead of the incoming one (i.e. no
+// other write happened since the caller read the document)
+// This keeps a stale backfill write from clobbering fresher live data.
+// Returns whether the write was applied. The unique contractAddress_chainId
+// index is created first if EnsureIndexes has not run, since without it a
+// stale write would insert a duplicate document instead of failing
+func (m *DopamintMongoClient) UpsertNFTContractIfNewer(ctx context.Context, contract NFTContractDocument) (bool, error) {
+	filter, update, err := contractUpsert(contract)
 	if err != nil {
-		return nil, fmt.Errorf("failed to count total documents: %w", err)
+		return false, err
+	}
+	return m.upsertIfNewer(ctx, contract, filter, update)
+}
+
+// upsertIfNewer applies a contract upsert guarded by the SourceBlock and
+// Version of contract, see UpsertNFTContractIfNewer
+func (m *DopamintMongoClient) upsertIfNewer(ctx context.Context, contract NFTContractDocument, filter, update bson.M) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if err := m.ensureUniqueIndex(ctx); err != nil {
+		return false, err
+	}
+
+	filter["$or"] = bson.A{
+		bson.M{"sourceBlock": bson.M{"$lt": contract.SourceBlock}},
+		bson.M{"sourceBlock": bson.M{"$exists": false}},
+		bson.M{"sourceBlock": contract.SourceBlock, "version": bson.M{"$lte": contract.Version}},
 	}
 
-	activeCount, err := m.collection.CountDocuments(ctx, bson.M{"status": "active"})
+	result, err := m.contracts().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 	if err != nil {
-		return nil, fmt.Errorf("failed to count active documents: %w", err)
+		// The stored document exists but is newer, so the upsert fell back
+		// to an insert that collides with it
+		if mongo.IsDuplicateKeyError(err) {
+			m.logger.Debug("Skipped stale contract write", "address", contract.ContractAddress,
+				"sourceBlock", contract.SourceBlock, "version", contract.Version)
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to upsert contract: %w", err)
 	}
 
-	return map[string]interface{}{
-		"total_contracts":  totalCount,
-		"active_contracts": activeCount,
-		"database":         m.config.Database,
-		"collection":       m.config.Collection,
-	}, nil
+	return result.MatchedCount > 0 || result.UpsertedCount > 0, nil
 }
 
-// Close closes the MongoDB connection
-func (m *DopamintMongoClient) Close(ctx context.Context) error {
-	if m.client != nil {
-		return m.client.Disconnect(ctx)
+// ensureUniqueIndex creates contractUniqueIndex unless it is known to exist
+// Creating it fails, and so do guarded upserts, when the collection holds
+// duplicate contracts or a conflicting index of the same name
+func (m *DopamintMongoClient) ensureUniqueIndex(ctx context.Context) error {
+	if m.uniqueIndex.Load() {
+		return nil
+	}
+
+	if _, err := m.contracts().Indexes().CreateOne(ctx, contractUniqueIndex); err != nil {
+		return fmt.Errorf("failed to ensure unique contract index: %w", err)
 	}
+	m.uniqueIndex.Store(true)
+
 	return nil
 }
 
-// WatchNFTContracts watches for new NFT contract insertions (requires replica set)
-func (m *DopamintMongoClient) WatchNFTContracts(ctx context.Context, callback func(contract NFTContractDocument)) error {
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.D{
-			{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update"}}}},
-		}}},
+// NormalizeAddress returns the canonical form used to store and compare
+// addresses: lowercase hex with a 0x prefix
+// Values that are not hex addresses are only trimmed and lowercased
+func NormalizeAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if common.IsHexAddress(address) {
+		return strings.ToLower(common.HexToAddress(address).Hex())
 	}
+	return strings.ToLower(address)
+}
 
-	stream, err := m.collection.Watch(ctx, pipeline)
+// NormalizeAddresses rewrites stored contract and creator addresses to their
+// canonical form, collapsing documents that only differed by address casing
+// into one. The document already in canonical form, or else the oldest, is
+// kept and the fields of the others are merged into it before they are
+// removed, see mergeContractDocuments
+// Duplicates are found by an aggregation returning only the groups with more
+// than one document, and the remaining documents are streamed from a cursor,
+// so memory use does not grow with the collection. Every query is bounded by
+// the operation timeout. Returns the number of documents updated or removed
+func (m *DopamintMongoClient) NormalizeAddresses(ctx context.Context) (int64, error) {
+	merged, err := m.mergeDuplicateContracts(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create change stream: %w", err)
+		return merged, err
 	}
-	defer stream.Close(ctx)
 
-	fmt.Println("[MongoDB] Watching for NFT contract changes...")
+	rewritten, err := m.rewriteContractAddresses(ctx)
+	changed := merged + rewritten
+	if err != nil {
+		return changed, err
+	}
 
-	for stream.Next(ctx) {
-		var changeEvent struct {
-			OperationType string              `bson:"operationType"`
-			FullDocument  NFTContractDocument `bson:"fullDocument"`
-		}
+	m.logger.Info("Normalized addresses", "changed", changed)
+	return changed, nil
+}
 
-		if err := stream.Decode(&changeEvent); err != nil {
-			fmt.Printf("[MongoDB] Failed to decode change event: %v\n", err)
-			continue
-		}
+// contractKey identifies a contract by canonical address and chain
+type contractKey struct {
+	address string
+	chainID int64
+}
 
-		callback(changeEvent.FullDocument)
-	}
+// addressRef is the part of a contract document NormalizeAddresses needs to
+// decide what to rewrite
+type addressRef struct {
+	ID              interface{} `bson:"_id"`
+	ContractAddress string      `bson:"contractAddress"`
+	Creator         string      `bson:"creator"`
+	ChainID         int64       `bson:"chainId"`
+	CreatedAt       time.Time   `bson:"createdAt"`
+}
 
-	if err := stream.Err(); err != nil {
-		return fmt.Errorf("change stream error: %w", err)
+// duplicateContractsPipeline groups contracts by address and chain, ignoring
+// case, surrounding whitespace and the 0x prefix as NormalizeAddress does,
+// and keeps only the groups holding more than one document
+var duplicateContractsPipeline = mongo.Pipeline{
+	{{Key: "$group", Value: bson.M{
+		"_id": bson.M{
+			"address": bson.M{"$let": bson.M{
+				"vars": bson.M{"address": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$contractAddress"}}}},
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{bson.M{"$substrCP": bson.A{"$$address", 0, 2}}, "0x"}},
+					bson.M{"$substrCP": bson.A{"$$address", 2, bson.M{"$strLenCP": "$$address"}}},
+					"$$address",
+				}},
+			}},
+			"chainId": "$chainId",
+		},
+		"refs": bson.M{"$push": bson.M{
+			"_id":             "$_id",
+			"contractAddress": "$contractAddress",
+			"creator":         "$creator",
+			"chainId":         "$chainId",
+			"createdAt":       "$createdAt",
+		}},
+		"count": bson.M{"$sum": 1},
+	}}},
+	{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+}
+
+// mergeDuplicateContracts collapses every group of documents of the same
+// contract into one, returning the number of documents changed
+func (m *DopamintMongoClient) mergeDuplicateContracts(ctx context.Context) (int64, error) {
+	aggCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := m.contracts().Aggregate(aggCtx, duplicateContractsPipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find duplicate contracts: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	return nil
+	var changed int64
+	for cursor.Next(ctx) {
+		var group struct {
+			Refs []addressRef `bson:"refs"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			m.logger.Warn("Failed to decode duplicate group", "error", err)
+			continue
+		}
+
+		key := contractKey{NormalizeAddress(group.Refs[0].ContractAddress), group.Refs[0].ChainID}
+		n, err := m.normalizeContractGroup(ctx, key, group.Refs)
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return changed, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return changed, nil
+}
+
+// rewriteContractAddresses streams every contract and rewrites the ones whose
+// contract or creator address is not canonical, returning the number changed
+// It runs after mergeDuplicateContracts, so no rewrite can collide with
+// another document of the same contract
+func (m *DopamintMongoClient) rewriteContractAddresses(ctx context.Context) (int64, error) {
+	findCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	projection := bson.M{"contractAddress": 1, "creator": 1, "chainId": 1, "createdAt": 1}
+	cursor, err := m.contracts().Find(findCtx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var changed int64
+	for cursor.Next(ctx) {
+		var ref addressRef
+		if err := cursor.Decode(&ref); err != nil {
+			m.logger.Warn("Failed to decode document", "error", err)
+			continue
+		}
+
+		key := contractKey{NormalizeAddress(ref.ContractAddress), ref.ChainID}
+		if ref.ContractAddress == key.address && ref.Creator == NormalizeAddress(ref.Creator) {
+			continue
+		}
+		n, err := m.normalizeContractGroup(ctx, key, []addressRef{ref})
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return changed, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return changed, nil
+}
+
+// normalizeContractGroup merges the documents of one contract into a single
+// one with canonical addresses, returning the number of documents changed
+func (m *DopamintMongoClient) normalizeContractGroup(ctx context.Context, key contractKey, group []addressRef) (int64, error) {
+	// Keep the document already in canonical form, otherwise the oldest
+	keeper := group[0]
+	for _, ref := range group[1:] {
+		if keeper.ContractAddress == key.address {
+			break
+		}
+		if ref.ContractAddress == key.address || ref.CreatedAt.Before(keeper.CreatedAt) {
+			keeper = ref
+		}
+	}
+
+	var changed int64
+	if len(group) > 1 {
+		n, err := m.mergeDuplicates(ctx, key, &keeper, group)
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+
+	creator := NormalizeAddress(keeper.Creator)
+	if keeper.ContractAddress == key.address && keeper.Creator == creator {
+		return changed, nil
+	}
+
+	writeCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"contractAddress": key.address, "creator": creator}}
+	result, err := m.contracts().UpdateOne(writeCtx, bson.M{"_id": keeper.ID}, update)
+	if err != nil {
+		return changed, fmt.Errorf("failed to normalize contract %s: %w", key.address, err)
+	}
+
+	return changed + result.ModifiedCount, nil
+}
+
+// mergeDuplicates merges the other documents of a group into the keeper and
+// removes them, updating keeper to the merged creator. The keeper is written
+// first so a failure never loses data, and keeps its address until the
+// duplicates are gone so the rewrite cannot violate the unique index
+func (m *DopamintMongoClient) mergeDuplicates(ctx context.Context, key contractKey, keeper *addressRef, group []addressRef) (int64, error) {
+	ids := make(bson.A, 0, len(group))
+	for _, ref := range group {
+		ids = append(ids, ref.ID)
+	}
+
+	docs, err := m.findContracts(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load duplicate contracts for %s: %w", key.address, err)
+	}
+
+	var (
+		merged     *NFTContractDocument
+		duplicates bson.A
+	)
+	for i := range docs {
+		if docs[i].ID == keeper.ID {
+			merged = &docs[i]
+		} else {
+			duplicates = append(duplicates, docs[i].ID)
+		}
+	}
+	if merged == nil || len(duplicates) == 0 {
+		return 0, nil
+	}
+	for _, doc := range docs {
+		if doc.ID != keeper.ID {
+			*merged = mergeContractDocuments(*merged, doc)
+		}
+	}
+
+	fields, err := toBSONMap(merged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode contract: %w", err)
+	}
+	delete(fields, "_id")
+	delete(fields, "contractAddress")
+
+	writeCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var changed int64
+	result, err := m.contracts().UpdateOne(writeCtx, bson.M{"_id": keeper.ID}, bson.M{"$set": fields})
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge duplicate contracts for %s: %w", key.address, err)
+	}
+	changed += result.ModifiedCount
+	keeper.Creator = merged.Creator
+
+	deleted, err := m.contracts().DeleteMany(writeCtx, bson.M{"_id": bson.M{"$in": duplicates}})
+	if err != nil {
+		return changed, fmt.Errorf("failed to remove duplicate contracts for %s: %w", key.address, err)
+	}
+	changed += deleted.DeletedCount
+
+	m.logger.Info("Merged duplicate contracts", "address", key.address, "chainId", key.chainID, "merged", deleted.DeletedCount)
+	return changed, nil
+}
+
+// mergeContractDocuments merges a duplicate of a contract into the document
+// that is kept: fields the kept document lacks are filled from the duplicate,
+// createdAt keeps the earliest value, and updatedAt and the block markers
+// keep the latest. The version ends past both so watchers see a new write
+// The kept document's _id and contract address are retained
+func mergeContractDocuments(keep, dup NFTContractDocument) NFTContractDocument {
+	fillString := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fillString(&keep.Creator, NormalizeAddress(dup.Creator))
+	fillString(&keep.Name, dup.Name)
+	fillString(&keep.Symbol, dup.Symbol)
+	fillString(&keep.BaseURI, dup.BaseURI)
+	fillString(&keep.Network, dup.Network)
+	fillString(&keep.Status, dup.Status)
+	keep.Creator = NormalizeAddress(keep.Creator)
+
+	if keep.CollectionID == 0 {
+		keep.CollectionID = dup.CollectionID
+	}
+	if keep.ModelID == 0 {
+		keep.ModelID = dup.ModelID
+	}
+	if keep.DiscoveryBlock == 0 {
+		keep.DiscoveryBlock = dup.DiscoveryBlock
+		keep.DiscoveryTxHash = dup.DiscoveryTxHash
+		keep.DiscoveryLogIndex = dup.DiscoveryLogIndex
+	}
+
+	if keep.CreatedAt.IsZero() || (!dup.CreatedAt.IsZero() && dup.CreatedAt.Before(keep.CreatedAt)) {
+		keep.CreatedAt = dup.CreatedAt
+	}
+	if dup.UpdatedAt.After(keep.UpdatedAt) {
+		keep.UpdatedAt = dup.UpdatedAt
+	}
+	if dup.SourceBlock > keep.SourceBlock {
+		keep.SourceBlock = dup.SourceBlock
+	}
+	if dup.LastActivityBlock > keep.LastActivityBlock {
+		keep.LastActivityBlock = dup.LastActivityBlock
+	}
+	if dup.Version > keep.Version {
+		keep.Version = dup.Version
+	}
+	keep.Version++
+
+	return keep
+}
+
+// toBSONMap converts a document struct into a mutable BSON map
+func toBSONMap(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m bson.M
+	if err := bson.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Contract status values
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+	StatusDeleted  = "deleted"
+)
+
+// validStatuses is the set of statuses a contract may be in
+var validStatuses = map[string]bool{
+	StatusActive:   true,
+	StatusInactive: true,
+	StatusDeleted:  true,
+}
+
+// SetContractStatus changes the status of a contract
+// Returns an error wrapping errdefs.ErrContractNotFound if the contract does not exist
+func (m *DopamintMongoClient) SetContractStatus(ctx context.Context, address string, chainID int64, status string) error {
+	if !validStatuses[status] {
+		return fmt.Errorf("invalid contract status: %q", status)
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"contractAddress": NormalizeAddress(address),
+		"chainId":         chainID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":    status,
+			"updatedAt": time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := m.contracts().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update contract status: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return contractNotFound(address, chainID)
+	}
+
+	m.logger.Info("Set contract status", "address", address, "status", status)
+	return nil
+}
+
+// UpdateLastActivity records that a contract emitted a log at block
+// The stored block only ever increases, so updates applied out of order
+// never move it back. Version and updatedAt are left alone since the
+// contract data itself did not change
+// Returns an error wrapping errdefs.ErrContractNotFound if the contract does not exist
+func (m *DopamintMongoClient) UpdateLastActivity(ctx context.Context, address string, chainID int64, block uint64) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"contractAddress": NormalizeAddress(address),
+		"chainId":         chainID,
+	}
+
+	update := bson.M{
+		"$max": bson.M{"lastActivityBlock": block},
+	}
+
+	result, err := m.contracts().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update last activity: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return contractNotFound(address, chainID)
+	}
+
+	return nil
+}
+
+// SoftDeleteContract marks a contract as deleted, removing it from the watch
+// address and active contract queries while keeping the document
+// Returns an error wrapping errdefs.ErrContractNotFound if no such contract exists
+func (m *DopamintMongoClient) SoftDeleteContract(ctx context.Context, address string, chainID int64) error {
+	return m.SetContractStatus(ctx, address, chainID, StatusDeleted)
+}
+
+// HardDeleteContract permanently removes a contract document, e.g. for data
+// purge requests
+// Returns an error wrapping errdefs.ErrContractNotFound if no such contract exists
+func (m *DopamintMongoClient) HardDeleteContract(ctx context.Context, address string, chainID int64) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"contractAddress": NormalizeAddress(address),
+		"chainId":         chainID,
+	}
+
+	result, err := m.contracts().DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete contract: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return contractNotFound(address, chainID)
+	}
+
+	m.logger.Info("Permanently deleted contract", "address", address)
+	return nil
+}
+
+// contractNotFound returns the error for a contract missing on a chain
+// mongo.ErrNoDocuments is kept in the chain for callers checking for it
+func contractNotFound(address string, chainID int64) error {
+	return fmt.Errorf("%w: %s on chain %d: %w", errdefs.ErrContractNotFound, NormalizeAddress(address), chainID, mongo.ErrNoDocuments)
+}
+
+// GetContractByAddress fetches a contract by address
+// Returns nil without error when no such contract is stored; use
+// MustGetContractByAddress to get errdefs.ErrContractNotFound instead
+func (m *DopamintMongoClient) GetContractByAddress(ctx context.Context, address string, chainID int64) (*NFTContractDocument, error) {
+	return m.findOneContract(ctx, bson.M{
+		"contractAddress": NormalizeAddress(address),
+		"chainId":         chainID,
+	})
+}
+
+// MustGetContractByAddress fetches a contract by address, returning an error
+// wrapping errdefs.ErrContractNotFound when no such contract is stored, so the
+// contract is never nil on success
+func (m *DopamintMongoClient) MustGetContractByAddress(ctx context.Context, address string, chainID int64) (*NFTContractDocument, error) {
+	contract, err := m.GetContractByAddress(ctx, address, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, contractNotFound(address, chainID)
+	}
+
+	return contract, nil
+}
+
+// GetContractByAddressNetwork fetches a contract by its address on a network
+// given by name (e.g. "base-mainnet") rather than chain ID
+// Returns nil without error when no such contract is stored
+func (m *DopamintMongoClient) GetContractByAddressNetwork(ctx context.Context, address, network string) (*NFTContractDocument, error) {
+	return m.findOneContract(ctx, bson.M{
+		"contractAddress": NormalizeAddress(address),
+		"network":         network,
+	})
+}
+
+// findOneContract fetches the contract matching filter, or nil if there is none
+func (m *DopamintMongoClient) findOneContract(ctx context.Context, filter bson.M) (*NFTContractDocument, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var contract NFTContractDocument
+	err := m.contracts().FindOne(ctx, filter).Decode(&contract)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch contract: %w", err)
+	}
+
+	return &contract, nil
+}
+
+// GetContractsByAddresses fetches several contracts on a chain in a single
+// query, keyed by normalized address
+// Addresses without a stored contract are absent from the result
+func (m *DopamintMongoClient) GetContractsByAddresses(ctx context.Context, addresses []string, chainID int64) (map[string]*NFTContractDocument, error) {
+	result := make(map[string]*NFTContractDocument, len(addresses))
+	if len(addresses) == 0 {
+		return result, nil
+	}
+
+	normalized := make([]string, 0, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		address = NormalizeAddress(address)
+		if !seen[address] {
+			seen[address] = true
+			normalized = append(normalized, address)
+		}
+	}
+
+	filter := bson.M{
+		"contractAddress": bson.M{"$in": normalized},
+		"chainId":         chainID,
+	}
+
+	contracts, err := m.findContracts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contracts {
+		result[NormalizeAddress(contracts[i].ContractAddress)] = &contracts[i]
+	}
+
+	return result, nil
+}
+
+// GetContractsByCreator fetches all non-deleted contracts created by an
+// address on a chain, newest first
+func (m *DopamintMongoClient) GetContractsByCreator(ctx context.Context, creator string, chainID int64) ([]NFTContractDocument, error) {
+	filter := bson.M{
+		"creator": NormalizeAddress(creator),
+		"chainId": chainID,
+		"status":  bson.M{"$ne": StatusDeleted},
+	}
+
+	return m.findContracts(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+// GetContractsByModelID fetches all non-deleted contracts generated from a
+// model on a chain, newest first
+func (m *DopamintMongoClient) GetContractsByModelID(ctx context.Context, modelID, chainID int64) ([]NFTContractDocument, error) {
+	filter := bson.M{
+		"modelId": modelID,
+		"chainId": chainID,
+		"status":  bson.M{"$ne": StatusDeleted},
+	}
+
+	return m.findContracts(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+// GetContractsUpdatedSince fetches contracts on a chain updated after since,
+// oldest update first, so consumers can sync incrementally using the last
+// updatedAt they saw as the next cutoff
+func (m *DopamintMongoClient) GetContractsUpdatedSince(ctx context.Context, since time.Time, chainID int64) ([]NFTContractDocument, error) {
+	filter := bson.M{
+		"updatedAt": bson.M{"$gt": since},
+		"chainId":   chainID,
+	}
+
+	return m.findContracts(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+// findContracts runs a Find on the contracts collection and decodes all results
+func (m *DopamintMongoClient) findContracts(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]NFTContractDocument, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := m.contracts().Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var contracts []NFTContractDocument
+	if err := cursor.All(ctx, &contracts); err != nil {
+		return nil, fmt.Errorf("failed to decode contracts: %w", err)
+	}
+
+	return contracts, nil
+}
+
+// GetStats returns statistics about NFT contracts
+// Kept as a map for compatibility; use ContractStats for a typed result
+func (m *DopamintMongoClient) GetStats(ctx context.Context, opts ...QueryOption) (map[string]interface{}, error) {
+	stats, err := m.ContractStats(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"total_contracts":  stats.TotalContracts,
+		"active_contracts": stats.ActiveContracts,
+		"database":         stats.Database,
+		"collection":       stats.Collection,
+	}, nil
+}
+
+// ContractStats holds contract counts for the configured collection
+type ContractStats struct {
+	TotalContracts  int64  `json:"total_contracts"`
+	ActiveContracts int64  `json:"active_contracts"`
+	Database        string `json:"database"`
+	Collection      string `json:"collection"`
+}
+
+// ContractStats returns typed statistics about the contracts collection
+func (m *DopamintMongoClient) ContractStats(ctx context.Context, opts ...QueryOption) (*ContractStats, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	collection := m.readCollection(opts)
+
+	totalCount, err := collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count total documents: %w", err)
+	}
+
+	activeCount, err := collection.CountDocuments(ctx, bson.M{"status": StatusActive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active documents: %w", err)
+	}
+
+	return &ContractStats{
+		TotalContracts:  totalCount,
+		ActiveContracts: activeCount,
+		Database:        m.config.Database,
+		Collection:      m.config.ContractsCollection,
+	}, nil
+}
+
+// GetStatsByGroup returns contract counts grouped by status, then network
+// Documents without a network are counted under the empty string
+func (m *DopamintMongoClient) GetStatsByGroup(ctx context.Context) (map[string]map[string]int64, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "status", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$status", ""}}}},
+				{Key: "network", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$network", ""}}}},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := m.contracts().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Status  string `bson:"status"`
+			Network string `bson:"network"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode stats: %w", err)
+	}
+
+	stats := make(map[string]map[string]int64)
+	for _, group := range groups {
+		if stats[group.ID.Status] == nil {
+			stats[group.ID.Status] = make(map[string]int64)
+		}
+		stats[group.ID.Status][group.ID.Network] += group.Count
+	}
+
+	return stats, nil
+}
+
+// defaultCheckpointsCollection is the default collection holding indexer checkpoints
+const defaultCheckpointsCollection = "checkpoints"
+
+// CheckpointDocument records the last processed block for a network
+type CheckpointDocument struct {
+	Network     string    `bson:"network"`
+	ChainID     int64     `bson:"chainId"`
+	BlockNumber uint64    `bson:"blockNumber"`
+	UpdatedAt   time.Time `bson:"updatedAt"`
+}
+
+// DistinctChainIDs returns the chain IDs present in the contracts collection
+// in ascending order
+func (m *DopamintMongoClient) DistinctChainIDs(ctx context.Context) ([]int64, error) {
+	values, err := m.distinctContractValues(ctx, "chainId")
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents written by other services may store the chain ID as any
+	// numeric type, so the same chain can come back more than once
+	seen := make(map[int64]bool, len(values))
+	chainIDs := make([]int64, 0, len(values))
+	for _, value := range values {
+		var chainID int64
+		switch v := value.(type) {
+		case int64:
+			chainID = v
+		case int32:
+			chainID = int64(v)
+		case float64:
+			chainID = int64(v)
+		default:
+			m.logger.Warn("Skipping non-numeric chainId", "value", value)
+			continue
+		}
+		if !seen[chainID] {
+			seen[chainID] = true
+			chainIDs = append(chainIDs, chainID)
+		}
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+
+	return chainIDs, nil
+}
+
+// DistinctNetworks returns the non-empty network names present in the
+// contracts collection in ascending order
+func (m *DopamintMongoClient) DistinctNetworks(ctx context.Context) ([]string, error) {
+	values, err := m.distinctContractValues(ctx, "network")
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make([]string, 0, len(values))
+	for _, value := range values {
+		if network, ok := value.(string); ok && network != "" {
+			networks = append(networks, network)
+		}
+	}
+	sort.Strings(networks)
+
+	return networks, nil
+}
+
+// distinctContractValues returns the distinct values of a contracts field
+func (m *DopamintMongoClient) distinctContractValues(ctx context.Context, field string) ([]interface{}, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	values, err := m.contracts().Distinct(ctx, field, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distinct %s: %w", field, err)
+	}
+
+	return values, nil
+}
+
+// SaveCheckpoint stores the last processed block for a network
+func (m *DopamintMongoClient) SaveCheckpoint(ctx context.Context, network string, chainID int64, blockNumber uint64) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"network": network,
+		"chainId": chainID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"blockNumber": blockNumber,
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := m.checkpoints().UpdateOne(ctx, filter, update, opts); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// RollbackCheckpoint moves the checkpoint of a network back to blockNumber
+// after a reorg. A checkpoint already at or below blockNumber is left as is,
+// so a rollback never advances indexing
+func (m *DopamintMongoClient) RollbackCheckpoint(ctx context.Context, network string, chainID, blockNumber int64) error {
+	if blockNumber < 0 {
+		return fmt.Errorf("invalid rollback block: %d", blockNumber)
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"network":     network,
+		"chainId":     chainID,
+		"blockNumber": bson.M{"$gt": blockNumber},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"blockNumber": blockNumber,
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	result, err := m.checkpoints().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to roll back checkpoint: %w", err)
+	}
+
+	if result.ModifiedCount > 0 {
+		m.logger.Info("Rolled back checkpoint", "network", network, "chainId", chainID, "block", blockNumber)
+	}
+	return nil
+}
+
+// GetCheckpoint returns the last processed block for a network, or 0 if no
+// checkpoint has been saved yet
+func (m *DopamintMongoClient) GetCheckpoint(ctx context.Context, network string, chainID int64) (uint64, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"network": network,
+		"chainId": chainID,
+	}
+
+	var checkpoint CheckpointDocument
+	err := m.checkpoints().FindOne(ctx, filter).Decode(&checkpoint)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to fetch checkpoint: %w", err)
+	}
+
+	return checkpoint.BlockNumber, nil
+}
+
+// Close closes the MongoDB connection
+func (m *DopamintMongoClient) Close(ctx context.Context) error {
+	m.connMu.RLock()
+	client := m.client
+	m.connMu.RUnlock()
+
+	if client != nil {
+		return client.Disconnect(ctx)
+	}
+	return nil
+}
+
+// WatchOptions configures a change stream started by WatchNFTContractsWithOptions
+type WatchOptions struct {
+	// ResumeToken resumes the stream after a previously processed event
+	ResumeToken bson.Raw
+	// OnResumeToken is called with the latest resume token after each
+	// processed event so callers can persist it across restarts
+	OnResumeToken func(token bson.Raw) error
+	// BatchSize is the maximum number of change events per server batch;
+	// zero uses the server default
+	BatchSize int32
+}
+
+// changeStreamOptions returns the driver options for a change stream
+// Update events carry the current document via an update lookup, since
+// otherwise only inserts include the full document
+func (o *WatchOptions) changeStreamOptions() *options.ChangeStreamOptions {
+	streamOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if len(o.ResumeToken) > 0 {
+		streamOpts.SetResumeAfter(o.ResumeToken)
+	}
+	if o.BatchSize > 0 {
+		streamOpts.SetBatchSize(o.BatchSize)
+	}
+	return streamOpts
+}
+
+// WatchNFTContracts watches for new NFT contract insertions (requires replica set)
+// Watching stops when callback returns an error, which is then returned
+func (m *DopamintMongoClient) WatchNFTContracts(ctx context.Context, callback func(contract NFTContractDocument) error) error {
+	return m.WatchNFTContractsWithOptions(ctx, WatchOptions{}, callback)
+}
+
+// Backoff bounds for reopening an interrupted change stream
+const (
+	initialWatchBackoff = time.Second
+	maxWatchBackoff     = 30 * time.Second
+)
+
+// resumableWatchErrorCodes are server error codes after which a change stream
+// can be reopened, e.g. primary stepdowns and shutdowns
+var resumableWatchErrorCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// WatchNFTContractsWithOptions watches for NFT contract changes, resuming from
+// a stored resume token when one is given
+// When the stream is interrupted by a resumable error it is reopened from the
+// last processed event with exponential backoff; it only returns once ctx is
+// cancelled, the stream is closed by the server, a non-resumable error occurs
+// or callback returns an error
+// A stream ended by the health check replacing the connection is reopened
+// right away on the new connection, however the old connection ended it
+// An error from callback stops the stream and is returned wrapped; the event it
+// was given is not marked processed, so resuming delivers it again
+func (m *DopamintMongoClient) WatchNFTContractsWithOptions(ctx context.Context, watchOpts WatchOptions, callback func(contract NFTContractDocument) error) error {
+	backoff := initialWatchBackoff
+
+	for {
+		gen := m.connGeneration()
+		delivered, err := m.watchOnce(ctx, &watchOpts, callback)
+		var stopErr *watchCallbackError
+		if errors.As(err, &stopErr) {
+			m.logger.Info("Change stream stopped by callback", "error", stopErr.err)
+			return fmt.Errorf("watch stopped by callback: %w", stopErr.err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if m.connGeneration() != gen {
+			m.logger.Info("Connection replaced, reopening change stream", "error", err)
+			backoff = initialWatchBackoff
+			continue
+		}
+		if err == nil {
+			return nil
+		}
+		if !isResumableWatchError(err) {
+			return err
+		}
+
+		// Only back off further while the stream keeps failing without progress
+		if delivered {
+			backoff = initialWatchBackoff
+		}
+
+		m.logger.Warn("Change stream interrupted, reopening", "backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxWatchBackoff {
+			backoff = maxWatchBackoff
+		}
+	}
+}
+
+// watchCallbackError carries an error returned by a watch callback, so it is
+// never mistaken for a resumable stream error
+type watchCallbackError struct {
+	err error
+}
+
+func (e *watchCallbackError) Error() string {
+	return e.err.Error()
+}
+
+// watchOnce opens a single change stream and delivers events until it ends
+// watchOpts.ResumeToken is advanced after every processed event so a reopened
+// stream continues where this one stopped
+// Returns whether any event was delivered along with the error that ended the
+// stream, which is a *watchCallbackError when the callback stopped it
+func (m *DopamintMongoClient) watchOnce(ctx context.Context, watchOpts *WatchOptions, callback func(contract NFTContractDocument) error) (bool, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update"}}}},
+		}}},
+	}
+
+	collection := m.contracts()
+	stream, err := collection.Watch(ctx, pipeline, watchOpts.changeStreamOptions())
+	if err != nil && len(watchOpts.ResumeToken) > 0 && isInvalidResumeTokenError(err) {
+		m.logger.Warn("Resume token no longer valid, starting a fresh change stream", "error", err)
+		watchOpts.ResumeToken = nil
+		stream, err = collection.Watch(ctx, pipeline, watchOpts.changeStreamOptions())
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create change stream: %w", err)
+	}
+	defer stream.Close(context.Background())
+
+	m.logger.Info("Watching for NFT contract changes")
+
+	return m.consumeChangeStream(ctx, stream, watchOpts, callback)
+}
+
+// changeStream is the part of *mongo.ChangeStream used to deliver events
+type changeStream interface {
+	Next(ctx context.Context) bool
+	Decode(val interface{}) error
+	ResumeToken() bson.Raw
+	Err() error
+}
+
+// consumeChangeStream delivers the events of stream to callback until the
+// stream ends, advancing watchOpts.ResumeToken after every processed event
+func (m *DopamintMongoClient) consumeChangeStream(ctx context.Context, stream changeStream, watchOpts *WatchOptions, callback func(contract NFTContractDocument) error) (bool, error) {
+	delivered := false
+	for stream.Next(ctx) {
+		var changeEvent struct {
+			OperationType string               `bson:"operationType"`
+			FullDocument  *NFTContractDocument `bson:"fullDocument"`
+		}
+
+		if err := stream.Decode(&changeEvent); err != nil {
+			m.logger.Warn("Failed to decode change event", "error", err)
+			continue
+		}
+
+		// The update lookup returns no document when the contract was deleted
+		// after the update; there is nothing to deliver, but the event still
+		// counts as processed for the resume token
+		if changeEvent.FullDocument == nil {
+			m.logger.Debug("Skipping change event without full document", "operationType", changeEvent.OperationType)
+		} else {
+			if err := callback(*changeEvent.FullDocument); err != nil {
+				return delivered, &watchCallbackError{err: err}
+			}
+			delivered = true
+		}
+
+		watchOpts.ResumeToken = stream.ResumeToken()
+		if watchOpts.OnResumeToken != nil {
+			if err := watchOpts.OnResumeToken(watchOpts.ResumeToken); err != nil {
+				m.logger.Warn("Failed to persist resume token", "error", err)
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return delivered, fmt.Errorf("change stream error: %w", err)
+	}
+
+	return delivered, nil
+}
+
+// isResumableWatchError reports whether a change stream that failed with err
+// can be reopened, as opposed to failing permanently
+func isResumableWatchError(err error) bool {
+	if isInvalidResumeTokenError(err) {
+		return false
+	}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		if serverErr.HasErrorLabel("ResumableChangeStreamError") {
+			return true
+		}
+		for _, code := range resumableWatchErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// invalidResumeTokenErrorCodes are server error codes for a change stream
+// that cannot be resumed from the requested token
+var invalidResumeTokenErrorCodes = []int{
+	280, // ChangeStreamFatalError
+	286, // ChangeStreamHistoryLost, the token fell off the oplog
+}
+
+// isInvalidResumeTokenError reports whether a change stream cannot be resumed
+// from the requested token
+func isInvalidResumeTokenError(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	for _, code := range invalidResumeTokenErrorCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,1259 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestContractUpsert(t *testing.T) {
+	contract := NFTContractDocument{
+		ContractAddress: "0xABCDEF0000000000000000000000000000000001",
+		Creator:         "0xABCDEF0000000000000000000000000000000002",
+		Name:            "Collection",
+		ModelID:         7,
+		ChainID:         8453,
+		Network:         "base",
+		Status:          StatusActive,
+		SourceBlock:     100,
+	}
+	discovered := contract
+	discovered.DiscoveryBlock = 100
+	discovered.DiscoveryTxHash = "0xfeed"
+
+	tests := []struct {
+		name        string
+		contract    NFTContractDocument
+		insertOnly  []string
+		wantSet     []string
+		wantNotSet  []string
+		wantOnlyNew []string
+	}{
+		{
+			name:        "backend write",
+			contract:    contract,
+			wantSet:     []string{"contractAddress", "creator", "status", "modelId", "name", "updatedAt"},
+			wantNotSet:  []string{"_id", "createdAt", "version", "sourceBlock", "discoveryBlock", "discoveryTxHash"},
+			wantOnlyNew: []string{"createdAt"},
+		},
+		{
+			name:        "discovery write",
+			contract:    discovered,
+			insertOnly:  backendOwnedFields,
+			wantSet:     []string{"contractAddress", "creator", "discoveryBlock", "discoveryTxHash", "updatedAt"},
+			wantNotSet:  []string{"status", "modelId", "name", "symbol", "baseURI", "network", "createdAt"},
+			wantOnlyNew: []string{"createdAt", "status", "modelId", "name", "network"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, update, err := contractUpsert(tt.contract, tt.insertOnly...)
+			if err != nil {
+				t.Fatalf("contractUpsert: %v", err)
+			}
+
+			wantAddress := "0xabcdef0000000000000000000000000000000001"
+			if filter["contractAddress"] != wantAddress || filter["chainId"] != int64(8453) {
+				t.Fatalf("filter = %v, want normalized address and chain", filter)
+			}
+
+			set := update["$set"].(bson.M)
+			onInsert := update["$setOnInsert"].(bson.M)
+			for _, field := range tt.wantSet {
+				if _, ok := set[field]; !ok {
+					t.Errorf("$set is missing %q", field)
+				}
+			}
+			for _, field := range tt.wantNotSet {
+				if _, ok := set[field]; ok {
+					t.Errorf("$set contains %q", field)
+				}
+			}
+			for _, field := range tt.wantOnlyNew {
+				if _, ok := onInsert[field]; !ok {
+					t.Errorf("$setOnInsert is missing %q", field)
+				}
+			}
+
+			if update["$inc"].(bson.M)["version"] != 1 {
+				t.Errorf("$inc = %v, want version incremented", update["$inc"])
+			}
+			if update["$max"].(bson.M)["sourceBlock"] != uint64(100) {
+				t.Errorf("$max = %v, want sourceBlock 100", update["$max"])
+			}
+		})
+	}
+}
+
+func TestUpsertNFTContractPreservesCreatedAt(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	contract := NFTContractDocument{
+		ContractAddress: "0xABCDEF0000000000000000000000000000000001",
+		Name:            "Collection",
+		ChainID:         8453,
+		Status:          StatusActive,
+	}
+	if err := m.UpsertNFTContract(ctx, contract); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	inserted, err := m.MustGetContractByAddress(ctx, contract.ContractAddress, contract.ChainID)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress after insert: %v", err)
+	}
+	if inserted.CreatedAt.IsZero() {
+		t.Fatalf("createdAt not set on insert")
+	}
+
+	contract.Name = "Renamed"
+	contract.CreatedAt = time.Now().Add(time.Hour)
+	if err := m.UpsertNFTContract(ctx, contract); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	updated, err := m.MustGetContractByAddress(ctx, contract.ContractAddress, contract.ChainID)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress after update: %v", err)
+	}
+
+	if !updated.CreatedAt.Equal(inserted.CreatedAt) {
+		t.Errorf("createdAt changed from %v to %v", inserted.CreatedAt, updated.CreatedAt)
+	}
+	if updated.Name != "Renamed" {
+		t.Errorf("name = %q, want %q", updated.Name, "Renamed")
+	}
+}
+
+func TestGetActiveNFTContractsPaged(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	// Contracts share createdAt in pairs so the _id tie-break is exercised
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var docs []interface{}
+	for i := 0; i < 7; i++ {
+		docs = append(docs, NFTContractDocument{
+			ContractAddress: fmt.Sprintf("0x%040x", i+1),
+			ChainID:         8453,
+			Status:          StatusActive,
+			CreatedAt:       base.Add(time.Duration(i/2) * time.Hour),
+		})
+	}
+	docs = append(docs, NFTContractDocument{ContractAddress: fmt.Sprintf("0x%040x", 99), ChainID: 8453, Status: StatusInactive, CreatedAt: base})
+	if _, err := m.contracts().InsertMany(ctx, docs); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	count, err := m.CountActiveNFTContracts(ctx)
+	if err != nil {
+		t.Fatalf("CountActiveNFTContracts: %v", err)
+	}
+	if count != 7 {
+		t.Fatalf("CountActiveNFTContracts = %d, want 7", count)
+	}
+
+	for _, limit := range []int64{1, 3, 7, 10} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			seen := make(map[string]bool)
+			var last time.Time
+			for skip := int64(0); ; skip += limit {
+				page, err := m.GetActiveNFTContractsPaged(ctx, skip, limit)
+				if err != nil {
+					t.Fatalf("GetActiveNFTContractsPaged(%d, %d): %v", skip, limit, err)
+				}
+				if int64(len(page)) > limit {
+					t.Fatalf("page at %d has %d contracts, want at most %d", skip, len(page), limit)
+				}
+				for _, contract := range page {
+					if seen[contract.ContractAddress] {
+						t.Fatalf("%s returned on more than one page", contract.ContractAddress)
+					}
+					if contract.Status != StatusActive {
+						t.Fatalf("%s has status %q", contract.ContractAddress, contract.Status)
+					}
+					if !last.IsZero() && contract.CreatedAt.After(last) {
+						t.Fatalf("%s is newer than the contract before it", contract.ContractAddress)
+					}
+					seen[contract.ContractAddress] = true
+					last = contract.CreatedAt
+				}
+				if int64(len(page)) < limit {
+					break
+				}
+			}
+			if int64(len(seen)) != count {
+				t.Errorf("pages returned %d contracts, want %d", len(seen), count)
+			}
+		})
+	}
+}
+
+func TestGetActiveNFTContractsAfter(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	// Contracts share createdAt in threes so the _id tie-break is exercised
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var docs []interface{}
+	for i := 0; i < 9; i++ {
+		docs = append(docs, NFTContractDocument{
+			ContractAddress: fmt.Sprintf("0x%040x", i+1),
+			ChainID:         8453,
+			Status:          StatusActive,
+			CreatedAt:       base.Add(time.Duration(i/3) * time.Hour),
+		})
+	}
+	docs = append(docs, NFTContractDocument{ContractAddress: fmt.Sprintf("0x%040x", 99), ChainID: 8453, Status: StatusInactive, CreatedAt: base})
+	if _, err := m.contracts().InsertMany(ctx, docs); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	for _, limit := range []int64{1, 2, 3, 4, 9, 10} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			seen := make(map[string]bool)
+			var (
+				afterCreatedAt time.Time
+				afterID        interface{}
+				pages          int
+			)
+			for {
+				page, err := m.GetActiveNFTContractsAfter(ctx, afterCreatedAt, afterID, limit)
+				if err != nil {
+					t.Fatalf("GetActiveNFTContractsAfter(page %d): %v", pages, err)
+				}
+				if int64(len(page)) > limit {
+					t.Fatalf("page %d has %d contracts, want at most %d", pages, len(page), limit)
+				}
+				for _, contract := range page {
+					if seen[contract.ContractAddress] {
+						t.Fatalf("%s returned on more than one page", contract.ContractAddress)
+					}
+					if contract.Status != StatusActive {
+						t.Fatalf("%s has status %q", contract.ContractAddress, contract.Status)
+					}
+					id, ok := contract.ID.(primitive.ObjectID)
+					if !ok {
+						t.Fatalf("%s has _id of type %T", contract.ContractAddress, contract.ID)
+					}
+					// Every document must sort strictly after the cursor
+					if afterID != nil {
+						prev := afterID.(primitive.ObjectID)
+						if contract.CreatedAt.After(afterCreatedAt) ||
+							(contract.CreatedAt.Equal(afterCreatedAt) && id.Hex() >= prev.Hex()) {
+							t.Fatalf("%s does not follow the page cursor", contract.ContractAddress)
+						}
+					}
+					seen[contract.ContractAddress] = true
+					afterCreatedAt, afterID = contract.CreatedAt, id
+				}
+				pages++
+				if int64(len(page)) < limit {
+					break
+				}
+			}
+			if len(seen) != 9 {
+				t.Errorf("pages returned %d contracts, want 9", len(seen))
+			}
+			if want := 9/int(limit) + 1; pages != want {
+				t.Errorf("walked %d pages, want %d", pages, want)
+			}
+		})
+	}
+
+	t.Run("newer contract stored mid-walk", func(t *testing.T) {
+		first, err := m.GetActiveNFTContractsAfter(ctx, time.Time{}, nil, 4)
+		if err != nil {
+			t.Fatalf("GetActiveNFTContractsAfter: %v", err)
+		}
+		newer := testContract(100)
+		newer.CreatedAt = base.Add(24 * time.Hour)
+		if _, err := m.contracts().InsertOne(ctx, newer); err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+
+		last := first[len(first)-1]
+		rest, err := m.GetActiveNFTContractsAfter(ctx, last.CreatedAt, last.ID, 10)
+		if err != nil {
+			t.Fatalf("GetActiveNFTContractsAfter: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, contract := range append(first, rest...) {
+			if seen[contract.ContractAddress] {
+				t.Fatalf("%s returned twice after an insert", contract.ContractAddress)
+			}
+			seen[contract.ContractAddress] = true
+		}
+		if seen[newer.ContractAddress] {
+			t.Errorf("walk returned %s, stored after the cursor was taken", newer.ContractAddress)
+		}
+		if len(seen) != 9 {
+			t.Errorf("walk returned %d contracts, want 9", len(seen))
+		}
+	})
+}
+
+func TestGetActiveNFTContractsAfterValidatesCursor(t *testing.T) {
+	// Cursors are validated before MongoDB is contacted
+	m := &DopamintMongoClient{}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		afterCreatedAt time.Time
+		afterID        interface{}
+		limit          int64
+	}{
+		{name: "zero limit", limit: 0},
+		{name: "negative limit", limit: -1},
+		{name: "createdAt without _id", afterCreatedAt: base, limit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.GetActiveNFTContractsAfter(context.Background(), tt.afterCreatedAt, tt.afterID, tt.limit); err == nil {
+				t.Fatalf("GetActiveNFTContractsAfter succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestStreamNFTContractAddresses(t *testing.T) {
+	m := newTestClient(t)
+	deleted := testContract(4)
+	deleted.Status = StatusDeleted
+	upsertContracts(t, m, testContract(1), testContract(2), testContract(3), deleted)
+
+	errStop := errors.New("stop")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		stopAfter int // fn fails on this call, 0 to never fail
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "streams every non-deleted contract", ctx: context.Background(), wantCalls: 3},
+		{name: "stops at the first callback error", ctx: context.Background(), stopAfter: 2, wantCalls: 2, wantErr: errStop},
+		{name: "query error", ctx: cancelled, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := m.StreamNFTContractAddresses(tt.ctx, func(address common.Address) error {
+				calls++
+				if calls == tt.stopAfter {
+					return errStop
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	// A second call must succeed on the existing indexes
+	for i := 0; i < 2; i++ {
+		if err := m.EnsureIndexes(ctx); err != nil {
+			t.Fatalf("EnsureIndexes call %d: %v", i+1, err)
+		}
+	}
+
+	cursor, err := m.contracts().Indexes().List(ctx)
+	if err != nil {
+		t.Fatalf("List indexes: %v", err)
+	}
+	var indexes []struct {
+		Name   string `bson:"name"`
+		Unique bool   `bson:"unique"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		t.Fatalf("decode indexes: %v", err)
+	}
+	unique := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		unique[index.Name] = index.Unique
+	}
+	for name, wantUnique := range map[string]bool{"contractAddress_chainId": true, "status": false, "createdAt_desc": false} {
+		gotUnique, ok := unique[name]
+		if !ok {
+			t.Errorf("index %q missing", name)
+		} else if gotUnique != wantUnique {
+			t.Errorf("index %q unique = %v, want %v", name, gotUnique, wantUnique)
+		}
+	}
+
+	contract := testContract(1)
+	if _, err := m.contracts().InsertOne(ctx, contract); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	if _, err := m.contracts().InsertOne(ctx, contract); !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("duplicate InsertOne error = %v, want a duplicate key error", err)
+	}
+	other := contract
+	other.ChainID = 84532
+	if _, err := m.contracts().InsertOne(ctx, other); err != nil {
+		t.Fatalf("InsertOne on another chain: %v", err)
+	}
+}
+
+func TestBulkUpsertNFTContracts(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	upsertContracts(t, m, testContract(1), testContract(2))
+	existing, err := m.MustGetContractByAddress(ctx, testContract(1).ContractAddress, 8453)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress: %v", err)
+	}
+
+	var batch []NFTContractDocument
+	for i := 1; i <= 5; i++ {
+		contract := testContract(i)
+		contract.Name = fmt.Sprintf("Collection %d", i)
+		contract.CreatedAt = time.Now().Add(time.Hour)
+		batch = append(batch, contract)
+	}
+
+	inserted, modified, err := m.BulkUpsertNFTContracts(ctx, batch)
+	if err != nil {
+		t.Fatalf("BulkUpsertNFTContracts: %v", err)
+	}
+	if inserted != 3 || modified != 2 {
+		t.Errorf("inserted %d and modified %d, want 3 and 2", inserted, modified)
+	}
+
+	updated, err := m.MustGetContractByAddress(ctx, testContract(1).ContractAddress, 8453)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress: %v", err)
+	}
+	if updated.Name != "Collection 1" {
+		t.Errorf("name = %q, want %q", updated.Name, "Collection 1")
+	}
+	if !updated.CreatedAt.Equal(existing.CreatedAt) {
+		t.Errorf("createdAt changed from %v to %v", existing.CreatedAt, updated.CreatedAt)
+	}
+	if !updated.UpdatedAt.After(existing.UpdatedAt) {
+		t.Errorf("updatedAt %v not after %v", updated.UpdatedAt, existing.UpdatedAt)
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		saves []uint64 // blocks saved in order on base-sepolia
+		want  uint64
+	}{
+		{name: "missing checkpoint", want: 0},
+		{name: "saved checkpoint", saves: []uint64{100}, want: 100},
+		{name: "overwritten checkpoint", saves: []uint64{100, 250}, want: 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestClient(t)
+			ctx := context.Background()
+
+			for _, block := range tt.saves {
+				if err := m.SaveCheckpoint(ctx, "base-sepolia", 84532, block); err != nil {
+					t.Fatalf("SaveCheckpoint(%d): %v", block, err)
+				}
+			}
+			// A checkpoint of another chain must not leak into this one
+			if err := m.SaveCheckpoint(ctx, "base", 8453, 999); err != nil {
+				t.Fatalf("SaveCheckpoint on another chain: %v", err)
+			}
+
+			got, err := m.GetCheckpoint(ctx, "base-sepolia", 84532)
+			if err != nil {
+				t.Fatalf("GetCheckpoint: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetCheckpoint = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetContractStatus(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+	upsertContracts(t, m, testContract(1))
+	address := testContract(1).ContractAddress
+
+	tests := []struct {
+		name     string
+		address  string
+		status   string
+		wantErr  bool
+		notFound bool
+	}{
+		{name: "active to inactive", address: address, status: StatusInactive},
+		{name: "inactive to deleted", address: address, status: StatusDeleted},
+		{name: "deleted to active", address: address, status: StatusActive},
+		{name: "invalid status", address: address, status: "paused", wantErr: true},
+		{name: "unknown contract", address: testContract(2).ContractAddress, status: StatusInactive, wantErr: true, notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := m.MustGetContractByAddress(ctx, address, 8453)
+			if err != nil {
+				t.Fatalf("MustGetContractByAddress: %v", err)
+			}
+
+			err = m.SetContractStatus(ctx, tt.address, 8453, tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetContractStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+			if got := errors.Is(err, errdefs.ErrContractNotFound); got != tt.notFound {
+				t.Fatalf("error %v matches ErrContractNotFound = %v, want %v", err, got, tt.notFound)
+			}
+
+			after, err := m.MustGetContractByAddress(ctx, address, 8453)
+			if err != nil {
+				t.Fatalf("MustGetContractByAddress: %v", err)
+			}
+			wantStatus := before.Status
+			if !tt.wantErr {
+				wantStatus = tt.status
+			}
+			if after.Status != wantStatus {
+				t.Errorf("status = %q, want %q", after.Status, wantStatus)
+			}
+			if !tt.wantErr && !after.UpdatedAt.After(before.UpdatedAt) {
+				t.Errorf("updatedAt %v not after %v", after.UpdatedAt, before.UpdatedAt)
+			}
+			if after.Name != before.Name || after.Creator != before.Creator {
+				t.Errorf("fields other than status and updatedAt changed")
+			}
+		})
+	}
+}
+
+func TestUpdateLastActivity(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	stored := testContract(1)
+	upsertContracts(t, m, stored)
+	before, err := m.MustGetContractByAddress(ctx, stored.ContractAddress, stored.ChainID)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress: %v", err)
+	}
+
+	// Blocks arrive out of order, e.g. from parallel backfill chunks
+	steps := []struct {
+		block uint64
+		want  uint64
+	}{
+		{block: 100, want: 100},
+		{block: 50, want: 100},
+		{block: 300, want: 300},
+		{block: 200, want: 300},
+		{block: 300, want: 300},
+	}
+	for _, step := range steps {
+		if err := m.UpdateLastActivity(ctx, strings.ToUpper(stored.ContractAddress[2:]), stored.ChainID, step.block); err != nil {
+			t.Fatalf("UpdateLastActivity(%d): %v", step.block, err)
+		}
+		contract, err := m.MustGetContractByAddress(ctx, stored.ContractAddress, stored.ChainID)
+		if err != nil {
+			t.Fatalf("MustGetContractByAddress: %v", err)
+		}
+		if contract.LastActivityBlock != step.want {
+			t.Fatalf("after block %d LastActivityBlock = %d, want %d", step.block, contract.LastActivityBlock, step.want)
+		}
+		if contract.Version != before.Version || !contract.UpdatedAt.Equal(before.UpdatedAt) {
+			t.Fatalf("activity update changed version %d -> %d or updatedAt", before.Version, contract.Version)
+		}
+	}
+
+	// Concurrent updates keep the highest block whatever order they land in
+	var wg sync.WaitGroup
+	for block := uint64(400); block < 450; block++ {
+		wg.Add(1)
+		go func(block uint64) {
+			defer wg.Done()
+			if err := m.UpdateLastActivity(ctx, stored.ContractAddress, stored.ChainID, block); err != nil {
+				t.Errorf("UpdateLastActivity(%d): %v", block, err)
+			}
+		}(block)
+	}
+	wg.Wait()
+	contract, err := m.MustGetContractByAddress(ctx, stored.ContractAddress, stored.ChainID)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress: %v", err)
+	}
+	if contract.LastActivityBlock != 449 {
+		t.Errorf("after concurrent updates LastActivityBlock = %d, want 449", contract.LastActivityBlock)
+	}
+
+	err = m.UpdateLastActivity(ctx, testContract(2).ContractAddress, stored.ChainID, 500)
+	if !errors.Is(err, errdefs.ErrContractNotFound) {
+		t.Errorf("UpdateLastActivity(unknown) = %v, want %v", err, errdefs.ErrContractNotFound)
+	}
+}
+
+func TestGetContractsByCreator(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	creator := "0x000000000000000000000000000000000000AAAA"
+	other := "0x000000000000000000000000000000000000bbbb"
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	contract := func(n int, creator string, chainID int64, status string) NFTContractDocument {
+		doc := testContract(n)
+		doc.Creator = creator
+		doc.ChainID = chainID
+		doc.Status = status
+		doc.CreatedAt = base.Add(time.Duration(n) * time.Hour)
+		return doc
+	}
+	upsertContracts(t, m,
+		contract(1, creator, 8453, StatusActive),
+		contract(2, creator, 8453, StatusInactive),
+		contract(3, creator, 8453, StatusDeleted),
+		contract(4, creator, 84532, StatusActive),
+		contract(5, other, 8453, StatusActive),
+	)
+
+	tests := []struct {
+		name    string
+		creator string
+		chainID int64
+		want    []string
+	}{
+		{name: "newest first without deleted", creator: creator, chainID: 8453, want: []string{testContract(2).ContractAddress, testContract(1).ContractAddress}},
+		{name: "case insensitive creator", creator: strings.ToLower(creator), chainID: 8453, want: []string{testContract(2).ContractAddress, testContract(1).ContractAddress}},
+		{name: "other chain", creator: creator, chainID: 84532, want: []string{testContract(4).ContractAddress}},
+		{name: "other creator", creator: other, chainID: 8453, want: []string{testContract(5).ContractAddress}},
+		{name: "unknown creator", creator: "0x000000000000000000000000000000000000cccc", chainID: 8453},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contracts, err := m.GetContractsByCreator(ctx, tt.creator, tt.chainID)
+			if err != nil {
+				t.Fatalf("GetContractsByCreator: %v", err)
+			}
+			var got []string
+			for _, contract := range contracts {
+				got = append(got, contract.ContractAddress)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetContractsByCreator = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistinctChainsAndNetworks(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	chainIDs, err := m.DistinctChainIDs(ctx)
+	if err != nil {
+		t.Fatalf("DistinctChainIDs: %v", err)
+	}
+	networks, err := m.DistinctNetworks(ctx)
+	if err != nil {
+		t.Fatalf("DistinctNetworks: %v", err)
+	}
+	if len(chainIDs) != 0 || len(networks) != 0 {
+		t.Fatalf("empty collection has chains %v and networks %v", chainIDs, networks)
+	}
+
+	contract := func(n int, chainID int64, network string) NFTContractDocument {
+		doc := testContract(n)
+		doc.ChainID = chainID
+		doc.Network = network
+		return doc
+	}
+	upsertContracts(t, m,
+		contract(1, 8453, "base"),
+		contract(2, 8453, "base"),
+		contract(3, 84532, "base-sepolia"),
+		contract(4, 1, "mainnet"),
+		contract(5, 1, ""),
+	)
+	// Documents written by other services with other numeric encodings
+	raw := []interface{}{
+		bson.M{"contractAddress": fmt.Sprintf("0x%040x", 6), "chainId": int32(10), "network": "optimism"},
+		bson.M{"contractAddress": fmt.Sprintf("0x%040x", 7), "chainId": float64(8453), "network": "base"},
+		bson.M{"contractAddress": fmt.Sprintf("0x%040x", 8), "chainId": "8453", "network": 8453},
+	}
+	if _, err := m.contracts().InsertMany(ctx, raw); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	chainIDs, err = m.DistinctChainIDs(ctx)
+	if err != nil {
+		t.Fatalf("DistinctChainIDs: %v", err)
+	}
+	if want := []int64{1, 10, 8453, 84532}; !reflect.DeepEqual(chainIDs, want) {
+		t.Errorf("DistinctChainIDs = %v, want %v", chainIDs, want)
+	}
+
+	networks, err = m.DistinctNetworks(ctx)
+	if err != nil {
+		t.Fatalf("DistinctNetworks: %v", err)
+	}
+	if want := []string{"base", "base-sepolia", "mainnet", "optimism"}; !reflect.DeepEqual(networks, want) {
+		t.Errorf("DistinctNetworks = %v, want %v", networks, want)
+	}
+}
+
+func TestMongoDBConfigWithDefaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        MongoDBConfig
+		wantContracts string
+		wantProcessed string
+	}{
+		{
+			name:          "defaults",
+			config:        MongoDBConfig{Collection: "nft_contracts"},
+			wantContracts: "nft_contracts",
+			wantProcessed: defaultProcessedLogsCollection,
+		},
+		{
+			name:          "configured collections",
+			config:        MongoDBConfig{ContractsCollection: "contracts", ProcessedLogsCollection: "handled_logs"},
+			wantContracts: "contracts",
+			wantProcessed: "handled_logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.withDefaults()
+			if got.ContractsCollection != tt.wantContracts || got.Collection != tt.wantContracts {
+				t.Errorf("contracts collection = %q/%q, want %q", got.ContractsCollection, got.Collection, tt.wantContracts)
+			}
+			if got.ProcessedLogsCollection != tt.wantProcessed {
+				t.Errorf("ProcessedLogsCollection = %q, want %q", got.ProcessedLogsCollection, tt.wantProcessed)
+			}
+			if got.EventsCollection != defaultEventsCollection || got.CheckpointsCollection != defaultCheckpointsCollection {
+				t.Errorf("events/checkpoints = %q/%q, want defaults", got.EventsCollection, got.CheckpointsCollection)
+			}
+		})
+	}
+}
+
+func TestContractNotFound(t *testing.T) {
+	err := contractNotFound("0xABCDEF0000000000000000000000000000000001", 8453)
+
+	for _, target := range []error{errdefs.ErrContractNotFound, mongo.ErrNoDocuments} {
+		if !errors.Is(err, target) {
+			t.Errorf("error %v does not match %v", err, target)
+		}
+	}
+	if errors.Is(err, errdefs.ErrConnection) {
+		t.Errorf("error %v matches %v", err, errdefs.ErrConnection)
+	}
+}
+
+func TestGetContractByAddress(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	stored := testContract(1)
+	upsertContracts(t, m, stored)
+
+	tests := []struct {
+		name    string
+		address string
+		chainID int64
+		found   bool
+	}{
+		{name: "stored", address: stored.ContractAddress, chainID: 8453, found: true},
+		{name: "unprefixed upper case address", address: strings.ToUpper(stored.ContractAddress[2:]), chainID: 8453, found: true},
+		{name: "other chain", address: stored.ContractAddress, chainID: 84532},
+		{name: "unknown address", address: testContract(2).ContractAddress, chainID: 8453},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The original method reports absence as a nil contract
+			contract, err := m.GetContractByAddress(ctx, tt.address, tt.chainID)
+			if err != nil {
+				t.Fatalf("GetContractByAddress: %v", err)
+			}
+			if got := contract != nil; got != tt.found {
+				t.Fatalf("GetContractByAddress found = %v, want %v", got, tt.found)
+			}
+
+			contract, err = m.MustGetContractByAddress(ctx, tt.address, tt.chainID)
+			if !tt.found {
+				if !errors.Is(err, errdefs.ErrContractNotFound) {
+					t.Fatalf("MustGetContractByAddress error = %v, want %v", err, errdefs.ErrContractNotFound)
+				}
+				if contract != nil {
+					t.Fatalf("MustGetContractByAddress returned %+v alongside the error", contract)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MustGetContractByAddress: %v", err)
+			}
+			if contract.ContractAddress != stored.ContractAddress || contract.ChainID != stored.ChainID {
+				t.Fatalf("MustGetContractByAddress = %s on %d, want %s on %d",
+					contract.ContractAddress, contract.ChainID, stored.ContractAddress, stored.ChainID)
+			}
+		})
+	}
+}
+
+// fakeChangeStream replays change events, each with its own resume token
+type fakeChangeStream struct {
+	events []bson.M
+	next   int
+	err    error
+}
+
+func (s *fakeChangeStream) Next(ctx context.Context) bool {
+	if s.next >= len(s.events) {
+		return false
+	}
+	s.next++
+	return true
+}
+
+func (s *fakeChangeStream) Decode(val interface{}) error {
+	raw, err := bson.Marshal(s.events[s.next-1])
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, val)
+}
+
+func (s *fakeChangeStream) ResumeToken() bson.Raw {
+	token, _ := bson.Marshal(bson.M{"_data": s.next})
+	return token
+}
+
+func (s *fakeChangeStream) Err() error {
+	return s.err
+}
+
+func TestConsumeChangeStreamPersistsResumeTokens(t *testing.T) {
+	contract := func(address string) bson.M {
+		return bson.M{"operationType": "insert", "fullDocument": bson.M{"contractAddress": address}}
+	}
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name          string
+		events        []bson.M
+		callbackErr   map[string]error
+		wantDelivered []string
+		wantTokens    int
+		wantErr       error
+	}{
+		{
+			name:          "token persisted per event",
+			events:        []bson.M{contract("0x01"), contract("0x02"), contract("0x03")},
+			wantDelivered: []string{"0x01", "0x02", "0x03"},
+			wantTokens:    3,
+		},
+		{
+			name:          "event without document still advances the token",
+			events:        []bson.M{contract("0x01"), {"operationType": "update"}, contract("0x03")},
+			wantDelivered: []string{"0x01", "0x03"},
+			wantTokens:    3,
+		},
+		{
+			name:          "failed callback keeps the event unprocessed",
+			events:        []bson.M{contract("0x01"), contract("0x02"), contract("0x03")},
+			callbackErr:   map[string]error{"0x02": errStop},
+			wantDelivered: []string{"0x01"},
+			wantTokens:    1,
+			wantErr:       errStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &DopamintMongoClient{logger: logging.New(io.Discard, "test", slog.LevelDebug)}
+
+			var tokens []bson.Raw
+			watchOpts := &WatchOptions{OnResumeToken: func(token bson.Raw) error {
+				tokens = append(tokens, token)
+				return nil
+			}}
+			var delivered []string
+			callback := func(contract NFTContractDocument) error {
+				if err := tt.callbackErr[contract.ContractAddress]; err != nil {
+					return err
+				}
+				delivered = append(delivered, contract.ContractAddress)
+				return nil
+			}
+
+			_, err := m.consumeChangeStream(context.Background(), &fakeChangeStream{events: tt.events}, watchOpts, callback)
+			var stopErr *watchCallbackError
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && (!errors.As(err, &stopErr) || stopErr.err != tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(delivered, tt.wantDelivered) {
+				t.Errorf("delivered %v, want %v", delivered, tt.wantDelivered)
+			}
+			if len(tokens) != tt.wantTokens {
+				t.Fatalf("persisted %d tokens, want %d", len(tokens), tt.wantTokens)
+			}
+			if tt.wantTokens > 0 && !bytes.Equal(watchOpts.ResumeToken, tokens[len(tokens)-1]) {
+				t.Errorf("ResumeToken = %v, want the last persisted token %v", watchOpts.ResumeToken, tokens[len(tokens)-1])
+			}
+		})
+	}
+}
+
+func TestWatchNFTContractsStopsOnCallbackError(t *testing.T) {
+	m := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Change streams need a replica set
+	probe, err := m.contracts().Watch(ctx, mongo.Pipeline{})
+	if err != nil {
+		t.Skipf("change streams unsupported: %v", err)
+	}
+	probe.Close(ctx)
+
+	// Keep inserting until the watch ends, since contracts stored before
+	// the stream is open are not delivered
+	stop := make(chan struct{})
+	inserted := make(chan struct{})
+	go func() {
+		defer close(inserted)
+		for n := 1; ; n++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+			if _, err := m.contracts().InsertOne(ctx, testContract(n)); err != nil && ctx.Err() == nil {
+				t.Errorf("InsertOne: %v", err)
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-inserted
+	}()
+
+	errStop := errors.New("stop watching")
+	var calls int
+	err = m.WatchNFTContracts(ctx, func(contract NFTContractDocument) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("WatchNFTContracts = %v, want %v", err, errStop)
+	}
+	if calls != 2 {
+		t.Fatalf("callback called %d times, want 2", calls)
+	}
+}
+
+func TestIsInvalidResumeTokenError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"history lost", mongo.CommandError{Code: 286, Name: "ChangeStreamHistoryLost"}, true},
+		{"fatal", mongo.CommandError{Code: 280, Name: "ChangeStreamFatalError"}, true},
+		{"wrapped", fmt.Errorf("change stream error: %w", mongo.CommandError{Code: 286}), true},
+		{"other server error", mongo.CommandError{Code: 189, Message: "resume token of primary stepdown"}, false},
+		{"message only", errors.New("cannot resume: resume token not found"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isInvalidResumeTokenError(tt.err); got != tt.want {
+				t.Errorf("isInvalidResumeTokenError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    string
+	}{
+		{"checksummed", "0xABCDEF0000000000000000000000000000000001", "0xabcdef0000000000000000000000000000000001"},
+		{"surrounding whitespace", "  0xAbCdEf0000000000000000000000000000000001\n", "0xabcdef0000000000000000000000000000000001"},
+		{"missing prefix", "abcdef0000000000000000000000000000000001", "0xabcdef0000000000000000000000000000000001"},
+		{"not an address", " Not-An-Address ", "not-an-address"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAddress(tt.address); got != tt.want {
+				t.Errorf("NormalizeAddress(%q) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeContractDocuments(t *testing.T) {
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		keep  NFTContractDocument
+		dup   NFTContractDocument
+		check func(t *testing.T, got NFTContractDocument)
+	}{
+		{
+			name: "fills missing fields from the duplicate",
+			keep: NFTContractDocument{ContractAddress: "0xaa", Name: "Kept"},
+			dup: NFTContractDocument{
+				ContractAddress: "0xAA",
+				Creator:         "0xABCDEF0000000000000000000000000000000002",
+				Name:            "Duplicate",
+				Symbol:          "DUP",
+				ModelID:         7,
+				DiscoveryBlock:  10,
+				DiscoveryTxHash: "0x01",
+			},
+			check: func(t *testing.T, got NFTContractDocument) {
+				if got.ContractAddress != "0xaa" || got.Name != "Kept" {
+					t.Errorf("kept fields overwritten: %+v", got)
+				}
+				if got.Creator != "0xabcdef0000000000000000000000000000000002" || got.Symbol != "DUP" || got.ModelID != 7 {
+					t.Errorf("missing fields not filled: %+v", got)
+				}
+				if got.DiscoveryBlock != 10 || got.DiscoveryTxHash != "0x01" {
+					t.Errorf("discovery provenance not copied: %+v", got)
+				}
+			},
+		},
+		{
+			name: "keeps the earliest creation and latest update",
+			keep: NFTContractDocument{CreatedAt: late, UpdatedAt: early, SourceBlock: 5, LastActivityBlock: 9},
+			dup:  NFTContractDocument{CreatedAt: early, UpdatedAt: late, SourceBlock: 8, LastActivityBlock: 3},
+			check: func(t *testing.T, got NFTContractDocument) {
+				if !got.CreatedAt.Equal(early) || !got.UpdatedAt.Equal(late) {
+					t.Errorf("createdAt = %v, updatedAt = %v", got.CreatedAt, got.UpdatedAt)
+				}
+				if got.SourceBlock != 8 || got.LastActivityBlock != 9 {
+					t.Errorf("sourceBlock = %d, lastActivityBlock = %d", got.SourceBlock, got.LastActivityBlock)
+				}
+			},
+		},
+		{
+			name: "ignores a zero creation time",
+			keep: NFTContractDocument{CreatedAt: late},
+			dup:  NFTContractDocument{},
+			check: func(t *testing.T, got NFTContractDocument) {
+				if !got.CreatedAt.Equal(late) {
+					t.Errorf("createdAt = %v, want %v", got.CreatedAt, late)
+				}
+			},
+		},
+		{
+			name: "version ends past both documents",
+			keep: NFTContractDocument{Version: 2},
+			dup:  NFTContractDocument{Version: 5},
+			check: func(t *testing.T, got NFTContractDocument) {
+				if got.Version != 6 {
+					t.Errorf("version = %d, want 6", got.Version)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, mergeContractDocuments(tt.keep, tt.dup))
+		})
+	}
+}
+
+func TestNormalizeAddresses(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	canonical := "0xabcdef0000000000000000000000000000000001"
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The same contract in three casings, inserted as is to bypass normalization
+	docs := []interface{}{
+		NFTContractDocument{ContractAddress: "0xABCDEF0000000000000000000000000000000001", ChainID: 8453, Name: "Upper", CreatedAt: early.Add(time.Hour)},
+		NFTContractDocument{ContractAddress: "0xAbCdEf0000000000000000000000000000000001", ChainID: 8453, Symbol: "MIX", CreatedAt: early},
+		NFTContractDocument{ContractAddress: "abcdef0000000000000000000000000000000001", ChainID: 8453, Creator: "0x000000000000000000000000000000000000C0DE", CreatedAt: early.Add(2 * time.Hour)},
+		// Same address on another chain, and a single document needing a rewrite
+		NFTContractDocument{ContractAddress: "0xABCDEF0000000000000000000000000000000001", ChainID: 84532, CreatedAt: early},
+		NFTContractDocument{ContractAddress: canonical, ChainID: 1, Creator: "0x000000000000000000000000000000000000C0DE", CreatedAt: early},
+	}
+	if _, err := m.contracts().InsertMany(ctx, docs); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	// The kept document is merged into and then rewritten, the two duplicates
+	// are removed, and the documents on the other chains are rewritten
+	changed, err := m.NormalizeAddresses(ctx)
+	if err != nil {
+		t.Fatalf("NormalizeAddresses: %v", err)
+	}
+	if changed != 6 {
+		t.Errorf("NormalizeAddresses changed %d documents, want 6", changed)
+	}
+
+	count, err := m.contracts().CountDocuments(ctx, bson.M{"chainId": 8453})
+	if err != nil {
+		t.Fatalf("CountDocuments: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("%d documents remain on chain 8453, want 1", count)
+	}
+	got, err := m.MustGetContractByAddress(ctx, canonical, 8453)
+	if err != nil {
+		t.Fatalf("MustGetContractByAddress: %v", err)
+	}
+	if got.ContractAddress != canonical {
+		t.Errorf("contractAddress = %q, want %q", got.ContractAddress, canonical)
+	}
+	if got.Name != "Upper" || got.Symbol != "MIX" || got.Creator != "0x000000000000000000000000000000000000c0de" {
+		t.Errorf("fields not merged: %+v", got)
+	}
+	if !got.CreatedAt.Equal(early) {
+		t.Errorf("createdAt = %v, want the earliest %v", got.CreatedAt, early)
+	}
+
+	for _, chainID := range []int64{84532, 1} {
+		other, err := m.MustGetContractByAddress(ctx, canonical, chainID)
+		if err != nil {
+			t.Fatalf("MustGetContractByAddress on chain %d: %v", chainID, err)
+		}
+		if other.ContractAddress != canonical || (other.Creator != "" && other.Creator != "0x000000000000000000000000000000000000c0de") {
+			t.Errorf("chain %d contract not normalized: %+v", chainID, other)
+		}
+	}
+
+	if changed, err := m.NormalizeAddresses(ctx); err != nil || changed != 0 {
+		t.Errorf("second NormalizeAddresses = %d, %v, want 0 and no error", changed, err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	const timeout = time.Minute
+
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		callerLimit  time.Duration // caller deadline from now, 0 for none
+		wantDeadline bool
+		wantLimit    time.Duration // expected deadline from now
+	}{
+		{name: "no operation timeout", wantDeadline: false},
+		{name: "no caller deadline", timeout: timeout, wantDeadline: true, wantLimit: timeout},
+		{name: "shorter caller deadline is kept", timeout: timeout, callerLimit: time.Second, wantDeadline: true, wantLimit: time.Second},
+		{name: "longer caller deadline is shortened", timeout: timeout, callerLimit: time.Hour, wantDeadline: true, wantLimit: timeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &DopamintMongoClient{config: MongoDBConfig{OperationTimeout: tt.timeout}}
+
+			parent := context.Background()
+			if tt.callerLimit > 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithTimeout(parent, tt.callerLimit)
+				defer cancel()
+			}
+
+			start := time.Now()
+			ctx, cancel := m.withTimeout(parent)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("has deadline = %v, want %v", ok, tt.wantDeadline)
+			}
+			if !ok {
+				return
+			}
+			if parentDeadline, ok := parent.Deadline(); ok && deadline.After(parentDeadline) {
+				t.Fatalf("deadline %v extends the caller deadline %v", deadline, parentDeadline)
+			}
+			if limit := deadline.Sub(start); limit > tt.wantLimit+time.Second || limit < tt.wantLimit-time.Second {
+				t.Errorf("deadline is %v from now, want about %v", limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestGetStatsByGroup(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	contract := func(n int, status, network string) NFTContractDocument {
+		doc := testContract(n)
+		doc.Status = status
+		doc.Network = network
+		return doc
+	}
+	upsertContracts(t, m,
+		contract(1, StatusActive, "base"),
+		contract(2, StatusActive, "base"),
+		contract(3, StatusActive, "base-sepolia"),
+		contract(4, StatusInactive, "base"),
+		contract(5, StatusDeleted, ""),
+	)
+	// A document written without a network field at all
+	if _, err := m.contracts().InsertOne(ctx, bson.M{"contractAddress": testContract(6).ContractAddress, "chainId": 8453, "status": StatusActive}); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	got, err := m.GetStatsByGroup(ctx)
+	if err != nil {
+		t.Fatalf("GetStatsByGroup: %v", err)
+	}
+
+	want := map[string]map[string]int64{
+		StatusActive:   {"base": 2, "base-sepolia": 1, "": 1},
+		StatusInactive: {"base": 1},
+		StatusDeleted:  {"": 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetStatsByGroup = %v, want %v", got, want)
+	}
+}
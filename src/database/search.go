@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Search result limits
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 200
+)
+
+// contractTextIndex backs SearchContracts when MongoDBConfig.TextSearch is set
+var contractTextIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "symbol", Value: "text"}},
+	Options: options.Index().SetName("name_symbol_text"),
+}
+
+// SearchContracts finds non-deleted contracts whose name or symbol matches
+// query, on chainID or on every chain when chainID is 0
+// With MongoDBConfig.TextSearch enabled the text index is used, matching
+// whole words ordered by relevance; otherwise query matches any part of the
+// name or symbol case-insensitively, newest first
+// A non-positive limit returns up to 20 contracts, and at most 200 are returned
+func (m *DopamintMongoClient) SearchContracts(ctx context.Context, query string, chainID int64, limit int64) ([]NFTContractDocument, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	filter := bson.M{"status": bson.M{"$ne": StatusDeleted}}
+	if chainID != 0 {
+		filter["chainId"] = chainID
+	}
+	opts := options.Find().SetLimit(limit)
+
+	if m.config.TextSearch {
+		score := bson.M{"$meta": "textScore"}
+		filter["$text"] = bson.M{"$search": query}
+		opts.SetProjection(bson.M{"score": score}).
+			SetSort(bson.D{{Key: "score", Value: score}, {Key: "createdAt", Value: -1}})
+	} else {
+		pattern := literalRegex(query)
+		filter["$or"] = bson.A{
+			bson.M{"name": pattern},
+			bson.M{"symbol": pattern},
+		}
+		opts.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	}
+
+	contracts, err := m.findContracts(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search contracts: %w", err)
+	}
+
+	return contracts, nil
+}
+
+// literalRegex returns a case-insensitive regex matching query literally
+func literalRegex(query string) bson.M {
+	return bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"}
+}
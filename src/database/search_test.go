@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSearchContracts(t *testing.T) {
+	m := newTestClient(t)
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	contract := func(n int, name, symbol string, chainID int64, status string) NFTContractDocument {
+		doc := testContract(n)
+		doc.Name = name
+		doc.Symbol = symbol
+		doc.ChainID = chainID
+		doc.Status = status
+		doc.CreatedAt = base.Add(time.Duration(n) * time.Hour)
+		return doc
+	}
+	upsertContracts(t, m,
+		contract(1, "Dopamint Genesis", "DOPA", 8453, StatusActive),
+		contract(2, "Genesis Apes", "GAPE", 8453, StatusInactive),
+		contract(3, "Deleted Genesis", "DEL", 8453, StatusDeleted),
+		contract(4, "Pixel Cats", "PIXEL", 84532, StatusActive),
+		contract(5, "a.b Collection", "AB", 8453, StatusActive),
+		contract(6, "aXb Club", "AXB", 8453, StatusActive),
+	)
+	addr := func(n int) string { return testContract(n).ContractAddress }
+
+	tests := []struct {
+		name    string
+		text    bool
+		query   string
+		chainID int64
+		limit   int64
+		// want is in result order, or sorted when ordered is false
+		want    []string
+		ordered bool
+	}{
+		{name: "name newest first without deleted", query: "genesis", chainID: 8453, want: []string{addr(2), addr(1)}, ordered: true},
+		{name: "symbol case insensitive", query: "gape", chainID: 8453, want: []string{addr(2)}, ordered: true},
+		{name: "substring of name", query: "  pix ", want: []string{addr(4)}, ordered: true},
+		{name: "other chain", query: "pix", chainID: 8453},
+		{name: "query matched literally", query: "a.b", chainID: 8453, want: []string{addr(5)}, ordered: true},
+		{name: "limit", query: "genesis", chainID: 8453, limit: 1, want: []string{addr(2)}, ordered: true},
+		{name: "text index", text: true, query: "genesis", chainID: 8453, want: []string{addr(1), addr(2)}},
+		{name: "text index on symbol", text: true, query: "pixel", want: []string{addr(4)}},
+		{name: "text index matches whole words", text: true, query: "pix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.config.TextSearch = tt.text
+			if tt.text {
+				if err := m.EnsureIndexes(ctx); err != nil {
+					t.Fatalf("EnsureIndexes: %v", err)
+				}
+			}
+
+			contracts, err := m.SearchContracts(ctx, tt.query, tt.chainID, tt.limit)
+			if err != nil {
+				t.Fatalf("SearchContracts(%q): %v", tt.query, err)
+			}
+			var got []string
+			for _, contract := range contracts {
+				got = append(got, contract.ContractAddress)
+			}
+			if !tt.ordered {
+				sort.Strings(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchContracts(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchContractsEmptyQuery(t *testing.T) {
+	// The query is validated before MongoDB is contacted
+	m := &DopamintMongoClient{}
+	for _, query := range []string{"", "   "} {
+		if _, err := m.SearchContracts(context.Background(), query, 8453, 10); err == nil {
+			t.Errorf("SearchContracts(%q) succeeded, want error", query)
+		}
+	}
+}
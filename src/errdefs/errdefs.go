@@ -0,0 +1,23 @@
+// Package errdefs declares the error categories shared by the indexer
+// packages, so errors.Is matches them whichever package wrapped them
+package errdefs
+
+import (
+	"errors"
+)
+
+// Error categories, usable with errors.Is
+var (
+	// ErrContractNotFound is wrapped by errors for operations on a contract
+	// that is not stored
+	ErrContractNotFound = errors.New("contract not found")
+	// ErrConnection is wrapped by errors for failing to reach MongoDB or any
+	// RPC endpoint
+	ErrConnection = errors.New("connection failed")
+	// ErrInvalidConfig is wrapped by errors for configs that cannot be
+	// parsed or applied
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrInvalidEvent is wrapped by errors for logs that are not the event
+	// being parsed or do not match its declared layout
+	ErrInvalidEvent = errors.New("invalid event")
+)
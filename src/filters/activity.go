@@ -0,0 +1,74 @@
+package filters
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RecordActivity records that a watched address produced an indexed log at
+// block, keeping the highest block seen
+// Addresses that are not watched are ignored
+func (cf *ContractFilter) RecordActivity(address common.Address, block uint64) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	cf.recordActivityLocked(address, block)
+}
+
+// RecordLogActivity records the activity of every watched address in logs
+// Removed (reorged) logs are ignored
+func (cf *ContractFilter) RecordLogActivity(logs []types.Log) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	for _, log := range logs {
+		if !log.Removed {
+			cf.recordActivityLocked(log.Address, log.BlockNumber)
+		}
+	}
+}
+
+// recordActivityLocked updates the last activity of a watched address;
+// caller must hold cf.mu
+func (cf *ContractFilter) recordActivityLocked(address common.Address, block uint64) {
+	if !cf.nftContracts[address] && !cf.isFactoryLocked(address) && address != cf.paymentAddress {
+		return
+	}
+	if block > cf.lastActivity[address] {
+		cf.lastActivity[address] = block
+		cf.touchActivityLocked(address, block)
+	}
+}
+
+// LastActivity returns the last block at which address produced an indexed
+// log, and false if no activity has been recorded for it
+func (cf *ContractFilter) LastActivity(address common.Address) (uint64, bool) {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	block, ok := cf.lastActivity[address]
+	return block, ok
+}
+
+// StaleContracts returns the discovered NFT contracts without any recorded
+// activity in the olderThanBlocks blocks before currentBlock, sorted by address
+// Contracts with no recorded activity at all are considered stale
+// Factory and payment contracts are never reported
+func (cf *ContractFilter) StaleContracts(olderThanBlocks uint64, currentBlock uint64) []common.Address {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	// Nothing can be stale before the window has fully elapsed
+	if currentBlock < olderThanBlocks {
+		return nil
+	}
+	cutoff := currentBlock - olderThanBlocks
+
+	var stale []common.Address
+	for _, addr := range cf.sortedNFTContractsLocked() {
+		if block, ok := cf.lastActivity[addr]; !ok || block < cutoff {
+			stale = append(stale, addr)
+		}
+	}
+	return stale
+}
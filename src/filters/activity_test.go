@@ -0,0 +1,56 @@
+package filters_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestStaleContracts(t *testing.T) {
+	nfts := nftAddresses(4)
+	unwatched := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+
+	cf := newFilter(t, filterConfig)
+	for _, addr := range nfts {
+		cf.AddNFTContract(addr)
+	}
+	cf.RecordActivity(nfts[0], 950)
+	cf.RecordActivity(nfts[0], 900) // older blocks do not move activity back
+	cf.RecordActivity(nfts[1], 800)
+	cf.RecordActivity(factoryAddr, 100)
+	cf.RecordActivity(unwatched, 990)
+	// nfts[2] has no activity; the reorged log of nfts[3] does not count
+	cf.RecordLogActivity([]types.Log{
+		{Address: nfts[3], BlockNumber: 990},
+		{Address: nfts[3], BlockNumber: 999, Removed: true},
+	})
+
+	tests := []struct {
+		name         string
+		olderThan    uint64
+		currentBlock uint64
+		want         []common.Address
+	}{
+		{name: "short window", olderThan: 20, currentBlock: 1000, want: []common.Address{nfts[0], nfts[1], nfts[2]}},
+		{name: "medium window", olderThan: 100, currentBlock: 1000, want: []common.Address{nfts[1], nfts[2]}},
+		{name: "long window", olderThan: 500, currentBlock: 1000, want: []common.Address{nfts[2]}},
+		{name: "window not yet elapsed", olderThan: 2000, currentBlock: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cf.StaleContracts(tt.olderThan, tt.currentBlock); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StaleContracts(%d, %d) = %v, want %v", tt.olderThan, tt.currentBlock, got, tt.want)
+			}
+		})
+	}
+
+	if block, ok := cf.LastActivity(nfts[3]); !ok || block != 990 {
+		t.Errorf("LastActivity(nfts[3]) = %d, %v, want 990, true", block, ok)
+	}
+	if _, ok := cf.LastActivity(unwatched); ok {
+		t.Errorf("activity recorded for an unwatched address")
+	}
+}
@@ -0,0 +1,176 @@
+package filters
+
+import (
+	"bytes"
+	"container/heap"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EvictionPolicy decides what happens when a capped watch set is full
+type EvictionPolicy string
+
+// Eviction policies
+const (
+	// EvictLeastRecentlyActive drops the NFT contract with the oldest recorded
+	// activity to make room; contracts without activity go first
+	EvictLeastRecentlyActive EvictionPolicy = "lru"
+	// RejectNew keeps the watch set as is and refuses the new contract
+	RejectNew EvictionPolicy = "reject"
+)
+
+// WithMaxContracts caps the number of watched NFT contracts, applying policy
+// once the cap is reached. A non-positive max leaves the watch set unbounded
+// Any policy other than EvictLeastRecentlyActive behaves as RejectNew
+// Factory and payment contracts do not count towards the cap and are never evicted
+func WithMaxContracts(max int, policy EvictionPolicy) ContractFilterOption {
+	return func(cf *ContractFilter) {
+		cf.maxContracts = max
+		cf.evictionPolicy = policy
+	}
+}
+
+// addNFTContractLocked adds address to the watch set, enforcing the cap
+// Returns false if the contract was already watched or was rejected
+// The caller must hold cf.mu for writing
+func (cf *ContractFilter) addNFTContractLocked(address common.Address) bool {
+	if cf.nftContracts[address] {
+		return false
+	}
+
+	if !cf.hasRoomLocked() {
+		if cf.evictionPolicy != EvictLeastRecentlyActive || !cf.evictLocked() {
+			cf.rejectedContracts++
+			cf.logCapacityLocked("Watch set full, rejected NFT contract", cf.rejectedContracts, "address", address.Hex())
+			return false
+		}
+	}
+
+	cf.nftContracts[address] = true
+	delete(cf.evicted, address)
+	entry := &activityEntry{address: address, block: cf.lastActivity[address]}
+	cf.activityEntries[address] = entry
+	heap.Push(&cf.activityOrder, entry)
+	return true
+}
+
+// hasRoomLocked reports whether another NFT contract fits under the cap
+func (cf *ContractFilter) hasRoomLocked() bool {
+	return cf.maxContracts <= 0 || cf.capacityUsedLocked() < cf.maxContracts
+}
+
+// capacityUsedLocked returns the number of watched contracts counting
+// towards the cap
+func (cf *ContractFilter) capacityUsedLocked() int {
+	used := len(cf.nftContracts)
+	core := make(map[common.Address]bool, len(cf.factoryAddresses)+1)
+	for _, factory := range cf.factoryAddresses {
+		core[factory] = true
+	}
+	core[cf.paymentAddress] = true
+	for addr := range core {
+		if cf.nftContracts[addr] {
+			used--
+		}
+	}
+	return used
+}
+
+// isCoreLocked reports whether address is a factory or the payment contract
+func (cf *ContractFilter) isCoreLocked(address common.Address) bool {
+	return cf.isFactoryLocked(address) || address == cf.paymentAddress
+}
+
+// evictLocked removes the least recently active NFT contract, breaking ties
+// by address so the choice is deterministic. The contract is remembered so
+// MongoDB syncs do not re-add it while the watch set is full
+// Returns false if there was no contract that may be evicted
+func (cf *ContractFilter) evictLocked() bool {
+	// Contracts that became factories or the payment contract are set aside
+	// and put back once a victim is found
+	var skipped []*activityEntry
+	defer func() {
+		for _, entry := range skipped {
+			heap.Push(&cf.activityOrder, entry)
+		}
+	}()
+
+	for cf.activityOrder.Len() > 0 {
+		victim := heap.Pop(&cf.activityOrder).(*activityEntry)
+		if cf.isCoreLocked(victim.address) {
+			skipped = append(skipped, victim)
+			continue
+		}
+
+		cf.deleteNFTContractLocked(victim.address)
+		cf.evicted[victim.address] = true
+		cf.evictedContracts++
+		cf.logCapacityLocked("Watch set full, evicted least recently active NFT contract", cf.evictedContracts,
+			"address", victim.address.Hex(), "lastActivity", victim.block)
+		return true
+	}
+
+	return false
+}
+
+// touchActivityLocked moves a watched NFT contract to its new place in the
+// eviction order after its last activity changed
+func (cf *ContractFilter) touchActivityLocked(address common.Address, block uint64) {
+	if entry, ok := cf.activityEntries[address]; ok {
+		entry.block = block
+		heap.Fix(&cf.activityOrder, entry.index)
+	}
+}
+
+// activityEntry is a watched NFT contract in the eviction order
+type activityEntry struct {
+	address common.Address
+	block   uint64
+	index   int // position in activityHeap, maintained by the heap
+}
+
+// activityHeap orders NFT contracts by last activity, least recent first,
+// breaking ties by address; it implements heap.Interface
+type activityHeap []*activityEntry
+
+func (h activityHeap) Len() int { return len(h) }
+
+func (h activityHeap) Less(i, j int) bool {
+	if h[i].block != h[j].block {
+		return h[i].block < h[j].block
+	}
+	return bytes.Compare(h[i].address.Bytes(), h[j].address.Bytes()) < 0
+}
+
+func (h activityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *activityHeap) Push(x interface{}) {
+	entry := x.(*activityEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *activityHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// logCapacityLocked logs a cap enforcement, warning on the first occurrence
+// only so a flood of additions does not flood the logs as well
+func (cf *ContractFilter) logCapacityLocked(msg string, count uint64, keysAndValues ...interface{}) {
+	keysAndValues = append(keysAndValues, "maxContracts", cf.maxContracts, "count", count)
+	if count == 1 {
+		cf.logger.Warn(msg, keysAndValues...)
+		return
+	}
+	cf.logger.Debug(msg, keysAndValues...)
+}
@@ -0,0 +1,107 @@
+package filters_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters/filterstest"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWatchSetCap(t *testing.T) {
+	a := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	b := common.HexToAddress("0x0000000000000000000000000000000000000e02")
+	c := common.HexToAddress("0x0000000000000000000000000000000000000e03")
+
+	tests := []struct {
+		name         string
+		policy       filters.EvictionPolicy
+		wantWatched  []common.Address
+		wantDropped  []common.Address
+		wantEvicted  uint64
+		wantRejected uint64
+	}{
+		{
+			name:        "evicts least recently active",
+			policy:      filters.EvictLeastRecentlyActive,
+			wantWatched: []common.Address{b, c},
+			wantDropped: []common.Address{a},
+			wantEvicted: 1,
+		},
+		{
+			name:         "rejects new contracts",
+			policy:       filters.RejectNew,
+			wantWatched:  []common.Address{a, b},
+			wantDropped:  []common.Address{c},
+			wantRejected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newSyncFilter(t, filters.WithMaxContracts(2, tt.policy))
+			cf.AddNFTContract(a)
+			cf.AddNFTContract(b)
+			cf.RecordActivity(a, 10)
+			cf.RecordActivity(b, 20)
+			cf.AddNFTContract(c)
+
+			for _, addr := range tt.wantWatched {
+				if !cf.Contains(addr) {
+					t.Errorf("%s not watched", addr.Hex())
+				}
+			}
+			for _, addr := range tt.wantDropped {
+				if cf.Contains(addr) {
+					t.Errorf("%s still watched", addr.Hex())
+				}
+			}
+			stats := cf.FilterStats()
+			if stats.EvictedContracts != tt.wantEvicted || stats.RejectedContracts != tt.wantRejected {
+				t.Errorf("evicted/rejected = %d/%d, want %d/%d",
+					stats.EvictedContracts, stats.RejectedContracts, tt.wantEvicted, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestMongoDBSyncRespectsEvictions(t *testing.T) {
+	a := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	b := common.HexToAddress("0x0000000000000000000000000000000000000e02")
+	c := common.HexToAddress("0x0000000000000000000000000000000000000e03")
+
+	cf := newSyncFilter(t, filters.WithMaxContracts(2, filters.EvictLeastRecentlyActive))
+	mock := filterstest.NewMockMongoDBClient(a, b)
+	sync := func() {
+		t.Helper()
+		if err := cf.WarmFromMongoDB(context.Background(), mock); err != nil {
+			t.Fatalf("WarmFromMongoDB: %v", err)
+		}
+	}
+
+	sync()
+	cf.RecordActivity(a, 10)
+	cf.RecordActivity(b, 20)
+	mock.SetAddresses(a, b, c)
+	sync()
+	evicted := cf.FilterStats().EvictedContracts
+	if evicted != 1 || cf.Contains(a) || !cf.Contains(c) {
+		t.Fatalf("after new contract: evicted = %d, a watched = %v, c watched = %v", evicted, cf.Contains(a), cf.Contains(c))
+	}
+
+	// Further syncs returning the same contracts must not churn the watch set
+	for i := 0; i < 3; i++ {
+		sync()
+	}
+	if got := cf.FilterStats().EvictedContracts; got != evicted || cf.Contains(a) {
+		t.Fatalf("syncs churned the watch set: evicted = %d, a watched = %v", got, cf.Contains(a))
+	}
+
+	// The evicted contract comes back once there is room again
+	mock.SetAddresses(a, b)
+	sync()
+	if !cf.Contains(a) || cf.Contains(c) {
+		t.Fatalf("after room freed: a watched = %v, c watched = %v", cf.Contains(a), cf.Contains(c))
+	}
+}
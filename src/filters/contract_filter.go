@@ -1,27 +1,58 @@
 package filters
 
 import (
+	"bytes"
+	"container/heap"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // ContractFilter manages which contracts to index
 type ContractFilter struct {
-	mu                sync.RWMutex
-	factoryAddress    common.Address
-	paymentAddress    common.Address
-	nftContracts      map[common.Address]bool
-	enabled           bool
-	autoDiscovery     bool
-	mongodbSyncEnabled bool
+	mu                  sync.RWMutex
+	chainID             int64
+	factoryAddresses    []common.Address
+	factoryOverride     []common.Address // set by SetFactoryAddresses, replaces the config factories
+	addedFactories      []common.Address // added by AddFactoryAddress on top of the config
+	paymentAddress      common.Address
+	nftContracts        map[common.Address]bool
+	configuredContracts map[common.Address]bool // NFT contracts listed in the config, kept by MongoDB syncs
+	allowedTopics       map[common.Address]map[common.Hash]bool
+	filterMode          string
+	roleTopics          map[string]map[common.Hash]bool
+	lastActivity        map[common.Address]uint64
+	activityOrder       activityHeap                      // NFT contracts in eviction order
+	activityEntries     map[common.Address]*activityEntry // activityOrder entries by address
+	evicted             map[common.Address]bool           // evicted NFT contracts, not re-added by syncs while full
+	discoveredAt        map[common.Address]time.Time      // discovered on chain, not yet returned by MongoDB
+	discoveryGrace      time.Duration
+	maxContracts        int
+	evictionPolicy      EvictionPolicy
+	rejectedContracts   uint64
+	evictedContracts    uint64
+	logger              logging.Logger
+	enabled             bool
+	autoDiscovery       bool
+	mongodbSyncEnabled  bool
 	mongodbSyncInterval time.Duration
+	lastSyncTime        time.Time
+	lastSyncErr         error
+	syncFailures        int
+	emptySyncs          int  // consecutive successful syncs that returned no contracts
+	warmed              bool // a successful WarmFromMongoDB not yet followed by a sync loop
+	requireFilter       bool
 }
 
 // ContractConfig represents the contract configuration
@@ -31,6 +62,7 @@ type ContractConfig struct {
 	Contracts struct {
 		Factory struct {
 			Address     string   `json:"address"`
+			Addresses   []string `json:"addresses"` // additional factories, e.g. one per model version
 			Name        string   `json:"name"`
 			Description string   `json:"description"`
 			Events      []string `json:"events"`
@@ -45,8 +77,16 @@ type ContractConfig struct {
 	} `json:"contracts"`
 	EventFilters struct {
 		Enabled     bool   `json:"enabled"`
-		FilterMode  string `json:"filterMode"`
+		FilterMode  string `json:"filterMode"` // "all" (default) or "allowlist"
 		Description string `json:"description"`
+		// AllowedEvents lists, per contract role, the event signatures such as
+		// "Transfer(address,address,uint256)" or topic0 hashes indexed in
+		// allowlist mode. Roles without entries are not restricted
+		AllowedEvents struct {
+			Factory []string `json:"factory"`
+			Payment []string `json:"payment"`
+			NFT     []string `json:"nft"`
+		} `json:"allowedEvents"`
 	} `json:"eventFilters"`
 	SyncSettings struct {
 		MongoDBSync struct {
@@ -61,8 +101,85 @@ type ContractConfig struct {
 	} `json:"syncSettings"`
 }
 
+// Event filter modes
+const (
+	FilterModeAll       = "all"
+	FilterModeAllowlist = "allowlist"
+	// filterModeWhitelist is the former name of FilterModeAllowlist, still
+	// accepted in configs
+	filterModeWhitelist = "whitelist"
+)
+
+// Contract roles used to look up event allowlists
+const (
+	roleFactory = "factory"
+	rolePayment = "payment"
+	roleNFT     = "nft"
+)
+
+// defaultDiscoveryGracePeriod is how long a discovered contract survives
+// syncs that do not return it, giving the backend time to store it
+const defaultDiscoveryGracePeriod = 15 * time.Minute
+
+// ContractFilterOption configures optional ContractFilter behaviour
+type ContractFilterOption func(*ContractFilter)
+
+// WithFilterLogger sets the logger of a ContractFilter
+func WithFilterLogger(logger logging.Logger) ContractFilterOption {
+	return func(cf *ContractFilter) {
+		cf.logger = logger
+	}
+}
+
+// WithRequireFilter makes the filter refuse configs that disable event
+// filtering, since every log on the chain would then be fetched and indexed
+// Without it such configs are accepted with a warning when they configure
+// contracts to watch, as disabling filtering is then likely a mistake
+func WithRequireFilter(require bool) ContractFilterOption {
+	return func(cf *ContractFilter) {
+		cf.requireFilter = require
+	}
+}
+
+// WithDiscoveryGracePeriod sets how long a contract discovered on chain is
+// kept by MongoDB syncs that do not return it yet. A non-positive period
+// removes such contracts on the next sync
+func WithDiscoveryGracePeriod(period time.Duration) ContractFilterOption {
+	return func(cf *ContractFilter) {
+		cf.discoveryGrace = period
+	}
+}
+
 // NewContractFilter creates a new contract filter
-func NewContractFilter(configPath string) (*ContractFilter, error) {
+func NewContractFilter(configPath string, opts ...ContractFilterOption) (*ContractFilter, error) {
+	config, err := loadContractConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &ContractFilter{
+		nftContracts:    make(map[common.Address]bool),
+		allowedTopics:   make(map[common.Address]map[common.Hash]bool),
+		lastActivity:    make(map[common.Address]uint64),
+		activityEntries: make(map[common.Address]*activityEntry),
+		evicted:         make(map[common.Address]bool),
+		discoveredAt:    make(map[common.Address]time.Time),
+		discoveryGrace:  defaultDiscoveryGracePeriod,
+		logger:          logging.Default("ContractFilter"),
+	}
+	for _, opt := range opts {
+		opt(filter)
+	}
+	if err := filter.checkFiltering(config); err != nil {
+		return nil, err
+	}
+	filter.applyConfig(config)
+
+	return filter, nil
+}
+
+// loadContractConfig reads and parses a contract config file
+func loadContractConfig(configPath string) (*ContractConfig, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -70,27 +187,281 @@ func NewContractFilter(configPath string) (*ContractFilter, error) {
 
 	var config ContractConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+		return nil, fmt.Errorf("failed to parse config: %w: %w", errdefs.ErrInvalidConfig, err)
 	}
 
-	filter := &ContractFilter{
-		factoryAddress:      common.HexToAddress(config.Contracts.Factory.Address),
-		paymentAddress:      common.HexToAddress(config.Contracts.Payment.Address),
-		nftContracts:        make(map[common.Address]bool),
-		enabled:             config.EventFilters.Enabled,
-		autoDiscovery:       config.SyncSettings.AutoDiscovery.Enabled,
-		mongodbSyncEnabled:  config.SyncSettings.MongoDBSync.Enabled,
-		mongodbSyncInterval: time.Duration(config.SyncSettings.MongoDBSync.IntervalSeconds) * time.Second,
-	}
+	return &config, nil
+}
+
+// applyConfig sets the config-derived fields of the filter, merging the
+// configured NFT contracts into the existing watch set
+// Factories set or added at runtime are kept over the configured ones
+// The caller must hold cf.mu or otherwise own cf exclusively
+func (cf *ContractFilter) applyConfig(config *ContractConfig) {
+	cf.chainID = int64(config.ChainID)
+	cf.applyFactoriesLocked(config)
+	cf.paymentAddress = common.HexToAddress(config.Contracts.Payment.Address)
+	cf.enabled = config.EventFilters.Enabled
+	cf.autoDiscovery = config.SyncSettings.AutoDiscovery.Enabled
+	cf.mongodbSyncEnabled = config.SyncSettings.MongoDBSync.Enabled
+	cf.mongodbSyncInterval = time.Duration(config.SyncSettings.MongoDBSync.IntervalSeconds) * time.Second
 
 	// Load initial NFT contracts
+	cf.configuredContracts = make(map[common.Address]bool, len(config.Contracts.NFTContracts))
 	for _, addr := range config.Contracts.NFTContracts {
 		if addr != "" {
-			filter.nftContracts[common.HexToAddress(addr)] = true
+			address := common.HexToAddress(addr)
+			cf.configuredContracts[address] = true
+			cf.addNFTContractLocked(address)
 		}
 	}
 
-	return filter, nil
+	cf.filterMode = FilterModeAll
+	switch config.EventFilters.FilterMode {
+	case FilterModeAllowlist, filterModeWhitelist:
+		cf.filterMode = FilterModeAllowlist
+	}
+
+	allowed := config.EventFilters.AllowedEvents
+	cf.roleTopics = map[string]map[common.Hash]bool{
+		roleFactory: cf.eventTopicSet(allowed.Factory),
+		rolePayment: cf.eventTopicSet(allowed.Payment),
+		roleNFT:     cf.eventTopicSet(allowed.NFT),
+	}
+}
+
+// applyFactoriesLocked sets the factories from config, unless they were
+// replaced with SetFactoryAddresses, and re-adds those from AddFactoryAddress
+func (cf *ContractFilter) applyFactoriesLocked(config *ContractConfig) {
+	if cf.factoryOverride != nil {
+		cf.factoryAddresses = append([]common.Address(nil), cf.factoryOverride...)
+	} else {
+		cf.factoryAddresses = []common.Address{common.HexToAddress(config.Contracts.Factory.Address)}
+		for _, addr := range config.Contracts.Factory.Addresses {
+			if addr != "" {
+				cf.addFactoryLocked(common.HexToAddress(addr))
+			}
+		}
+	}
+
+	for _, addr := range cf.addedFactories {
+		cf.addFactoryLocked(addr)
+	}
+}
+
+// eventTopic returns the topic0 of an event given either its signature, e.g.
+// "Transfer(address,address,uint256)", or its hash as hex
+func eventTopic(event string) (common.Hash, bool) {
+	event = strings.TrimSpace(event)
+	if strings.HasPrefix(event, "0x") && len(event) == 2+2*common.HashLength {
+		if _, err := hexutil.Decode(event); err == nil {
+			return common.HexToHash(event), true
+		}
+		return common.Hash{}, false
+	}
+
+	if strings.Index(event, "(") <= 0 || !strings.HasSuffix(event, ")") || strings.ContainsAny(event, " \t") {
+		return common.Hash{}, false
+	}
+	return crypto.Keccak256Hash([]byte(event)), true
+}
+
+// eventTopicSet converts configured event signatures to a topic0 set,
+// skipping and reporting malformed entries
+func (cf *ContractFilter) eventTopicSet(events []string) map[common.Hash]bool {
+	topics := make(map[common.Hash]bool, len(events))
+	for _, event := range events {
+		topic, ok := eventTopic(event)
+		if !ok {
+			cf.logger.Warn("Ignoring invalid event signature in allowlist", "event", event)
+			continue
+		}
+		topics[topic] = true
+	}
+	return topics
+}
+
+// ConfigError lists every problem found while validating a contract config
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config: %s", strings.Join(e.Problems, "; "))
+}
+
+// Unwrap makes a ConfigError match errdefs.ErrInvalidConfig
+func (e *ConfigError) Unwrap() error {
+	return errdefs.ErrInvalidConfig
+}
+
+// validateContractConfig checks that a config can be applied safely,
+// reporting every problem found as a *ConfigError
+// Strict mode additionally requires the factory and payment addresses and
+// rejects duplicate NFT contracts
+func validateContractConfig(config *ContractConfig, strict bool) error {
+	var problems []string
+
+	// An empty address is an unconfigured contract, as when loading a config
+	factory := config.Contracts.Factory.Address
+	if factory == "" && strict {
+		problems = append(problems, "factory address is required")
+	} else if factory != "" && !common.IsHexAddress(factory) {
+		problems = append(problems, fmt.Sprintf("invalid factory address %q", factory))
+	}
+	for _, addr := range config.Contracts.Factory.Addresses {
+		if addr != "" && !common.IsHexAddress(addr) {
+			problems = append(problems, fmt.Sprintf("invalid factory address %q", addr))
+		}
+	}
+
+	payment := config.Contracts.Payment.Address
+	if payment == "" && strict {
+		problems = append(problems, "payment address is required")
+	} else if payment != "" && !common.IsHexAddress(payment) {
+		problems = append(problems, fmt.Sprintf("invalid payment address %q", payment))
+	}
+
+	seen := make(map[common.Address]bool, len(config.Contracts.NFTContracts))
+	for _, addr := range config.Contracts.NFTContracts {
+		if !common.IsHexAddress(addr) {
+			problems = append(problems, fmt.Sprintf("invalid NFT contract address %q", addr))
+			continue
+		}
+		if strict && seen[common.HexToAddress(addr)] {
+			problems = append(problems, fmt.Sprintf("duplicate NFT contract address %q", addr))
+		}
+		seen[common.HexToAddress(addr)] = true
+	}
+
+	switch config.EventFilters.FilterMode {
+	case "", FilterModeAll, FilterModeAllowlist, filterModeWhitelist:
+	default:
+		problems = append(problems, fmt.Sprintf("invalid filterMode %q (expected %q or %q)",
+			config.EventFilters.FilterMode, FilterModeAll, FilterModeAllowlist))
+	}
+
+	allowed := config.EventFilters.AllowedEvents
+	roleEvents := []struct {
+		role   string
+		events []string
+	}{
+		{roleFactory, allowed.Factory},
+		{rolePayment, allowed.Payment},
+		{roleNFT, allowed.NFT},
+	}
+	for _, re := range roleEvents {
+		for _, event := range re.events {
+			if _, ok := eventTopic(event); !ok {
+				problems = append(problems, fmt.Sprintf("invalid %s event signature %q", re.role, event))
+			}
+		}
+	}
+
+	if config.SyncSettings.MongoDBSync.Enabled && config.SyncSettings.MongoDBSync.IntervalSeconds <= 0 {
+		problems = append(problems, "mongodbSync.intervalSeconds must be positive when sync is enabled")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// checkFiltering guards against configs that disable event filtering, which
+// makes every log on the chain be fetched and indexed
+// It returns an error wrapping errdefs.ErrInvalidConfig when filtering is required,
+// and otherwise warns if the config lists contracts to watch
+func (cf *ContractFilter) checkFiltering(config *ContractConfig) error {
+	if config.EventFilters.Enabled {
+		return nil
+	}
+	if cf.requireFilter {
+		return fmt.Errorf("%w: eventFilters.enabled is false but filtering is required", errdefs.ErrInvalidConfig)
+	}
+
+	if configuresWatchSet(config) {
+		cf.logger.Warn("EVENT FILTERING IS DISABLED: every log on the chain will be fetched and indexed, "+
+			"although contracts to watch are configured; set eventFilters.enabled to true unless this is intended",
+			"network", config.Network, "chainId", config.ChainID)
+	}
+	return nil
+}
+
+// configuresWatchSet reports whether config lists any factory, payment or
+// NFT contract
+func configuresWatchSet(config *ContractConfig) bool {
+	configured := func(addr string) bool {
+		return addr != "" && common.HexToAddress(addr) != (common.Address{})
+	}
+
+	contracts := config.Contracts
+	if configured(contracts.Factory.Address) || configured(contracts.Payment.Address) {
+		return true
+	}
+	for _, addr := range contracts.Factory.Addresses {
+		if configured(addr) {
+			return true
+		}
+	}
+	for _, addr := range contracts.NFTContracts {
+		if configured(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewContractFilterStrict creates a new contract filter, rejecting configs
+// with missing or malformed addresses or duplicate NFT contracts
+// All problems are reported at once in a *ConfigError
+func NewContractFilterStrict(configPath string, opts ...ContractFilterOption) (*ContractFilter, error) {
+	if err := ValidateConfigFile(configPath); err != nil {
+		return nil, err
+	}
+	return NewContractFilter(configPath, opts...)
+}
+
+// ValidateConfigFile strictly validates a config file without creating a
+// filter, for dry-run checks before deployment
+func ValidateConfigFile(configPath string) error {
+	config, err := loadContractConfig(configPath)
+	if err != nil {
+		return err
+	}
+	return validateContractConfig(config, true)
+}
+
+// ReloadConfig re-reads the config file and swaps in the new settings,
+// keeping dynamically discovered NFT contracts
+// Factories replaced with SetFactoryAddresses or added with AddFactoryAddress
+// are kept as well, so the factories of the file only apply when neither
+// was used. The current config is left untouched if the new file is invalid
+func (cf *ContractFilter) ReloadConfig(configPath string) error {
+	config, err := loadContractConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := validateContractConfig(config, false); err != nil {
+		return err
+	}
+	if err := cf.checkFiltering(config); err != nil {
+		return err
+	}
+
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	cf.applyConfig(config)
+
+	if cf.factoryOverride != nil {
+		cf.logger.Warn("Kept factory contracts set at runtime, ignoring the factories in the config",
+			"configFactory", config.Contracts.Factory.Address, "factories", len(cf.factoryAddresses))
+	}
+	cf.logger.Info("Reloaded config", "path", configPath, "enabled", cf.enabled,
+		"factories", len(cf.factoryAddresses), "nftContracts", len(cf.nftContracts))
+	return nil
 }
 
 // IsEnabled returns whether filtering is enabled
@@ -100,17 +471,45 @@ func (cf *ContractFilter) IsEnabled() bool {
 	return cf.enabled
 }
 
+// ChainID returns the chain ID of the network the filter is configured for
+func (cf *ContractFilter) ChainID() int64 {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.chainID
+}
+
 // ShouldIndexLog determines if a log should be indexed
 func (cf *ContractFilter) ShouldIndexLog(address common.Address) bool {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
 	if !cf.enabled {
 		return true // Index everything if filtering is disabled
 	}
 
+	return cf.containsLocked(address)
+}
+
+// Contains reports whether address is in the watch set (a factory, the
+// payment contract or a known NFT contract), regardless of whether
+// filtering is enabled
+func (cf *ContractFilter) Contains(address common.Address) bool {
 	cf.mu.RLock()
 	defer cf.mu.RUnlock()
 
-	// Check if it's the factory contract
-	if address == cf.factoryAddress {
+	return cf.containsLocked(address)
+}
+
+// containsLocked implements Contains; caller must hold cf.mu
+func (cf *ContractFilter) containsLocked(address common.Address) bool {
+	// Unconfigured factory/payment addresses are the zero address and
+	// must not make 0x0 match
+	if address == (common.Address{}) {
+		return false
+	}
+
+	// Check if it's one of the factory contracts
+	if cf.isFactoryLocked(address) {
 		return true
 	}
 
@@ -120,55 +519,278 @@ func (cf *ContractFilter) ShouldIndexLog(address common.Address) bool {
 	}
 
 	// Check if it's a known NFT contract
-	if cf.nftContracts[address] {
+	return cf.nftContracts[address]
+}
+
+// ShouldIndexEvent determines if a log with the given event signature (topic0)
+// should be indexed, applying any per-address topic allowlist, or in allowlist
+// mode the configured allowlist of the contract's role, on top of the address
+// check done by ShouldIndexLog
+func (cf *ContractFilter) ShouldIndexEvent(address common.Address, topic0 common.Hash) bool {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	if !cf.enabled {
+		return true
+	}
+	if !cf.containsLocked(address) {
+		return false
+	}
+
+	// A per-address allowlist takes precedence over the role allowlists
+	if allowed := cf.allowedTopics[address]; len(allowed) > 0 {
+		return allowed[topic0]
+	}
+
+	if cf.filterMode != FilterModeAllowlist {
 		return true
 	}
 
+	allowed := cf.roleTopics[cf.roleLocked(address)]
+	return len(allowed) == 0 || allowed[topic0]
+}
+
+// roleLocked returns the contract role of a watched address; caller must hold cf.mu
+func (cf *ContractFilter) roleLocked(address common.Address) string {
+	switch {
+	case cf.isFactoryLocked(address):
+		return roleFactory
+	case address == cf.paymentAddress:
+		return rolePayment
+	default:
+		return roleNFT
+	}
+}
+
+// SetAllowedTopics restricts which event signatures are indexed for an address
+// An empty list removes the restriction so all events are indexed
+func (cf *ContractFilter) SetAllowedTopics(address common.Address, topics []common.Hash) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if len(topics) == 0 {
+		delete(cf.allowedTopics, address)
+		return
+	}
+
+	allowed := make(map[common.Hash]bool, len(topics))
+	for _, topic := range topics {
+		allowed[topic] = true
+	}
+	cf.allowedTopics[address] = allowed
+}
+
+// AddFactoryAddress adds another factory contract to the watch list
+func (cf *ContractFilter) AddFactoryAddress(address common.Address) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if cf.addFactoryLocked(address) {
+		cf.addedFactories = append(cf.addedFactories, address)
+		cf.logger.Info("Added factory contract", "address", address.Hex(), "total", len(cf.factoryAddresses))
+	}
+}
+
+// SetFactoryAddresses replaces the watched factory contracts, e.g. after a
+// new factory deployment; the first address becomes the primary factory
+// Replaced factories stop being watched unless they are listed again, and
+// the replacement is kept over the config factories by ReloadConfig
+func (cf *ContractFilter) SetFactoryAddresses(addresses []common.Address) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	// Keep the primary slot filled, as loading a config without a factory does
+	cf.factoryAddresses = []common.Address{{}}
+	if len(addresses) > 0 {
+		cf.factoryAddresses = []common.Address{addresses[0]}
+		for _, addr := range addresses[1:] {
+			cf.addFactoryLocked(addr)
+		}
+	}
+	cf.factoryOverride = append([]common.Address(nil), cf.factoryAddresses...)
+	cf.addedFactories = nil
+
+	cf.logger.Info("Replaced factory contracts", "primary", cf.factoryAddresses[0].Hex(), "total", len(cf.factoryAddresses))
+}
+
+// GetFactoryAddresses returns all factory addresses being watched
+func (cf *ContractFilter) GetFactoryAddresses() []common.Address {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	factories := make([]common.Address, len(cf.factoryAddresses))
+	copy(factories, cf.factoryAddresses)
+	return factories
+}
+
+// GetPaymentAddress returns the payment contract address
+func (cf *ContractFilter) GetPaymentAddress() common.Address {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	return cf.paymentAddress
+}
+
+// isFactoryLocked reports whether address is a factory; caller must hold cf.mu
+func (cf *ContractFilter) isFactoryLocked(address common.Address) bool {
+	for _, factory := range cf.factoryAddresses {
+		if address == factory {
+			return true
+		}
+	}
 	return false
 }
 
+// addFactoryLocked appends a factory if not yet present; caller must hold cf.mu
+func (cf *ContractFilter) addFactoryLocked(address common.Address) bool {
+	if cf.isFactoryLocked(address) {
+		return false
+	}
+	cf.factoryAddresses = append(cf.factoryAddresses, address)
+	return true
+}
+
 // AddNFTContract adds a new NFT contract to the watch list
-func (cf *ContractFilter) AddNFTContract(address common.Address) {
+// Returns true if the contract was not being watched before and was not
+// rejected by the watch set cap
+func (cf *ContractFilter) AddNFTContract(address common.Address) bool {
 	cf.mu.Lock()
 	defer cf.mu.Unlock()
 
-	if !cf.nftContracts[address] {
-		cf.nftContracts[address] = true
-		fmt.Printf("[ContractFilter] Added NFT contract: %s (total: %d)\n", address.Hex(), len(cf.nftContracts))
+	if !cf.addNFTContractLocked(address) {
+		return false
 	}
+
+	cf.logger.Info("Added NFT contract", "address", address.Hex(), "total", len(cf.nftContracts))
+	return true
 }
 
-// AddNFTContracts adds multiple NFT contracts
+// AddDiscoveredNFTContract adds an NFT contract discovered on chain
+// Unlike AddNFTContract, MongoDB syncs keep the contract for the discovery
+// grace period even when the backend has not stored it yet
+func (cf *ContractFilter) AddDiscoveredNFTContract(address common.Address) bool {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if !cf.addNFTContractLocked(address) {
+		return false
+	}
+	cf.discoveredAt[address] = time.Now()
+
+	cf.logger.Info("Added discovered NFT contract", "address", address.Hex(), "total", len(cf.nftContracts))
+	return true
+}
+
+// AddNFTContracts adds multiple NFT contracts, subject to the watch set cap
 func (cf *ContractFilter) AddNFTContracts(addresses []common.Address) {
 	cf.mu.Lock()
 	defer cf.mu.Unlock()
 
 	newCount := 0
 	for _, addr := range addresses {
-		if !cf.nftContracts[addr] {
-			cf.nftContracts[addr] = true
+		if cf.addNFTContractLocked(addr) {
 			newCount++
 		}
 	}
 
 	if newCount > 0 {
-		fmt.Printf("[ContractFilter] Added %d new NFT contracts (total: %d)\n", newCount, len(cf.nftContracts))
+		cf.logger.Info("Added new NFT contracts", "added", newCount, "total", len(cf.nftContracts))
+	}
+}
+
+// RemoveNFTContract removes an NFT contract from the watch list
+// Returns true if the contract was being watched
+func (cf *ContractFilter) RemoveNFTContract(address common.Address) bool {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if !cf.nftContracts[address] {
+		return false
+	}
+
+	cf.deleteNFTContractLocked(address)
+	cf.logger.Info("Removed NFT contract", "address", address.Hex(), "total", len(cf.nftContracts))
+	return true
+}
+
+// RemoveNFTContracts removes multiple NFT contracts
+// Returns the number of contracts that were actually removed
+func (cf *ContractFilter) RemoveNFTContracts(addresses []common.Address) int {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	removedCount := 0
+	for _, addr := range addresses {
+		if cf.nftContracts[addr] {
+			cf.deleteNFTContractLocked(addr)
+			removedCount++
+		}
+	}
+
+	if removedCount > 0 {
+		cf.logger.Info("Removed NFT contracts", "removed", removedCount, "total", len(cf.nftContracts))
+	}
+
+	return removedCount
+}
+
+// deleteNFTContractLocked drops address and its bookkeeping from the watch
+// set; the caller must hold cf.mu for writing
+func (cf *ContractFilter) deleteNFTContractLocked(address common.Address) {
+	delete(cf.nftContracts, address)
+	delete(cf.lastActivity, address)
+	delete(cf.discoveredAt, address)
+	if entry, ok := cf.activityEntries[address]; ok {
+		if entry.index >= 0 {
+			heap.Remove(&cf.activityOrder, entry.index)
+		}
+		delete(cf.activityEntries, address)
 	}
 }
 
 // GetWatchedAddresses returns all addresses being watched
+// Factory and payment addresses come first, followed by the NFT contracts
+// sorted by address, so the result is stable across calls
 func (cf *ContractFilter) GetWatchedAddresses() []common.Address {
 	cf.mu.RLock()
 	defer cf.mu.RUnlock()
 
-	addresses := make([]common.Address, 0, len(cf.nftContracts)+2)
-	addresses = append(addresses, cf.factoryAddress)
-	addresses = append(addresses, cf.paymentAddress)
+	addresses := make([]common.Address, 0, len(cf.nftContracts)+len(cf.factoryAddresses)+1)
+	for _, factory := range cf.factoryAddresses {
+		if factory != (common.Address{}) {
+			addresses = append(addresses, factory)
+		}
+	}
+	if cf.paymentAddress != (common.Address{}) {
+		addresses = append(addresses, cf.paymentAddress)
+	}
+
+	return append(addresses, cf.sortedNFTContractsLocked()...)
+}
+
+// GetNFTContractAddresses returns only the discovered NFT contracts, without
+// the factory and payment addresses, sorted by address
+func (cf *ContractFilter) GetNFTContractAddresses() []common.Address {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	return cf.sortedNFTContractsLocked()
+}
 
+// sortedNFTContractsLocked returns the NFT contracts that are not also core
+// addresses, sorted by address; caller must hold cf.mu
+func (cf *ContractFilter) sortedNFTContractsLocked() []common.Address {
+	addresses := make([]common.Address, 0, len(cf.nftContracts))
 	for addr := range cf.nftContracts {
+		if cf.isFactoryLocked(addr) || addr == cf.paymentAddress {
+			continue
+		}
 		addresses = append(addresses, addr)
 	}
 
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i].Bytes(), addresses[j].Bytes()) < 0
+	})
 	return addresses
 }
 
@@ -179,75 +801,369 @@ func (cf *ContractFilter) GetAddressFilter() []string {
 	filter := make([]string, len(addresses))
 
 	for i, addr := range addresses {
-		filter[i] = strings.ToLower(addr.Hex())
+		filter[i] = strings.TrimPrefix(strings.ToLower(addr.Hex()), "0x")
 	}
 
 	return filter
 }
 
 // Stats returns statistics about the filter
+// Kept as a map for compatibility; use FilterStats for a typed result
 func (cf *ContractFilter) Stats() map[string]interface{} {
+	stats := cf.FilterStats()
+
+	return map[string]interface{}{
+		"enabled":             stats.Enabled,
+		"factory_address":     stats.FactoryAddress,
+		"factory_addresses":   stats.FactoryAddresses,
+		"payment_address":     stats.PaymentAddress,
+		"nft_contracts_count": stats.NFTContractsCount,
+		"total_watched":       stats.TotalWatched,
+		"auto_discovery":      stats.AutoDiscovery,
+		"mongodb_sync":        stats.MongoDBSync,
+		"max_contracts":       stats.MaxContracts,
+		"rejected_contracts":  stats.RejectedContracts,
+		"evicted_contracts":   stats.EvictedContracts,
+	}
+}
+
+// FilterStats is a typed snapshot of the filter state
+type FilterStats struct {
+	Enabled           bool     `json:"enabled"`
+	FactoryAddress    string   `json:"factory_address"`
+	FactoryAddresses  []string `json:"factory_addresses"`
+	PaymentAddress    string   `json:"payment_address"`
+	NFTContractsCount int      `json:"nft_contracts_count"`
+	TotalWatched      int      `json:"total_watched"`
+	AutoDiscovery     bool     `json:"auto_discovery"`
+	MongoDBSync       bool     `json:"mongodb_sync"`
+	MaxContracts      int      `json:"max_contracts"` // 0 when unbounded
+	RejectedContracts uint64   `json:"rejected_contracts"`
+	EvictedContracts  uint64   `json:"evicted_contracts"`
+}
+
+// FilterStats returns typed statistics about the filter
+func (cf *ContractFilter) FilterStats() FilterStats {
 	cf.mu.RLock()
 	defer cf.mu.RUnlock()
 
-	return map[string]interface{}{
-		"enabled":             cf.enabled,
-		"factory_address":     cf.factoryAddress.Hex(),
-		"payment_address":     cf.paymentAddress.Hex(),
-		"nft_contracts_count": len(cf.nftContracts),
-		"total_watched":       len(cf.nftContracts) + 2,
-		"auto_discovery":      cf.autoDiscovery,
-		"mongodb_sync":        cf.mongodbSyncEnabled,
+	factories := make([]string, len(cf.factoryAddresses))
+	for i, addr := range cf.factoryAddresses {
+		factories[i] = addr.Hex()
 	}
+
+	return FilterStats{
+		Enabled:           cf.enabled,
+		FactoryAddress:    cf.factoryAddresses[0].Hex(),
+		FactoryAddresses:  factories,
+		PaymentAddress:    cf.paymentAddress.Hex(),
+		NFTContractsCount: len(cf.nftContracts),
+		TotalWatched:      cf.watchedCountLocked(),
+		AutoDiscovery:     cf.autoDiscovery,
+		MongoDBSync:       cf.mongodbSyncEnabled,
+		MaxContracts:      cf.maxContracts,
+		RejectedContracts: cf.rejectedContracts,
+		EvictedContracts:  cf.evictedContracts,
+	}
+}
+
+// watchedCountLocked returns the number of distinct addresses Contains
+// matches, leaving out unconfigured (zero) factory and payment addresses;
+// caller must hold cf.mu
+func (cf *ContractFilter) watchedCountLocked() int {
+	watched := make(map[common.Address]bool, len(cf.nftContracts)+len(cf.factoryAddresses)+1)
+	for addr := range cf.nftContracts {
+		watched[addr] = true
+	}
+	for _, factory := range cf.factoryAddresses {
+		watched[factory] = true
+	}
+	watched[cf.paymentAddress] = true
+	delete(watched, common.Address{})
+
+	return len(watched)
 }
 
 // StartMongoDBSync starts the MongoDB sync goroutine
-func (cf *ContractFilter) StartMongoDBSync(ctx context.Context, mongoClient MongoDBClient) {
-	if !cf.mongodbSyncEnabled {
-		fmt.Println("[ContractFilter] MongoDB sync is disabled")
-		return
+// The returned channel is closed once the goroutine has exited after the
+// context is cancelled; it is closed immediately when sync is disabled
+func (cf *ContractFilter) StartMongoDBSync(ctx context.Context, mongoClient MongoDBClient) <-chan struct{} {
+	done := make(chan struct{})
+
+	if !cf.syncEnabled() {
+		cf.logger.Info("MongoDB sync is disabled")
+		close(done)
+		return done
 	}
 
-	fmt.Printf("[ContractFilter] Starting MongoDB sync (interval: %s)\n", cf.mongodbSyncInterval)
+	go func() {
+		defer close(done)
+		cf.runMongoDBSync(ctx, mongoClient)
+	}()
+
+	return done
+}
+
+// MongoDB sync timing
+const (
+	defaultSyncInterval = 5 * time.Minute  // used when the configured interval is not positive
+	maxSyncBackoff      = 30 * time.Minute // cap for the delay after repeated failures
+)
 
-	ticker := time.NewTicker(cf.mongodbSyncInterval)
-	defer ticker.Stop()
+// runMongoDBSync runs the MongoDB sync loop until the context is cancelled
+func (cf *ContractFilter) runMongoDBSync(ctx context.Context, mongoClient MongoDBClient) {
+	cf.logger.Info("Starting MongoDB sync", "interval", cf.syncInterval())
 
-	// Initial sync
-	if err := cf.syncFromMongoDB(ctx, mongoClient); err != nil {
-		fmt.Printf("[ContractFilter] Initial MongoDB sync error: %v\n", err)
+	// Initial sync, unless the filter was just warmed
+	if cf.takeWarmed() {
+		cf.logger.Info("Skipping initial MongoDB sync, filter already warmed")
+	} else if err := cf.recordSync(ctx, mongoClient); err != nil {
+		cf.logger.Error("Initial MongoDB sync failed", "error", err)
 	}
 
+	timer := time.NewTimer(cf.nextSyncDelay())
+	defer timer.Stop()
+
 	// Periodic sync
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("[ContractFilter] MongoDB sync stopped")
+			cf.logger.Info("MongoDB sync stopped")
 			return
-		case <-ticker.C:
-			if err := cf.syncFromMongoDB(ctx, mongoClient); err != nil {
-				fmt.Printf("[ContractFilter] MongoDB sync error: %v\n", err)
+		case <-timer.C:
+			if err := cf.recordSync(ctx, mongoClient); err != nil {
+				cf.logger.Error("MongoDB sync failed", "error", err)
 			}
+
+			// Re-read the interval so config reloads take effect
+			delay := cf.nextSyncDelay()
+			if failures := cf.ConsecutiveSyncFailures(); failures > 0 {
+				cf.logger.Warn("Consecutive MongoDB sync failures", "failures", failures, "nextAttempt", delay)
+			}
+			timer.Reset(delay)
 		}
 	}
 }
 
-// syncFromMongoDB fetches NFT contract addresses from MongoDB
+// WarmFromMongoDB loads the active NFT contracts from MongoDB, blocking until
+// the load completes, so logs are not fetched against an empty watch set
+// It is meant to be called before the fetch loop starts; a sync loop started
+// after a successful warm-up skips its own initial sync
+func (cf *ContractFilter) WarmFromMongoDB(ctx context.Context, mongoClient MongoDBClient) error {
+	if err := cf.recordSync(ctx, mongoClient); err != nil {
+		return fmt.Errorf("failed to warm contract filter: %w", err)
+	}
+
+	cf.mu.Lock()
+	cf.warmed = true
+	count := len(cf.nftContracts)
+	cf.mu.Unlock()
+
+	cf.logger.Info("Warmed contract filter from MongoDB", "nftContracts", count)
+	return nil
+}
+
+// takeWarmed reports whether the filter was warmed since the last sync loop
+// started, clearing the flag
+func (cf *ContractFilter) takeWarmed() bool {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	warmed := cf.warmed
+	cf.warmed = false
+	return warmed
+}
+
+// recordSync runs a single sync and records its outcome for LastSyncTime,
+// LastSyncError and the consecutive failure count
+func (cf *ContractFilter) recordSync(ctx context.Context, mongoClient MongoDBClient) error {
+	err := cf.syncFromMongoDB(ctx, mongoClient)
+	cf.recordSyncResult(err)
+	return err
+}
+
+// recordSyncResult records the outcome of a sync attempt
+func (cf *ContractFilter) recordSyncResult(err error) {
+	cf.mu.Lock()
+	cf.lastSyncTime = time.Now()
+	cf.lastSyncErr = err
+	if err != nil {
+		cf.syncFailures++
+	} else {
+		cf.syncFailures = 0
+	}
+	cf.mu.Unlock()
+}
+
+// nextSyncDelay returns the sync interval, doubled for every consecutive
+// failure up to maxSyncBackoff (or the interval itself if that is larger)
+func (cf *ContractFilter) nextSyncDelay() time.Duration {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	limit := maxSyncBackoff
+	if cf.mongodbSyncInterval > limit {
+		limit = cf.mongodbSyncInterval
+	}
+
+	delay := cf.mongodbSyncInterval
+	if delay <= 0 {
+		delay = defaultSyncInterval
+	}
+	for i := 0; i < cf.syncFailures && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+
+	return delay
+}
+
+// ConsecutiveSyncFailures returns how many MongoDB syncs in a row have failed
+func (cf *ContractFilter) ConsecutiveSyncFailures() int {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.syncFailures
+}
+
+// LastSyncTime returns when the last MongoDB sync attempt finished
+func (cf *ContractFilter) LastSyncTime() time.Time {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.lastSyncTime
+}
+
+// LastSyncError returns the error of the last MongoDB sync attempt, if any
+func (cf *ContractFilter) LastSyncError() error {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.lastSyncErr
+}
+
+// syncEnabled reports whether MongoDB sync is enabled in the config
+func (cf *ContractFilter) syncEnabled() bool {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.mongodbSyncEnabled
+}
+
+// syncInterval returns the configured MongoDB sync interval
+func (cf *ContractFilter) syncInterval() time.Duration {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.mongodbSyncInterval
+}
+
+// syncFromMongoDB fetches the active NFT contract addresses from MongoDB and
+// reconciles the watch set against them, so contracts that were deactivated
+// or deleted stop being watched
 func (cf *ContractFilter) syncFromMongoDB(ctx context.Context, mongoClient MongoDBClient) error {
-	addresses, err := mongoClient.GetNFTContractAddresses(ctx)
+	addresses, err := mongoClient.GetActiveNFTContractAddresses(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch NFT contracts from MongoDB: %w", err)
 	}
 
-	if len(addresses) > 0 {
-		cf.AddNFTContracts(addresses)
-		fmt.Printf("[ContractFilter] Synced %d NFT contracts from MongoDB\n", len(addresses))
+	cf.applySync(addresses)
+	return nil
+}
+
+// emptySyncConfirmations is how many successful syncs in a row must return no
+// contracts before the synced NFT contracts are removed, so a single bad read
+// (e.g. of the wrong database after a misconfiguration) cannot wipe the watch set
+const emptySyncConfirmations = 2
+
+// applySync reconciles the watch set against the addresses of a successful sync
+// An empty result is only applied once confirmed by the next sync, see
+// emptySyncConfirmations
+func (cf *ContractFilter) applySync(addresses []common.Address) {
+	if emptySyncs := cf.countEmptySync(len(addresses) == 0); emptySyncs > 0 && emptySyncs < emptySyncConfirmations {
+		cf.logger.Warn("MongoDB returned no active NFT contracts, keeping the watch set until the next sync confirms it",
+			"emptySyncs", emptySyncs)
+		return
 	}
 
-	return nil
+	added, removed := cf.reconcileNFTContracts(addresses)
+	if len(addresses) == 0 && removed > 0 {
+		cf.logger.Warn("MongoDB returned no active NFT contracts again, removed all synced contracts", "removed", removed)
+		return
+	}
+
+	cf.logger.Info("Synced NFT contracts from MongoDB", "count", len(addresses), "added", added, "removed", removed)
+}
+
+// countEmptySync records whether a successful sync returned no contracts and
+// returns how many did so in a row
+func (cf *ContractFilter) countEmptySync(empty bool) int {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if !empty {
+		cf.emptySyncs = 0
+		return 0
+	}
+	cf.emptySyncs++
+	return cf.emptySyncs
+}
+
+// reconcileNFTContracts makes the NFT contract set match the given addresses,
+// adding missing ones and removing stale ones under a single lock
+// The factory and payment addresses and the NFT contracts listed in the
+// config are always retained, as are contracts discovered on chain within
+// the grace period, which the backend may not have stored yet
+func (cf *ContractFilter) reconcileNFTContracts(addresses []common.Address) (added, removed int) {
+	authoritative := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		authoritative[addr] = true
+	}
+
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	now := time.Now()
+	// Remove first so stale contracts free up room under the watch set cap
+	for addr := range cf.nftContracts {
+		if authoritative[addr] || cf.isCoreLocked(addr) || cf.configuredContracts[addr] || cf.pendingDiscoveryLocked(addr, now) {
+			continue
+		}
+		cf.deleteNFTContractLocked(addr)
+		removed++
+	}
+
+	for addr := range cf.evicted {
+		if !authoritative[addr] {
+			delete(cf.evicted, addr)
+		}
+	}
+
+	for addr := range authoritative {
+		// Discovered contracts that reached MongoDB are synced like any other
+		delete(cf.discoveredAt, addr)
+		// Evicted contracts only come back once there is room, otherwise
+		// every sync would evict another contract to re-add them
+		if cf.evicted[addr] && !cf.hasRoomLocked() {
+			continue
+		}
+		if cf.addNFTContractLocked(addr) {
+			added++
+		}
+	}
+
+	return added, removed
+}
+
+// pendingDiscoveryLocked reports whether address was discovered on chain
+// less than the grace period before now; caller must hold cf.mu
+func (cf *ContractFilter) pendingDiscoveryLocked(address common.Address, now time.Time) bool {
+	discovered, ok := cf.discoveredAt[address]
+	return ok && now.Sub(discovered) < cf.discoveryGrace
 }
 
 // MongoDBClient interface for fetching contract addresses
 type MongoDBClient interface {
+	// GetNFTContractAddresses returns all non-deleted contracts
 	GetNFTContractAddresses(ctx context.Context) ([]common.Address, error)
+	// GetActiveNFTContractAddresses returns the authoritative set of active
+	// contracts the sync reconciles the watch set against
+	GetActiveNFTContractAddresses(ctx context.Context) ([]common.Address, error)
 }
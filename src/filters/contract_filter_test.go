@@ -0,0 +1,667 @@
+package filters_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// filterConfig enables filtering with the factory at 0x...f1 and the payment
+// contract at 0x...e1
+const filterConfig = `{
+	"network": "base-sepolia",
+	"chainId": 84532,
+	"contracts": {
+		"factory": {"address": "0x00000000000000000000000000000000000000f1"},
+		"payment": {"address": "0x00000000000000000000000000000000000000e1"}
+	},
+	"eventFilters": {"enabled": true}
+}`
+
+var (
+	factoryAddr = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+	paymentAddr = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+)
+
+// nftAddresses returns n distinct NFT contract addresses
+func nftAddresses(n int) []common.Address {
+	addresses := make([]common.Address, n)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(0xa000 + i)))
+	}
+	return addresses
+}
+
+// writeConfig writes a contract config to a temporary file and returns its path
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "contracts.json")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newFilter creates a filter from a config, discarding its log output
+func newFilter(t *testing.T, config string, opts ...filters.ContractFilterOption) *filters.ContractFilter {
+	t.Helper()
+
+	opts = append([]filters.ContractFilterOption{filters.WithFilterLogger(discardLogger())}, opts...)
+	cf, err := filters.NewContractFilter(writeConfig(t, config), opts...)
+	if err != nil {
+		t.Fatalf("NewContractFilter: %v", err)
+	}
+	return cf
+}
+
+func TestGetAddressFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory string
+		payment string
+		nft     string
+	}{
+		{
+			name:    "prefixed",
+			factory: "0x00000000000000000000000000000000000000F1",
+			payment: "0x00000000000000000000000000000000000000E1",
+			nft:     "0xAbCdEf0000000000000000000000000000000001",
+		},
+		{
+			name:    "unprefixed",
+			factory: "00000000000000000000000000000000000000F1",
+			payment: "00000000000000000000000000000000000000e1",
+			nft:     "ABCDEF0000000000000000000000000000000001",
+		},
+	}
+
+	want := []string{
+		"00000000000000000000000000000000000000f1",
+		"00000000000000000000000000000000000000e1",
+		"abcdef0000000000000000000000000000000001",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, `{
+				"contracts": {
+					"factory": {"address": "`+tt.factory+`"},
+					"payment": {"address": "`+tt.payment+`"},
+					"nftContracts": ["`+tt.nft+`"]
+				},
+				"eventFilters": {"enabled": true}
+			}`)
+
+			if got := cf.GetAddressFilter(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetAddressFilter() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRemoveNFTContracts(t *testing.T) {
+	addresses := nftAddresses(3)
+
+	tests := []struct {
+		name        string
+		remove      func(cf *filters.ContractFilter) int
+		wantRemoved int
+		wantLeft    []common.Address
+	}{
+		{
+			name: "single",
+			remove: func(cf *filters.ContractFilter) int {
+				if cf.RemoveNFTContract(addresses[0]) {
+					return 1
+				}
+				return 0
+			},
+			wantRemoved: 1,
+			wantLeft:    addresses[1:],
+		},
+		{
+			name: "batch with duplicates and unknown addresses",
+			remove: func(cf *filters.ContractFilter) int {
+				return cf.RemoveNFTContracts([]common.Address{addresses[0], addresses[0], factoryAddr})
+			},
+			wantRemoved: 1,
+			wantLeft:    addresses[1:],
+		},
+		{
+			name:        "all",
+			remove:      func(cf *filters.ContractFilter) int { return cf.RemoveNFTContracts(addresses) },
+			wantRemoved: 3,
+			wantLeft:    []common.Address{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, filterConfig)
+			cf.AddNFTContracts(addresses)
+
+			if got := tt.remove(cf); got != tt.wantRemoved {
+				t.Fatalf("removed %d contracts, want %d", got, tt.wantRemoved)
+			}
+			// Removing again is a no-op
+			if got := tt.remove(cf); got != 0 {
+				t.Fatalf("second removal removed %d contracts, want 0", got)
+			}
+
+			if got := cf.GetNFTContractAddresses(); !reflect.DeepEqual(got, tt.wantLeft) {
+				t.Errorf("NFT contracts = %v, want %v", got, tt.wantLeft)
+			}
+			if !cf.Contains(factoryAddr) || !cf.Contains(paymentAddr) {
+				t.Errorf("core addresses removed")
+			}
+		})
+	}
+}
+
+func TestConcurrentAddRemoveNFTContracts(t *testing.T) {
+	cf := newFilter(t, filterConfig)
+	addresses := nftAddresses(50)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				addr := addresses[i%len(addresses)]
+				cf.AddNFTContract(addr)
+				cf.ShouldIndexLog(addr)
+				cf.RemoveNFTContract(addr)
+				cf.AddNFTContracts(addresses[:5])
+				cf.RemoveNFTContracts(addresses[:5])
+				cf.GetWatchedAddresses()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The listing and membership checks must agree once the workers are done
+	watched := 0
+	for _, addr := range addresses {
+		if cf.Contains(addr) {
+			watched++
+		}
+	}
+	if got := cf.GetNFTContractAddresses(); len(got) != watched {
+		t.Errorf("listed %d NFT contracts, Contains matches %d", len(got), watched)
+	}
+	if removed := cf.RemoveNFTContracts(addresses); removed != watched {
+		t.Errorf("removed %d NFT contracts, want %d", removed, watched)
+	}
+}
+
+func TestShouldIndexEventAllowedTopics(t *testing.T) {
+	restricted := common.HexToAddress("0x000000000000000000000000000000000000a001")
+	open := common.HexToAddress("0x000000000000000000000000000000000000a002")
+	unknown := common.HexToAddress("0x000000000000000000000000000000000000a003")
+	approval := crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+
+	cf := newFilter(t, filterConfig)
+	cf.AddNFTContracts([]common.Address{restricted, open})
+	cf.SetAllowedTopics(restricted, []common.Hash{filters.TransferSignature})
+
+	tests := []struct {
+		name    string
+		address common.Address
+		topic0  common.Hash
+		want    bool
+	}{
+		{name: "allowed topic", address: restricted, topic0: filters.TransferSignature, want: true},
+		{name: "topic outside the allowlist", address: restricted, topic0: approval, want: false},
+		{name: "contract without an allowlist", address: open, topic0: approval, want: true},
+		{name: "factory events unrestricted", address: factoryAddr, topic0: filters.NFTContractCreatedSignature, want: true},
+		{name: "unwatched contract", address: unknown, topic0: filters.TransferSignature, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cf.ShouldIndexEvent(tt.address, tt.topic0); got != tt.want {
+				t.Errorf("ShouldIndexEvent(%s, %s) = %v, want %v", tt.address.Hex(), tt.topic0.Hex(), got, tt.want)
+			}
+			if got := cf.ShouldIndexLog(tt.address); got != (tt.address != unknown) {
+				t.Errorf("ShouldIndexLog(%s) = %v, the allowlist must not affect it", tt.address.Hex(), got)
+			}
+		})
+	}
+
+	cf.SetAllowedTopics(restricted, nil)
+	if !cf.ShouldIndexEvent(restricted, approval) {
+		t.Errorf("clearing the allowlist did not restore all events")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	const reloaded = `{
+	"network": "base-sepolia",
+	"chainId": 84532,
+	"contracts": {
+		"factory": {"address": "0x00000000000000000000000000000000000000f2"},
+		"payment": {"address": "0x00000000000000000000000000000000000000e2"}
+	},
+	"eventFilters": {"enabled": false},
+	"syncSettings": {
+		"mongodbSync": {"enabled": true, "intervalSeconds": 30},
+		"autoDiscovery": {"enabled": true}
+	}
+}`
+	discovered := nftAddresses(3)
+
+	tests := []struct {
+		name      string
+		config    string
+		wantErr   bool
+		wantStats filters.FilterStats
+	}{
+		{
+			name:   "changed config",
+			config: reloaded,
+			wantStats: filters.FilterStats{
+				Enabled:          false,
+				FactoryAddress:   "0x00000000000000000000000000000000000000F2",
+				PaymentAddress:   "0x00000000000000000000000000000000000000E2",
+				AutoDiscovery:    true,
+				MongoDBSync:      true,
+				FactoryAddresses: []string{"0x00000000000000000000000000000000000000F2"},
+			},
+		},
+		{name: "unparsable config", config: "{not json", wantErr: true},
+		{name: "invalid factory address", config: strings.Replace(reloaded, "0x00000000000000000000000000000000000000f2", "0xnothex", 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, filterConfig)
+			cf, err := filters.NewContractFilter(path, filters.WithFilterLogger(discardLogger()))
+			if err != nil {
+				t.Fatalf("NewContractFilter: %v", err)
+			}
+			cf.AddNFTContracts(discovered)
+			before := cf.FilterStats()
+
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			err = cf.ReloadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReloadConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := tt.wantStats
+			if tt.wantErr {
+				want = before
+			}
+			got := cf.FilterStats()
+			if got.Enabled != want.Enabled || got.FactoryAddress != want.FactoryAddress || got.PaymentAddress != want.PaymentAddress ||
+				got.AutoDiscovery != want.AutoDiscovery || got.MongoDBSync != want.MongoDBSync || !reflect.DeepEqual(got.FactoryAddresses, want.FactoryAddresses) {
+				t.Errorf("FilterStats after reload = %+v, want %+v", got, want)
+			}
+			for _, addr := range discovered {
+				if !cf.Contains(addr) {
+					t.Errorf("discovered contract %s lost by the reload", addr.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestNewContractFilterStrict(t *testing.T) {
+	dup := "0x000000000000000000000000000000000000a001"
+
+	tests := []struct {
+		name         string
+		config       string
+		wantProblems []string
+	}{
+		{
+			name:   "valid config",
+			config: filterConfig,
+		},
+		{
+			name: "every problem is reported",
+			config: `{
+				"network": "base-sepolia",
+				"chainId": 84532,
+				"contracts": {
+					"factory": {"address": ""},
+					"payment": {"address": "0xnothex"},
+					"nftContracts": ["0x12", "` + dup + `", "` + dup + `"]
+				}
+			}`,
+			wantProblems: []string{
+				"factory address is required",
+				`invalid payment address "0xnothex"`,
+				`invalid NFT contract address "0x12"`,
+				`duplicate NFT contract address "` + dup + `"`,
+			},
+		},
+		{
+			name: "missing core addresses",
+			config: `{
+				"network": "base-sepolia",
+				"chainId": 84532,
+				"contracts": {"nftContracts": ["` + dup + `", "` + dup + `"]}
+			}`,
+			wantProblems: []string{
+				"factory address is required",
+				"payment address is required",
+				`duplicate NFT contract address "` + dup + `"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.config)
+
+			_, err := filters.NewContractFilterStrict(path, filters.WithFilterLogger(discardLogger()))
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Fatalf("NewContractFilterStrict: %v", err)
+				}
+			} else {
+				var configErr *filters.ConfigError
+				if !errors.As(err, &configErr) {
+					t.Fatalf("NewContractFilterStrict error = %v, want *ConfigError", err)
+				}
+				if !reflect.DeepEqual(configErr.Problems, tt.wantProblems) {
+					t.Errorf("problems = %q, want %q", configErr.Problems, tt.wantProblems)
+				}
+				if !errors.Is(err, errdefs.ErrInvalidConfig) {
+					t.Errorf("error %v is not ErrInvalidConfig", err)
+				}
+			}
+
+			// The lenient constructor keeps accepting the same configs
+			if _, err := filters.NewContractFilter(path, filters.WithFilterLogger(discardLogger())); err != nil {
+				t.Errorf("NewContractFilter: %v", err)
+			}
+		})
+	}
+}
+
+func TestShouldIndexLogIgnoresZeroAddress(t *testing.T) {
+	nft := nftAddresses(1)[0]
+	other := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+
+	tests := []struct {
+		name        string
+		contracts   string
+		want        map[common.Address]bool
+		wantWatched []common.Address
+	}{
+		{
+			name:        "payment unconfigured",
+			contracts:   `{"factory": {"address": "0x00000000000000000000000000000000000000f1"}}`,
+			want:        map[common.Address]bool{{}: false, factoryAddr: true, nft: true, other: false},
+			wantWatched: []common.Address{factoryAddr, nft},
+		},
+		{
+			name:        "factory and payment unconfigured",
+			contracts:   `{}`,
+			want:        map[common.Address]bool{{}: false, factoryAddr: false, nft: true, other: false},
+			wantWatched: []common.Address{nft},
+		},
+		{
+			name:        "empty payment address",
+			contracts:   `{"factory": {"address": "0x00000000000000000000000000000000000000f1"}, "payment": {"address": ""}}`,
+			want:        map[common.Address]bool{{}: false, factoryAddr: true, nft: true, other: false},
+			wantWatched: []common.Address{factoryAddr, nft},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, `{
+				"network": "base-sepolia",
+				"chainId": 84532,
+				"contracts": `+tt.contracts+`,
+				"eventFilters": {"enabled": true}
+			}`)
+			cf.AddNFTContract(nft)
+
+			for addr, want := range tt.want {
+				if got := cf.ShouldIndexLog(addr); got != want {
+					t.Errorf("ShouldIndexLog(%s) = %v, want %v", addr.Hex(), got, want)
+				}
+			}
+			if got := cf.GetWatchedAddresses(); !reflect.DeepEqual(got, tt.wantWatched) {
+				t.Errorf("GetWatchedAddresses = %v, want %v", got, tt.wantWatched)
+			}
+			if got := cf.FilterStats().TotalWatched; got != len(tt.wantWatched) {
+				t.Errorf("TotalWatched = %d, want %d", got, len(tt.wantWatched))
+			}
+		})
+	}
+}
+
+func TestGetNFTContractAddresses(t *testing.T) {
+	nfts := nftAddresses(4)
+
+	tests := []struct {
+		name  string
+		added []common.Address
+		want  []common.Address
+	}{
+		{name: "none discovered"},
+		{name: "sorted", added: []common.Address{nfts[3], nfts[0], nfts[2], nfts[1]}, want: nfts},
+		{name: "core addresses excluded", added: []common.Address{nfts[1], factoryAddr, paymentAddr, nfts[0]}, want: nfts[:2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, filterConfig)
+			for _, addr := range tt.added {
+				cf.AddNFTContract(addr)
+			}
+
+			got := cf.GetNFTContractAddresses()
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("GetNFTContractAddresses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWatchedAddressesDeterministic(t *testing.T) {
+	nfts := nftAddresses(32)
+	cf := newFilter(t, filterConfig)
+	// Add in reverse so insertion order differs from the sorted order
+	for i := len(nfts) - 1; i >= 0; i-- {
+		cf.AddNFTContract(nfts[i])
+	}
+
+	want := append([]common.Address{factoryAddr, paymentAddr}, nfts...)
+	wantFilter := cf.GetAddressFilter()
+
+	for i := 0; i < 20; i++ {
+		if got := cf.GetWatchedAddresses(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("call %d: GetWatchedAddresses = %v, want %v", i, got, want)
+		}
+		if got := cf.GetAddressFilter(); !reflect.DeepEqual(got, wantFilter) {
+			t.Fatalf("call %d: GetAddressFilter = %v, want %v", i, got, wantFilter)
+		}
+	}
+}
+
+func TestExportWatchedCSV(t *testing.T) {
+	nfts := nftAddresses(3)
+	secondFactory := common.HexToAddress("0x00000000000000000000000000000000000000f0")
+
+	tests := []struct {
+		name   string
+		config string
+		setup  func(cf *filters.ContractFilter)
+		want   string
+	}{
+		{
+			name:   "core contracts only",
+			config: filterConfig,
+			want: "address,role\n" +
+				factoryAddr.Hex() + ",factory\n" +
+				paymentAddr.Hex() + ",payment\n",
+		},
+		{
+			name:   "sorted within each role",
+			config: filterConfig,
+			setup: func(cf *filters.ContractFilter) {
+				cf.AddFactoryAddress(secondFactory)
+				cf.AddNFTContract(nfts[2])
+				cf.AddNFTContract(nfts[0])
+				cf.AddNFTContract(nfts[1])
+			},
+			want: "address,role\n" +
+				secondFactory.Hex() + ",factory\n" +
+				factoryAddr.Hex() + ",factory\n" +
+				paymentAddr.Hex() + ",payment\n" +
+				nfts[0].Hex() + ",nft\n" +
+				nfts[1].Hex() + ",nft\n" +
+				nfts[2].Hex() + ",nft\n",
+		},
+		{
+			name:   "unconfigured payment contract is left out",
+			config: `{"contracts": {"factory": {"address": "0x00000000000000000000000000000000000000f1"}}}`,
+			setup:  func(cf *filters.ContractFilter) { cf.AddNFTContract(nfts[0]) },
+			want: "address,role\n" +
+				factoryAddr.Hex() + ",factory\n" +
+				nfts[0].Hex() + ",nft\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, tt.config)
+			if tt.setup != nil {
+				tt.setup(cf)
+			}
+
+			var buf strings.Builder
+			if err := cf.ExportWatchedCSV(&buf); err != nil {
+				t.Fatalf("ExportWatchedCSV: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("ExportWatchedCSV =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsIgnoresEnabledFlag(t *testing.T) {
+	nft := nftAddresses(1)[0]
+	other := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+
+	tests := []struct {
+		name            string
+		enabled         bool
+		addr            common.Address
+		wantContains    bool
+		wantShouldIndex bool
+	}{
+		{name: "enabled, watched", enabled: true, addr: nft, wantContains: true, wantShouldIndex: true},
+		{name: "enabled, not watched", enabled: true, addr: other},
+		{name: "enabled, factory", enabled: true, addr: factoryAddr, wantContains: true, wantShouldIndex: true},
+		{name: "disabled, watched", addr: nft, wantContains: true, wantShouldIndex: true},
+		{name: "disabled, not watched", addr: other, wantShouldIndex: true},
+		{name: "disabled, payment", addr: paymentAddr, wantContains: true, wantShouldIndex: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, strings.Replace(filterConfig, `"enabled": true`, `"enabled": `+strconv.FormatBool(tt.enabled), 1))
+			cf.AddNFTContract(nft)
+
+			if got := cf.Contains(tt.addr); got != tt.wantContains {
+				t.Errorf("Contains(%s) = %v, want %v", tt.addr.Hex(), got, tt.wantContains)
+			}
+			if got := cf.ShouldIndexLog(tt.addr); got != tt.wantShouldIndex {
+				t.Errorf("ShouldIndexLog(%s) = %v, want %v", tt.addr.Hex(), got, tt.wantShouldIndex)
+			}
+		})
+	}
+}
+
+func TestFilteringDisabledSafeguard(t *testing.T) {
+	const warning = "EVENT FILTERING IS DISABLED"
+	withContracts := `{
+	"network": "base-sepolia",
+	"chainId": 84532,
+	"contracts": {"factory": {"address": "0x00000000000000000000000000000000000000f1"}},
+	"eventFilters": {"enabled": %t}
+}`
+	withoutContracts := `{"network": "base-sepolia", "chainId": 84532, "eventFilters": {"enabled": %t}}`
+	unwatched := common.HexToAddress("0x0000000000000000000000000000000000007001")
+
+	tests := []struct {
+		name        string
+		config      string
+		enabled     bool
+		require     bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "filtering enabled", config: withContracts, enabled: true},
+		{name: "filtering enabled and required", config: withContracts, enabled: true, require: true},
+		{name: "disabled with contracts", config: withContracts, wantWarning: true},
+		{name: "disabled without contracts", config: withoutContracts},
+		{name: "disabled with contracts and required", config: withContracts, require: true, wantErr: true},
+		{name: "disabled without contracts and required", config: withoutContracts, require: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			config := writeConfig(t, fmt.Sprintf(tt.config, tt.enabled))
+			cf, err := filters.NewContractFilter(config,
+				filters.WithFilterLogger(logging.New(&out, "test", slog.LevelDebug)),
+				filters.WithRequireFilter(tt.require))
+			if tt.wantErr {
+				if !errors.Is(err, errdefs.ErrInvalidConfig) {
+					t.Fatalf("NewContractFilter error = %v, want %v", err, errdefs.ErrInvalidConfig)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewContractFilter: %v", err)
+			}
+			if got := strings.Contains(out.String(), warning); got != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v; log:\n%s", got, tt.wantWarning, out.String())
+			}
+			// An accepted disabled filter indexes every log
+			if got := cf.ShouldIndexLog(unwatched); got != !tt.enabled {
+				t.Errorf("ShouldIndexLog(unwatched) = %v, want %v", got, !tt.enabled)
+			}
+		})
+	}
+
+	t.Run("reload disabling a required filter", func(t *testing.T) {
+		cf := newFilter(t, fmt.Sprintf(withContracts, true), filters.WithRequireFilter(true))
+
+		err := cf.ReloadConfig(writeConfig(t, fmt.Sprintf(withContracts, false)))
+		if !errors.Is(err, errdefs.ErrInvalidConfig) {
+			t.Fatalf("ReloadConfig error = %v, want %v", err, errdefs.ErrInvalidConfig)
+		}
+		if cf.ShouldIndexLog(unwatched) {
+			t.Errorf("rejected reload disabled filtering")
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package filters
+
+import (
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+)
+
+// Errors wrapped when a log does not match the declared layout of its event,
+// e.g. because the emitting contract declares the event differently
+// Both also match errdefs.ErrInvalidEvent
+var (
+	ErrUnexpectedTopicCount error = &eventError{msg: "unexpected topic count"}
+	ErrInvalidEventData     error = &eventError{msg: "invalid event data"}
+)
+
+// eventError is a specific errdefs.ErrInvalidEvent
+type eventError struct {
+	msg string
+}
+
+func (e *eventError) Error() string {
+	return e.msg
+}
+
+// Is makes every eventError match errdefs.ErrInvalidEvent
+func (e *eventError) Is(target error) bool {
+	return target == errdefs.ErrInvalidEvent
+}
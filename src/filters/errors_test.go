@@ -0,0 +1,85 @@
+package filters_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestErrorCategories(t *testing.T) {
+	badConfig := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(badConfig, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	creationTopics := []common.Hash{filters.NFTContractCreatedSignature, {}}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{
+			name: "unparsable config",
+			run: func() error {
+				_, err := filters.NewContractFilter(badConfig)
+				return err
+			},
+			want: errdefs.ErrInvalidConfig,
+		},
+		{
+			name: "duplicate chain",
+			run: func() error {
+				_, err := filters.NewMultiChainFilter(newChainFilter(t, chainA), newChainFilter(t, chainA))
+				return err
+			},
+			want: errdefs.ErrInvalidConfig,
+		},
+		{
+			name: "other event",
+			run: func() error {
+				_, err := filters.ParseNFTContractCreatedEvent(types.Log{Topics: []common.Hash{filters.TransferSignature}})
+				return err
+			},
+			want: errdefs.ErrInvalidEvent,
+		},
+		{
+			name: "unexpected topic count",
+			run: func() error {
+				_, err := filters.ParseNFTContractCreatedEvent(types.Log{Topics: creationTopics})
+				return err
+			},
+			want: filters.ErrUnexpectedTopicCount,
+		},
+		{
+			name: "unexpected topic count is an invalid event",
+			run: func() error {
+				_, err := filters.ParseNFTContractCreatedEvent(types.Log{Topics: creationTopics})
+				return err
+			},
+			want: errdefs.ErrInvalidEvent,
+		},
+		{
+			name: "not a transfer",
+			run: func() error {
+				_, err := filters.ParseTransferEvent(types.Log{})
+				return err
+			},
+			want: errdefs.ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v does not match %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,9 +2,17 @@ package filters
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -12,66 +20,425 @@ import (
 
 // EventListener listens for Factory events and auto-discovers new NFT contracts
 type EventListener struct {
-	contractFilter *ContractFilter
-	factoryAddress common.Address
+	contractFilter   *ContractFilter
+	factoryMu        sync.RWMutex // guards factoryAddresses
+	factoryAddresses map[common.Address]bool
+	hooksMu          sync.RWMutex // guards creationSigs, parsers, the hooks and backfillChunk
+	creationSigs     map[common.Hash]bool
+	onDiscover       func(event *NFTContractCreatedEvent)
+	parsers          map[common.Hash]EventParser
+	onEvent          func(log types.Log, event interface{})
+	backfillChunk    uint64
+	onBackfill       func(progress BackfillProgress)
+	logger           logging.Logger
 }
 
 // Event signatures
 var (
-	// NFTContractCreated(uint256 indexed collectionId, address indexed contractAddress, address indexed creator, string name, string symbol, string baseURI)
+	// NFTContractCreated(uint256 collectionId, address indexed contractAddress, address indexed creator, string name, string symbol, string baseURI)
 	NFTContractCreatedSignature = crypto.Keccak256Hash([]byte("NFTContractCreated(uint256,address,address,string,string,string)"))
 )
 
+// mustParseABI parses a static ABI definition, panicking on invalid input
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI definition: %v", err))
+	}
+	return parsed
+}
+
+// EventListenerOption configures optional EventListener behaviour
+type EventListenerOption func(*EventListener)
+
+// WithListenerLogger sets the logger of an EventListener
+func WithListenerLogger(logger logging.Logger) EventListenerOption {
+	return func(el *EventListener) {
+		el.logger = logger
+	}
+}
+
 // NewEventListener creates a new event listener
-func NewEventListener(contractFilter *ContractFilter, factoryAddress common.Address) *EventListener {
-	return &EventListener{
-		contractFilter: contractFilter,
-		factoryAddress: factoryAddress,
+func NewEventListener(contractFilter *ContractFilter, factoryAddress common.Address, opts ...EventListenerOption) *EventListener {
+	return NewEventListenerMulti(contractFilter, []common.Address{factoryAddress}, opts...)
+}
+
+// NewEventListenerMulti creates an event listener that recognizes events from
+// any of the given factory contracts
+func NewEventListenerMulti(contractFilter *ContractFilter, factoryAddresses []common.Address, opts ...EventListenerOption) *EventListener {
+	factories := make(map[common.Address]bool, len(factoryAddresses))
+	for _, addr := range factoryAddresses {
+		factories[addr] = true
+	}
+
+	el := &EventListener{
+		contractFilter:   contractFilter,
+		factoryAddresses: factories,
+		creationSigs:     map[common.Hash]bool{NFTContractCreatedSignature: true},
+		logger:           logging.Default("EventListener"),
+		parsers: map[common.Hash]EventParser{
+			NFTContractCreatedSignature: parseNFTContractCreated,
+			PaymentReceivedSignature:    parsePaymentReceived,
+			WithdrawalSignature:         parseWithdrawal,
+			TransferSignature:           TransferEventParser,
+		},
+	}
+	for _, opt := range opts {
+		opt(el)
+	}
+
+	return el
+}
+
+// SetOnDiscover sets a hook invoked once for every newly discovered NFT
+// contract, after it has been added to the contract filter; pass nil to
+// remove it. Safe to call while logs are being processed
+func (el *EventListener) SetOnDiscover(fn func(event *NFTContractCreatedEvent)) {
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+	el.onDiscover = fn
+}
+
+// discoverHook returns the hook set with SetOnDiscover
+func (el *EventListener) discoverHook() func(event *NFTContractCreatedEvent) {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+	return el.onDiscover
+}
+
+// isCreationSig reports whether topic is an accepted contract creation signature
+func (el *EventListener) isCreationSig(topic common.Hash) bool {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+	return el.creationSigs[topic]
+}
+
+// isFactory reports whether address is one of the configured factories
+func (el *EventListener) isFactory(address common.Address) bool {
+	el.factoryMu.RLock()
+	defer el.factoryMu.RUnlock()
+	return el.factoryAddresses[address]
+}
+
+// SetFactoryAddress makes address the only factory the listener accepts
+// creation events from, e.g. after a new factory deployment
+func (el *EventListener) SetFactoryAddress(address common.Address) {
+	el.SetFactoryAddresses([]common.Address{address})
+}
+
+// SetFactoryAddresses replaces the factories the listener accepts creation
+// events from, without a restart. The contract filter's factories are
+// replaced as well, so logs of a dropped factory are neither indexed nor
+// treated as creations. Safe to call while logs are being processed
+func (el *EventListener) SetFactoryAddresses(addresses []common.Address) {
+	factories := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		factories[addr] = true
+	}
+
+	el.factoryMu.Lock()
+	el.factoryAddresses = factories
+	el.factoryMu.Unlock()
+
+	el.contractFilter.SetFactoryAddresses(addresses)
+	el.logger.Info("Updated factory contracts", "factories", len(factories))
+}
+
+// AddCreationEventSignature accepts an additional topic0 as a contract
+// creation event, for factories emitting a differently-named event with the
+// same parameters as NFTContractCreated
+// The signature is the Keccak-256 hash of the canonical event string: the
+// name followed by the parameter types without names, spaces or "indexed", e.g.
+//
+//	crypto.Keccak256Hash([]byte("CollectionCreated(uint256,address,address,string,string,string)"))
+//
+// It is safe to call while logs are being processed. Include
+// CreationEventSignatures in RPC topic filters so the additional events are
+// fetched at all
+func (el *EventListener) AddCreationEventSignature(sig common.Hash) {
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+
+	el.creationSigs[sig] = true
+	el.parsers[sig] = func(log types.Log) (interface{}, error) {
+		return el.parseCreationLog(log)
 	}
 }
 
+// CreationEventSignatures returns the accepted contract creation signatures,
+// NFTContractCreated first and the additional ones in ascending order
+func (el *EventListener) CreationEventSignatures() []common.Hash {
+	el.hooksMu.RLock()
+	sigs := make([]common.Hash, 0, len(el.creationSigs))
+	for sig := range el.creationSigs {
+		if sig != NFTContractCreatedSignature {
+			sigs = append(sigs, sig)
+		}
+	}
+	el.hooksMu.RUnlock()
+	sort.Slice(sigs, func(i, j int) bool { return sigs[i].Cmp(sigs[j]) < 0 })
+
+	return append([]common.Hash{NFTContractCreatedSignature}, sigs...)
+}
+
+// isContractCreatedLog reports whether log is a contract creation event
+// emitted by a configured factory
+func (el *EventListener) isContractCreatedLog(log types.Log) bool {
+	return el.isFactory(log.Address) && len(log.Topics) > 0 && el.isCreationSig(log.Topics[0])
+}
+
+// parseCreationLog parses a creation log with any accepted signature, relying
+// on additional signatures sharing the NFTContractCreated layout
+func (el *EventListener) parseCreationLog(log types.Log) (*NFTContractCreatedEvent, error) {
+	if len(log.Topics) > 0 && log.Topics[0] != NFTContractCreatedSignature && el.isCreationSig(log.Topics[0]) {
+		topics := append([]common.Hash(nil), log.Topics...)
+		topics[0] = NFTContractCreatedSignature
+		log.Topics = topics
+	}
+	return ParseNFTContractCreatedEvent(log)
+}
+
 // ProcessLog processes a log entry and extracts NFT contract addresses
-func (el *EventListener) ProcessLog(log types.Log) {
-	// Only process logs from the factory contract
-	if log.Address != el.factoryAddress {
-		return
+// Returns true if the log revealed a contract that was not yet watched
+func (el *EventListener) ProcessLog(log types.Log) bool {
+	return el.applyCreation(log, el.decodeCreation(log))
+}
+
+// creationResult is a log decoded as a contract creation event
+type creationResult struct {
+	creation bool // the log is a creation event emitted by a factory
+	event    *NFTContractCreatedEvent
+	err      error
+}
+
+// decodeCreation parses log if it is a creation event emitted by a factory
+// It only reads listener state, so logs may be decoded concurrently
+func (el *EventListener) decodeCreation(log types.Log) creationResult {
+	if !el.isContractCreatedLog(log) {
+		return creationResult{}
 	}
+	event, err := el.parseCreationLog(log)
+	return creationResult{creation: true, event: event, err: err}
+}
 
-	// Check if it's an NFTContractCreated event
-	if len(log.Topics) == 0 || log.Topics[0] != NFTContractCreatedSignature {
-		return
+// applyCreation applies the discovery of an already decoded log
+// Returns true if the log revealed a contract that was not yet watched
+func (el *EventListener) applyCreation(log types.Log, result creationResult) bool {
+	// Only process creation events (NFTContractCreated or an added
+	// signature) from a factory contract
+	if !result.creation {
+		return false
 	}
 
 	// Extract the contract address from the event
-	// Topic layout:
+	// Topic layout (collectionId is only present on newer factories):
 	// Topic[0] = event signature
-	// Topic[1] = contractAddress (indexed)
-	// Topic[2] = creator (indexed)
-	if len(log.Topics) < 3 {
-		fmt.Printf("[EventListener] Invalid NFTContractCreated event: not enough topics\n")
-		return
+	// Topic[1] = collectionId (indexed, optional)
+	// Topic[n-2] = contractAddress (indexed)
+	// Topic[n-1] = creator (indexed)
+	contractAddress, _, ok := creationAddresses(log)
+	if !ok {
+		el.logger.Warn("Invalid NFTContractCreated event: unexpected topic count", "tx", log.TxHash.Hex(), "topics", len(log.Topics))
+		return false
+	}
+
+	// The creation was reverted by a chain reorganization
+	if log.Removed {
+		if el.contractFilter.RemoveNFTContract(contractAddress) {
+			el.logger.Info("Dropped NFT contract reverted by reorg", "address", contractAddress.Hex())
+		}
+		return false
+	}
+
+	// Add to contract filter; already watched contracts need no further work
+	if !el.contractFilter.AddDiscoveredNFTContract(contractAddress) {
+		return false
 	}
 
-	// Topic[1] contains the contract address (indexed address is 32 bytes with padding)
-	contractAddress := common.BytesToAddress(log.Topics[1].Bytes())
+	// The creation block counts as the first activity of the new contract
+	el.contractFilter.RecordActivity(contractAddress, log.BlockNumber)
+
+	el.logger.Info("Discovered new NFT contract", "address", contractAddress.Hex(), "block", log.BlockNumber)
 
-	// Add to contract filter
-	el.contractFilter.AddNFTContract(contractAddress)
+	if hook := el.discoverHook(); hook != nil {
+		hook(el.discoveredEvent(log, result))
+	}
 
-	fmt.Printf("[EventListener] Discovered new NFT contract: %s\n", contractAddress.Hex())
+	return true
 }
 
-// ProcessLogs processes multiple logs
+// discoveredEvent returns the decoded creation event for the discovery hook,
+// falling back to the indexed fields alone when the data could not be decoded
+func (el *EventListener) discoveredEvent(log types.Log, result creationResult) *NFTContractCreatedEvent {
+	if result.err == nil && result.event != nil {
+		return result.event
+	}
+
+	el.logger.Warn("Failed to decode NFTContractCreated data", "tx", log.TxHash.Hex(), "error", result.err)
+	contractAddress, creator, _ := creationAddresses(log)
+	return &NFTContractCreatedEvent{
+		ContractAddress: contractAddress,
+		Creator:         creator,
+		BlockNumber:     log.BlockNumber,
+		TxHash:          log.TxHash,
+		LogIndex:        log.Index,
+	}
+}
+
+// processDecoded applies the steps shared by every way of processing a batch
+// of logs, once their creation events have been decoded (results holds one
+// entry per log): the activity of the emitting contracts is recorded,
+// discovery is applied in input order and other logs are dispatched to the
+// SetOnEvent hook
+// Returns the number of newly discovered contracts, and the parsed creation
+// events and parse errors of the non-removed creation logs in input order
+func (el *EventListener) processDecoded(logs []types.Log, results []creationResult) (discovered int, events []*NFTContractCreatedEvent, errs []error) {
+	el.contractFilter.RecordLogActivity(logs)
+	remined := el.reminedContracts(logs)
+
+	for i, log := range logs {
+		if el.isRevertedRemined(log, remined) {
+			continue
+		}
+
+		result := results[i]
+		if el.applyCreation(log, result) {
+			discovered++
+		}
+		el.dispatchEvent(log)
+
+		switch {
+		case !result.creation || log.Removed:
+		case result.err != nil:
+			errs = append(errs, fmt.Errorf("log %d of tx %s: %w", log.Index, log.TxHash.Hex(), result.err))
+		default:
+			events = append(events, result.event)
+		}
+	}
+
+	return discovered, events, errs
+}
+
+// decodeAll decodes the creation events of logs on the calling goroutine
+func (el *EventListener) decodeAll(logs []types.Log) []creationResult {
+	results := make([]creationResult, len(logs))
+	for i, log := range logs {
+		results[i] = el.decodeCreation(log)
+	}
+	return results
+}
+
+// ProcessLogs processes multiple logs, dispatching each by topic0:
+// NFTContractCreated logs drive discovery and other logs with a registered
+// parser are delivered to the SetOnEvent hook
+// The activity of the watched contracts emitting the logs is recorded as well
+// Returns the number of newly discovered NFT contracts
 func (el *EventListener) ProcessLogs(logs []types.Log) int {
-	discoveredCount := 0
+	discovered, _, _ := el.processDecoded(logs, el.decodeAll(logs))
+	return discovered
+}
+
+// reminedContracts returns the contracts with a non-removed creation log in
+// the batch, i.e. contracts whose creation survived any reorg in the batch
+func (el *EventListener) reminedContracts(logs []types.Log) map[common.Address]bool {
+	remined := make(map[common.Address]bool)
 	for _, log := range logs {
-		el.ProcessLog(log)
-		// Check if it was an NFTContractCreated event
-		if len(log.Topics) > 0 && log.Topics[0] == NFTContractCreatedSignature {
-			discoveredCount++
+		if log.Removed || !el.isContractCreatedLog(log) {
+			continue
+		}
+		if contractAddress, _, ok := creationAddresses(log); ok {
+			remined[contractAddress] = true
 		}
 	}
-	return discoveredCount
+	return remined
+}
+
+// isRevertedRemined reports whether log is a removed creation log for a
+// contract that was mined again within the same batch, so it must not be dropped
+func (el *EventListener) isRevertedRemined(log types.Log, remined map[common.Address]bool) bool {
+	if !log.Removed || !el.isContractCreatedLog(log) {
+		return false
+	}
+	contractAddress, _, ok := creationAddresses(log)
+	return ok && remined[contractAddress]
+}
+
+// ProcessLogsCollect processes multiple logs like ProcessLogs and returns the
+// fully parsed NFTContractCreated events among them, in input order
+// Logs that fail to parse are reported and skipped
+func (el *EventListener) ProcessLogsCollect(logs []types.Log) []*NFTContractCreatedEvent {
+	_, events, errs := el.processDecoded(logs, el.decodeAll(logs))
+	for _, err := range errs {
+		el.logger.Warn("Failed to parse NFTContractCreated event", "error", err)
+	}
+	return events
+}
+
+// ProcessLogsParallel is ProcessLogsCollect with the creation logs decoded by
+// a pool of workers (NumCPU when workers is not positive), for backfills of
+// many logs. Discovery is still applied in input order on the calling
+// goroutine, so reorg removals and re-additions of a contract resolve exactly
+// as in ProcessLogsCollect
+// The parsed events are returned ordered by block and log index; creation
+// logs that fail to parse are skipped and reported in the returned error
+func (el *EventListener) ProcessLogsParallel(logs []types.Log, workers int) ([]*NFTContractCreatedEvent, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Workers write to distinct indexes and only read listener state
+	results := make([]creationResult, len(logs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = el.decodeCreation(logs[i])
+			}
+		}()
+	}
+	for i, log := range logs {
+		if el.isContractCreatedLog(log) {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	_, events, errs := el.processDecoded(logs, results)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+
+	return events, errors.Join(errs...)
+}
+
+// ProcessReceipt processes the logs of a transaction receipt, skipping logs
+// from addresses not in the contract filter
+// Matching logs drive discovery and are delivered to the SetOnEvent hook like
+// in ProcessLogs, and the parsed NFTContractCreated events are returned in log
+// order. Creation logs that fail to parse are skipped and reported in the
+// returned error alongside the events that did parse
+func (el *EventListener) ProcessReceipt(receipt *types.Receipt) ([]*NFTContractCreatedEvent, error) {
+	if receipt == nil {
+		return nil, fmt.Errorf("nil receipt")
+	}
+
+	logs := make([]types.Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		if log != nil && el.contractFilter.ShouldIndexLog(log.Address) {
+			logs = append(logs, *log)
+		}
+	}
+
+	_, events, errs := el.processDecoded(logs, el.decodeAll(logs))
+	return events, errors.Join(errs...)
 }
 
 // NFTContractCreatedEvent represents the parsed NFTContractCreated event
@@ -87,46 +454,292 @@ type NFTContractCreatedEvent struct {
 	LogIndex        uint
 }
 
-// ParseNFTContractCreatedEvent parses an NFTContractCreated event
+// NFTContractCreated layouts. Both share topic0, since indexing does not
+// change the event signature:
+//   - original: topics are the signature, contractAddress and creator, and
+//     data holds collectionId followed by three dynamic strings
+//   - indexed collectionId (newer factories): topics are the signature,
+//     collectionId, contractAddress and creator, and data holds the strings
+const (
+	nftContractCreatedTopics          = 3
+	nftContractCreatedIndexedIDTopics = 4
+	nftContractCreatedDynamic         = 3
+)
+
+// nftContractCreatedIndexedIDABIJSON is the NFTContractCreated fragment of
+// factories that index collectionId
+const nftContractCreatedIndexedIDABIJSON = `[{
+	"anonymous": false,
+	"name": "NFTContractCreated",
+	"type": "event",
+	"inputs": [
+		{"indexed": true, "internalType": "uint256", "name": "collectionId", "type": "uint256"},
+		{"indexed": true, "internalType": "address", "name": "contractAddress", "type": "address"},
+		{"indexed": true, "internalType": "address", "name": "creator", "type": "address"},
+		{"indexed": false, "internalType": "string", "name": "name", "type": "string"},
+		{"indexed": false, "internalType": "string", "name": "symbol", "type": "string"},
+		{"indexed": false, "internalType": "string", "name": "baseURI", "type": "string"}
+	]
+}]`
+
+// nftContractCreatedIndexedIDABI decodes the data of the indexed collectionId layout
+var nftContractCreatedIndexedIDABI = mustParseABI(nftContractCreatedIndexedIDABIJSON)
+
+// creationAddresses returns the contract and creator addresses of a creation
+// log in either layout; they are always the last two topics
+// ok is false when the topic count matches neither layout
+func creationAddresses(log types.Log) (contractAddress, creator common.Address, ok bool) {
+	n := len(log.Topics)
+	if n != nftContractCreatedTopics && n != nftContractCreatedIndexedIDTopics {
+		return common.Address{}, common.Address{}, false
+	}
+	return common.BytesToAddress(log.Topics[n-2].Bytes()), common.BytesToAddress(log.Topics[n-1].Bytes()), true
+}
+
+// ParseNFTContractCreatedEvent parses an NFTContractCreated event in either
+// the original or the indexed collectionId layout, told apart by topic count
+// A log with another topic count or with data not matching its layout
+// returns an error wrapping ErrUnexpectedTopicCount or ErrInvalidEventData
 func ParseNFTContractCreatedEvent(log types.Log) (*NFTContractCreatedEvent, error) {
-	if len(log.Topics) < 3 {
-		return nil, fmt.Errorf("invalid NFTContractCreated event: not enough topics")
+	if len(log.Topics) == 0 || log.Topics[0] != NFTContractCreatedSignature {
+		return nil, fmt.Errorf("%w: not an NFTContractCreated event", errdefs.ErrInvalidEvent)
 	}
 
-	if log.Topics[0] != NFTContractCreatedSignature {
-		return nil, fmt.Errorf("not an NFTContractCreated event")
+	contractAddress, creator, ok := creationAddresses(log)
+	if !ok {
+		return nil, fmt.Errorf("invalid NFTContractCreated event: %w: expected %d or %d, got %d",
+			ErrUnexpectedTopicCount, nftContractCreatedTopics, nftContractCreatedIndexedIDTopics, len(log.Topics))
+	}
+
+	// collectionId is the only static data value unless it is indexed
+	static := 1
+	if len(log.Topics) == nftContractCreatedIndexedIDTopics {
+		static = 0
+	}
+	if err := validateDynamicData(log.Data, static, nftContractCreatedDynamic); err != nil {
+		return nil, fmt.Errorf("invalid NFTContractCreated event: %w", err)
 	}
 
 	event := &NFTContractCreatedEvent{
-		ContractAddress: common.BytesToAddress(log.Topics[1].Bytes()),
-		Creator:         common.BytesToAddress(log.Topics[2].Bytes()),
+		ContractAddress: contractAddress,
+		Creator:         creator,
 		BlockNumber:     log.BlockNumber,
 		TxHash:          log.TxHash,
 		LogIndex:        log.Index,
 	}
 
-	// Parse the data field (collectionId, name, symbol, baseURI)
-	// The data contains non-indexed parameters
-	// For detailed parsing, we would need to use ABI decoder
-	// For now, we just extract the contract address which is indexed
+	if len(log.Topics) == nftContractCreatedIndexedIDTopics {
+		return decodeIndexedIDCreation(event, log)
+	}
+
+	// Decode the data field (collectionId, name, symbol, baseURI) through
+	// the generated binding so it stays in sync with the factory ABI
+	decoded, err := factoryFilterer.ParseNFTContractCreated(log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode NFTContractCreated data: %w: %w", ErrInvalidEventData, err)
+	}
+
+	event.CollectionID = decoded.CollectionId
+	event.Name = decoded.Name
+	event.Symbol = decoded.Symbol
+	event.BaseURI = decoded.BaseURI
+
+	return event, nil
+}
+
+// decodeIndexedIDCreation completes event from a log in the indexed
+// collectionId layout, taking collectionId from topic 1
+func decodeIndexedIDCreation(event *NFTContractCreatedEvent, log types.Log) (*NFTContractCreatedEvent, error) {
+	event.CollectionID = new(big.Int).SetBytes(log.Topics[1].Bytes())
+
+	values, err := nftContractCreatedIndexedIDABI.Unpack("NFTContractCreated", log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode NFTContractCreated data: %w: %w", ErrInvalidEventData, err)
+	}
+	if len(values) != nftContractCreatedDynamic {
+		return nil, fmt.Errorf("invalid NFTContractCreated data: %w: expected %d values, got %d", ErrInvalidEventData, nftContractCreatedDynamic, len(values))
+	}
+
+	var ok bool
+	if event.Name, ok = values[0].(string); !ok {
+		return nil, fmt.Errorf("invalid NFTContractCreated data: %w: name is %T", ErrInvalidEventData, values[0])
+	}
+	if event.Symbol, ok = values[1].(string); !ok {
+		return nil, fmt.Errorf("invalid NFTContractCreated data: %w: symbol is %T", ErrInvalidEventData, values[1])
+	}
+	if event.BaseURI, ok = values[2].(string); !ok {
+		return nil, fmt.Errorf("invalid NFTContractCreated data: %w: baseURI is %T", ErrInvalidEventData, values[2])
+	}
 
 	return event, nil
 }
 
-// GetEventSignatures returns all event signatures to monitor
+// validateDynamicData checks that ABI-encoded data holds static one-word
+// parameters followed by dynamic parameters (e.g. strings), all in order:
+// every offset must point inside the data past the head, and every length
+// must fit in the remaining data
+func validateDynamicData(data []byte, static, dynamic int) error {
+	const word = 32
+
+	head := (static + dynamic) * word
+	minLen := head + dynamic*word
+	if len(data)%word != 0 {
+		return fmt.Errorf("%w: length %d is not a multiple of %d", ErrInvalidEventData, len(data), word)
+	}
+	if len(data) < minLen {
+		return fmt.Errorf("%w: length %d is below the minimum %d for %d dynamic values", ErrInvalidEventData, len(data), minLen, dynamic)
+	}
+
+	size := uint64(len(data))
+	for i := 0; i < dynamic; i++ {
+		slot := (static + i) * word
+		offset, ok := wordToUint64(data[slot : slot+word])
+		if !ok || offset < uint64(head) || offset%word != 0 || offset > size-word {
+			return fmt.Errorf("%w: value %d has offset out of bounds", ErrInvalidEventData, i)
+		}
+
+		length, ok := wordToUint64(data[offset : offset+word])
+		padded := (length + word - 1) / word * word
+		if !ok || length > size || padded > size-offset-word {
+			return fmt.Errorf("%w: value %d has length %d past the end of the data", ErrInvalidEventData, i, length)
+		}
+	}
+
+	return nil
+}
+
+// wordToUint64 converts a 32-byte big-endian word, reporting false if it
+// does not fit in a uint64
+func wordToUint64(word []byte) (uint64, bool) {
+	value := new(big.Int).SetBytes(word)
+	if !value.IsUint64() {
+		return 0, false
+	}
+	return value.Uint64(), true
+}
+
+// GetEventSignatures returns all event signatures to monitor, i.e. the topics
+// of the events NewEventListener parses by default
 func GetEventSignatures() []common.Hash {
 	return []common.Hash{
 		NFTContractCreatedSignature,
+		PaymentReceivedSignature,
+		WithdrawalSignature,
+		TransferSignature,
 	}
 }
 
 // BackfillNFTContracts backfills NFT contracts from historical events
 func (el *EventListener) BackfillNFTContracts(ctx context.Context, logs []types.Log) error {
-	fmt.Printf("[EventListener] Starting backfill of NFT contracts from %d logs\n", len(logs))
+	el.logger.Info("Starting backfill of NFT contracts", "logs", len(logs))
 
 	discoveredCount := el.ProcessLogs(logs)
 
-	fmt.Printf("[EventListener] Backfill complete: discovered %d NFT contracts\n", discoveredCount)
+	el.logger.Info("Backfill complete", "discovered", discoveredCount)
 
 	return nil
 }
+
+// defaultBackfillChunkSize is the number of blocks fetched per request by
+// BackfillFromRPC unless overridden with SetBackfillChunkSize
+const defaultBackfillChunkSize = 5000
+
+// LogFetcher fetches logs for a block range restricted to the given topics
+// It is satisfied by utils.DopamintRPCClient
+type LogFetcher interface {
+	GetFilteredLogsWithTopics(ctx context.Context, fromBlock, toBlock *big.Int, topics [][]common.Hash) ([]types.Log, error)
+}
+
+// BackfillProgress reports how far a BackfillFromRPC run has got
+type BackfillProgress struct {
+	FromBlock  uint64
+	ToBlock    uint64
+	LastBlock  uint64 // last block fetched and processed so far
+	Discovered int    // contracts discovered so far
+}
+
+// SetBackfillChunkSize sets the number of blocks BackfillFromRPC fetches per request
+func (el *EventListener) SetBackfillChunkSize(blocks uint64) {
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+	el.backfillChunk = blocks
+}
+
+// backfillChunkSize returns the number of blocks fetched per backfill request
+func (el *EventListener) backfillChunkSize() uint64 {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+
+	if el.backfillChunk == 0 {
+		return defaultBackfillChunkSize
+	}
+	return el.backfillChunk
+}
+
+// SetOnBackfillProgress sets a hook invoked by BackfillFromRPC after every
+// processed chunk; pass nil to remove it
+func (el *EventListener) SetOnBackfillProgress(fn func(progress BackfillProgress)) {
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+	el.onBackfill = fn
+}
+
+// backfillHook returns the hook set with SetOnBackfillProgress
+func (el *EventListener) backfillHook() func(progress BackfillProgress) {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+	return el.onBackfill
+}
+
+// BackfillFromRPC fetches NFTContractCreated logs for a block range in chunks
+// and processes them, discovering the NFT contracts created in that range
+// Returns the number of newly discovered contracts; on error the contracts
+// discovered in the chunks before the failure are kept and counted
+func (el *EventListener) BackfillFromRPC(ctx context.Context, fetcher LogFetcher, fromBlock, toBlock uint64) (int, error) {
+	if fromBlock > toBlock {
+		return 0, fmt.Errorf("invalid block range: %d > %d", fromBlock, toBlock)
+	}
+
+	chunkSize := el.backfillChunkSize()
+
+	el.logger.Info("Starting backfill of NFT contracts", "fromBlock", fromBlock, "toBlock", toBlock)
+
+	topics := [][]common.Hash{el.CreationEventSignatures()}
+	discoveredCount := 0
+
+	for start := fromBlock; start <= toBlock; {
+		if err := ctx.Err(); err != nil {
+			return discoveredCount, fmt.Errorf("backfill cancelled at block %d: %w", start, err)
+		}
+
+		end := toBlock
+		if toBlock-start >= chunkSize {
+			end = start + chunkSize - 1
+		}
+
+		logs, err := fetcher.GetFilteredLogsWithTopics(ctx, new(big.Int).SetUint64(start), new(big.Int).SetUint64(end), topics)
+		if err != nil {
+			return discoveredCount, fmt.Errorf("failed to fetch logs for blocks %d-%d: %w", start, end, err)
+		}
+
+		discoveredCount += el.ProcessLogs(logs)
+
+		if hook := el.backfillHook(); hook != nil {
+			hook(BackfillProgress{
+				FromBlock:  fromBlock,
+				ToBlock:    toBlock,
+				LastBlock:  end,
+				Discovered: discoveredCount,
+			})
+		}
+
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+
+	el.logger.Info("Backfill complete", "discovered", discoveredCount)
+
+	return discoveredCount, nil
+}
@@ -0,0 +1,865 @@
+package filters_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// factory is the factory address of syncConfig
+var factory = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+
+// creationLog builds an NFTContractCreated log in the indexed collectionId layout
+func creationLog(t *testing.T, block uint64, index uint, contract common.Address) types.Log {
+	t.Helper()
+
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := abi.Arguments{{Type: stringType}, {Type: stringType}, {Type: stringType}}.Pack("Collection", "COL", "ipfs://base/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return types.Log{
+		Address: factory,
+		Topics: []common.Hash{
+			filters.NFTContractCreatedSignature,
+			common.BigToHash(big.NewInt(int64(block))),
+			common.BytesToHash(contract.Bytes()),
+			common.BytesToHash(common.HexToAddress("0x000000000000000000000000000000000000c0de").Bytes()),
+		},
+		Data:        data,
+		BlockNumber: block,
+		Index:       index,
+		TxHash:      crypto.Keccak256Hash(big.NewInt(int64(block)).Bytes()),
+	}
+}
+
+// transferLog builds an ERC-721 Transfer log of contract
+func transferLog(contract common.Address, block uint64, index uint) types.Log {
+	return types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			filters.TransferSignature,
+			{},
+			common.BytesToHash(common.HexToAddress("0x0000000000000000000000000000000000000b0b").Bytes()),
+			common.BigToHash(big.NewInt(1)),
+		},
+		BlockNumber: block,
+		Index:       index,
+	}
+}
+
+// batchLogs returns creation logs for count contracts in descending block
+// order, each followed by a transfer of the new contract
+func batchLogs(t *testing.T, count int) []types.Log {
+	t.Helper()
+
+	var logs []types.Log
+	for i := count; i > 0; i-- {
+		contract := common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		logs = append(logs, creationLog(t, uint64(100+i), 0, contract), transferLog(contract, uint64(100+i), 1))
+	}
+	return logs
+}
+
+// newListener creates a listener over a fresh filter, counting dispatched events
+func newListener(t *testing.T) (*filters.ContractFilter, *filters.EventListener, *atomic.Int64) {
+	t.Helper()
+
+	cf := newSyncFilter(t)
+	el := filters.NewEventListener(cf, factory, filters.WithListenerLogger(discardLogger()))
+	dispatched := new(atomic.Int64)
+	el.SetOnEvent(func(log types.Log, event interface{}) { dispatched.Add(1) })
+	return cf, el, dispatched
+}
+
+func TestProcessLogsParallelMatchesSerial(t *testing.T) {
+	logs := batchLogs(t, 50)
+
+	serialFilter, serial, serialDispatched := newListener(t)
+	want := serial.ProcessLogsCollect(logs)
+	sort.SliceStable(want, func(i, j int) bool {
+		if want[i].BlockNumber != want[j].BlockNumber {
+			return want[i].BlockNumber < want[j].BlockNumber
+		}
+		return want[i].LogIndex < want[j].LogIndex
+	})
+
+	for _, workers := range []int{1, 4, 0} {
+		cf, el, dispatched := newListener(t)
+		got, err := el.ProcessLogsParallel(logs, workers)
+		if err != nil {
+			t.Fatalf("workers=%d: ProcessLogsParallel: %v", workers, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("workers=%d: parallel events differ from serial ones", workers)
+		}
+		if !reflect.DeepEqual(cf.GetNFTContractAddresses(), serialFilter.GetNFTContractAddresses()) {
+			t.Fatalf("workers=%d: watched contracts differ from serial run", workers)
+		}
+		if dispatched.Load() != serialDispatched.Load() || dispatched.Load() != 50 {
+			t.Fatalf("workers=%d: dispatched %d events, serial %d, want 50", workers, dispatched.Load(), serialDispatched.Load())
+		}
+		for _, addr := range cf.GetNFTContractAddresses() {
+			if _, ok := cf.LastActivity(addr); !ok {
+				t.Fatalf("workers=%d: no activity recorded for %s", workers, addr.Hex())
+			}
+		}
+	}
+}
+
+func TestEventListenerConcurrentConfiguration(t *testing.T) {
+	_, el, _ := newListener(t)
+	logs := batchLogs(t, 20)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			el.SetOnDiscover(func(event *filters.NFTContractCreatedEvent) {})
+			el.SetOnEvent(func(log types.Log, event interface{}) {})
+			el.RegisterEvent("Approval(address,address,uint256)", filters.TransferEventParser)
+			el.AddCreationEventSignature(crypto.Keccak256Hash(big.NewInt(int64(i)).Bytes()))
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		if _, err := el.ProcessLogsParallel(logs, 4); err != nil {
+			t.Fatalf("ProcessLogsParallel: %v", err)
+		}
+		el.ProcessLogs(logs)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestParseNFTContractCreatedEvent(t *testing.T) {
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringArgs := abi.Arguments{{Type: stringType}, {Type: stringType}, {Type: stringType}}
+	withID := append(abi.Arguments{{Type: uint256Type}}, stringArgs...)
+
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	creator := common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	collectionID := big.NewInt(42)
+
+	originalData, err := withID.Pack(collectionID, "Collection", "COL", "ipfs://base/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexedData, err := stringArgs.Pack("Collection", "COL", "ipfs://base/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalTopics := []common.Hash{
+		filters.NFTContractCreatedSignature,
+		common.BytesToHash(contract.Bytes()),
+		common.BytesToHash(creator.Bytes()),
+	}
+	indexedTopics := []common.Hash{
+		filters.NFTContractCreatedSignature,
+		common.BigToHash(collectionID),
+		common.BytesToHash(contract.Bytes()),
+		common.BytesToHash(creator.Bytes()),
+	}
+
+	// corrupt returns a copy of data with the word at slot replaced by value
+	corrupt := func(data []byte, slot int, value int64) []byte {
+		out := append([]byte(nil), data...)
+		copy(out[slot*32:(slot+1)*32], common.BigToHash(big.NewInt(value)).Bytes())
+		return out
+	}
+
+	want := &filters.NFTContractCreatedEvent{
+		CollectionID:    collectionID,
+		ContractAddress: contract,
+		Creator:         creator,
+		Name:            "Collection",
+		Symbol:          "COL",
+		BaseURI:         "ipfs://base/",
+		BlockNumber:     7,
+		LogIndex:        3,
+	}
+
+	tests := []struct {
+		name    string
+		topics  []common.Hash
+		data    []byte
+		wantErr error
+	}{
+		{name: "original layout", topics: originalTopics, data: originalData},
+		{name: "indexed collectionId layout", topics: indexedTopics, data: indexedData},
+		{name: "truncated data", topics: originalTopics, data: originalData[:len(originalData)-32], wantErr: filters.ErrInvalidEventData},
+		{name: "unaligned data", topics: indexedTopics, data: indexedData[:len(indexedData)-1], wantErr: filters.ErrInvalidEventData},
+		{name: "offset into the head", topics: originalTopics, data: corrupt(originalData, 1, 32), wantErr: filters.ErrInvalidEventData},
+		{name: "offset past the end", topics: indexedTopics, data: corrupt(indexedData, 0, int64(len(indexedData))), wantErr: filters.ErrInvalidEventData},
+		{name: "length past the end", topics: indexedTopics, data: corrupt(indexedData, 3, 1<<20), wantErr: filters.ErrInvalidEventData},
+		{name: "layout mismatch", topics: indexedTopics, data: originalData, wantErr: filters.ErrInvalidEventData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := filters.ParseNFTContractCreatedEvent(types.Log{
+				Topics:      tt.topics,
+				Data:        tt.data,
+				BlockNumber: want.BlockNumber,
+				Index:       want.LogIndex,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNFTContractCreatedEvent: %v", err)
+			}
+			if !reflect.DeepEqual(event, want) {
+				t.Fatalf("event = %+v, want %+v", event, want)
+			}
+		})
+	}
+}
+
+func TestEventListenerMultipleFactories(t *testing.T) {
+	second := common.HexToAddress("0x00000000000000000000000000000000000000f2")
+	unknown := common.HexToAddress("0x00000000000000000000000000000000000000f3")
+
+	tests := []struct {
+		name         string
+		emitter      common.Address
+		wantDiscover bool
+	}{
+		{name: "primary factory", emitter: factory, wantDiscover: true},
+		{name: "secondary factory", emitter: second, wantDiscover: true},
+		{name: "unrecognized factory", emitter: unknown, wantDiscover: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newSyncFilter(t)
+			cf.AddFactoryAddress(second)
+			el := filters.NewEventListenerMulti(cf, []common.Address{factory, second}, filters.WithListenerLogger(discardLogger()))
+
+			contract := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+			log := creationLog(t, 100, 0, contract)
+			log.Address = tt.emitter
+
+			if got := cf.ShouldIndexLog(tt.emitter); got != tt.wantDiscover {
+				t.Fatalf("ShouldIndexLog(emitter) = %v, want %v", got, tt.wantDiscover)
+			}
+			if got := el.ProcessLog(log); got != tt.wantDiscover {
+				t.Fatalf("ProcessLog = %v, want %v", got, tt.wantDiscover)
+			}
+			if got := cf.Contains(contract); got != tt.wantDiscover {
+				t.Fatalf("Contains(contract) = %v, want %v", got, tt.wantDiscover)
+			}
+
+			watched := make(map[common.Address]bool)
+			for _, addr := range cf.GetWatchedAddresses() {
+				watched[addr] = true
+			}
+			if !watched[factory] || !watched[second] || watched[unknown] {
+				t.Fatalf("GetWatchedAddresses = %v, want both factories and not %s", cf.GetWatchedAddresses(), unknown.Hex())
+			}
+		})
+	}
+}
+
+func TestProcessLogsCollect(t *testing.T) {
+	first := common.HexToAddress("0x0000000000000000000000000000000000001001")
+	second := common.HexToAddress("0x0000000000000000000000000000000000001002")
+	stranger := common.HexToAddress("0x0000000000000000000000000000000000001003")
+
+	foreign := creationLog(t, 101, 1, stranger)
+	foreign.Address = common.HexToAddress("0x00000000000000000000000000000000000000f9")
+	malformed := creationLog(t, 102, 0, common.HexToAddress("0x0000000000000000000000000000000000001004"))
+	malformed.Data = malformed.Data[:len(malformed.Data)-32]
+
+	tests := []struct {
+		name string
+		logs []types.Log
+		want []common.Address
+	}{
+		{name: "no logs"},
+		{name: "non-matching logs only", logs: []types.Log{transferLog(first, 100, 0), foreign}},
+		{
+			name: "mixed logs",
+			logs: []types.Log{
+				creationLog(t, 100, 0, first),
+				transferLog(first, 100, 1),
+				foreign,
+				malformed,
+				creationLog(t, 103, 0, second),
+			},
+			want: []common.Address{first, second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, el, _ := newListener(t)
+
+			var got []common.Address
+			for _, event := range el.ProcessLogsCollect(tt.logs) {
+				if event.Name != "Collection" || event.Symbol != "COL" {
+					t.Errorf("event for %s not fully parsed: %+v", event.ContractAddress.Hex(), event)
+				}
+				got = append(got, event.ContractAddress)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProcessLogsCollect returned %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventListenerDiscoverHook(t *testing.T) {
+	fresh := common.HexToAddress("0x0000000000000000000000000000000000002001")
+	known := common.HexToAddress("0x0000000000000000000000000000000000002002")
+
+	tests := []struct {
+		name    string
+		logs    []types.Log
+		noHook  bool
+		want    []common.Address
+		wantNew int
+	}{
+		{name: "new contract", logs: []types.Log{creationLog(t, 100, 0, fresh)}, want: []common.Address{fresh}, wantNew: 1},
+		{name: "already watched contract", logs: []types.Log{creationLog(t, 100, 0, known)}},
+		{
+			name:    "duplicate creation logs",
+			logs:    []types.Log{creationLog(t, 100, 0, fresh), creationLog(t, 100, 0, fresh), creationLog(t, 101, 3, fresh)},
+			want:    []common.Address{fresh},
+			wantNew: 1,
+		},
+		{name: "no hook set", logs: []types.Log{creationLog(t, 100, 0, fresh)}, noHook: true, wantNew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			cf.AddNFTContract(known)
+
+			var got []common.Address
+			if !tt.noHook {
+				el.SetOnDiscover(func(event *filters.NFTContractCreatedEvent) {
+					got = append(got, event.ContractAddress)
+				})
+			}
+
+			if n := el.ProcessLogs(tt.logs); n != tt.wantNew {
+				t.Errorf("ProcessLogs discovered %d contracts, want %d", n, tt.wantNew)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hook fired for %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessLogReportsKnownContractsOnce(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000003001")
+
+	var out bytes.Buffer
+	cf := newSyncFilter(t)
+	el := filters.NewEventListener(cf, factory, filters.WithListenerLogger(logging.New(&out, "test", slog.LevelDebug)))
+
+	results := []bool{
+		el.ProcessLog(creationLog(t, 100, 0, contract)),
+		el.ProcessLog(creationLog(t, 100, 0, contract)),
+		el.ProcessLog(creationLog(t, 105, 2, contract)),
+	}
+	if !reflect.DeepEqual(results, []bool{true, false, false}) {
+		t.Errorf("ProcessLog results = %v, want only the first to discover", results)
+	}
+	if n := strings.Count(out.String(), "Discovered new NFT contract"); n != 1 {
+		t.Errorf("logged %d discoveries, want 1", n)
+	}
+
+	if cf.AddNFTContract(contract) {
+		t.Errorf("AddNFTContract of a watched contract returned true")
+	}
+	other := common.HexToAddress("0x0000000000000000000000000000000000003002")
+	if !cf.AddNFTContract(other) || cf.AddNFTContract(other) {
+		t.Errorf("AddNFTContract should report only the first add of a new contract")
+	}
+}
+
+func TestProcessLogsHandlesReorgedCreations(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000004001")
+
+	removed := func(log types.Log) types.Log {
+		log.Removed = true
+		return log
+	}
+
+	tests := []struct {
+		name         string
+		watched      bool // contract watched before the batch
+		logs         []types.Log
+		wantWatched  bool
+		wantDiscover int
+	}{
+		{
+			name:        "reverted creation is dropped",
+			watched:     true,
+			logs:        []types.Log{removed(creationLog(t, 100, 0, contract))},
+			wantWatched: false,
+		},
+		{
+			name:        "reverted creation re-mined later in the batch is kept",
+			watched:     true,
+			logs:        []types.Log{removed(creationLog(t, 100, 0, contract)), creationLog(t, 101, 0, contract)},
+			wantWatched: true,
+		},
+		{
+			name:         "discovered and reverted in the same batch is kept for the re-mined log",
+			logs:         []types.Log{creationLog(t, 100, 0, contract), removed(creationLog(t, 100, 0, contract)), creationLog(t, 102, 4, contract)},
+			wantWatched:  true,
+			wantDiscover: 1,
+		},
+		{
+			name:        "reverted creation of an unknown contract",
+			logs:        []types.Log{removed(creationLog(t, 100, 0, contract))},
+			wantWatched: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			if tt.watched {
+				cf.AddNFTContract(contract)
+			}
+
+			if n := el.ProcessLogs(tt.logs); n != tt.wantDiscover {
+				t.Errorf("ProcessLogs discovered %d contracts, want %d", n, tt.wantDiscover)
+			}
+			if got := cf.Contains(contract); got != tt.wantWatched {
+				t.Errorf("Contains = %v, want %v", got, tt.wantWatched)
+			}
+		})
+	}
+}
+
+// fakeLogFetcher serves logs by block range, recording the requested ranges
+type fakeLogFetcher struct {
+	mu       sync.Mutex
+	logs     []types.Log
+	failFrom uint64 // fail requests starting at or after this block when non-zero
+	ranges   [][2]uint64
+}
+
+func (f *fakeLogFetcher) GetFilteredLogsWithTopics(ctx context.Context, fromBlock, toBlock *big.Int, topics [][]common.Hash) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	from, to := fromBlock.Uint64(), toBlock.Uint64()
+	f.ranges = append(f.ranges, [2]uint64{from, to})
+	if f.failFrom != 0 && from >= f.failFrom {
+		return nil, errors.New("rpc unavailable")
+	}
+
+	var logs []types.Log
+	for _, log := range f.logs {
+		if log.BlockNumber < from || log.BlockNumber > to {
+			continue
+		}
+		for _, topic := range topics[0] {
+			if log.Topics[0] == topic {
+				logs = append(logs, log)
+				break
+			}
+		}
+	}
+	return logs, nil
+}
+
+func TestBackfillFromRPC(t *testing.T) {
+	contracts := nftAddresses(4)
+	logs := []types.Log{
+		creationLog(t, 105, 0, contracts[0]),
+		transferLog(contracts[0], 106, 1),
+		creationLog(t, 130, 0, contracts[1]),
+		creationLog(t, 131, 0, contracts[2]),
+		creationLog(t, 175, 0, contracts[3]),
+	}
+
+	tests := []struct {
+		name           string
+		chunkSize      uint64
+		failFrom       uint64
+		wantRanges     [][2]uint64
+		wantDiscovered int
+		wantProgress   []filters.BackfillProgress
+		wantErr        bool
+	}{
+		{
+			name:           "multiple chunks",
+			chunkSize:      25,
+			wantRanges:     [][2]uint64{{100, 124}, {125, 149}, {150, 174}, {175, 180}},
+			wantDiscovered: 4,
+			wantProgress: []filters.BackfillProgress{
+				{FromBlock: 100, ToBlock: 180, LastBlock: 124, Discovered: 1},
+				{FromBlock: 100, ToBlock: 180, LastBlock: 149, Discovered: 3},
+				{FromBlock: 100, ToBlock: 180, LastBlock: 174, Discovered: 3},
+				{FromBlock: 100, ToBlock: 180, LastBlock: 180, Discovered: 4},
+			},
+		},
+		{
+			name:           "single chunk by default",
+			wantRanges:     [][2]uint64{{100, 180}},
+			wantDiscovered: 4,
+			wantProgress:   []filters.BackfillProgress{{FromBlock: 100, ToBlock: 180, LastBlock: 180, Discovered: 4}},
+		},
+		{
+			name:           "failure keeps earlier chunks",
+			chunkSize:      50,
+			failFrom:       150,
+			wantRanges:     [][2]uint64{{100, 149}, {150, 180}},
+			wantDiscovered: 3,
+			wantProgress:   []filters.BackfillProgress{{FromBlock: 100, ToBlock: 180, LastBlock: 149, Discovered: 3}},
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			el.SetBackfillChunkSize(tt.chunkSize)
+			var progress []filters.BackfillProgress
+			el.SetOnBackfillProgress(func(p filters.BackfillProgress) { progress = append(progress, p) })
+			fetcher := &fakeLogFetcher{logs: logs, failFrom: tt.failFrom}
+
+			discovered, err := el.BackfillFromRPC(context.Background(), fetcher, 100, 180)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BackfillFromRPC error = %v, want error %v", err, tt.wantErr)
+			}
+			if discovered != tt.wantDiscovered {
+				t.Errorf("discovered %d contracts, want %d", discovered, tt.wantDiscovered)
+			}
+			if !reflect.DeepEqual(fetcher.ranges, tt.wantRanges) {
+				t.Errorf("fetched ranges %v, want %v", fetcher.ranges, tt.wantRanges)
+			}
+			if !reflect.DeepEqual(progress, tt.wantProgress) {
+				t.Errorf("progress %+v, want %+v", progress, tt.wantProgress)
+			}
+			for _, addr := range contracts[:tt.wantDiscovered] {
+				if !cf.Contains(addr) {
+					t.Errorf("contract %s not watched after backfill", addr.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestBackfillFromRPCConcurrentSettings(t *testing.T) {
+	_, el, _ := newListener(t)
+	el.SetBackfillChunkSize(1)
+	fetcher := &fakeLogFetcher{logs: []types.Log{creationLog(t, 150, 0, nftAddresses(1)[0])}}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			el.SetBackfillChunkSize(uint64(1 + i%5))
+			el.SetOnBackfillProgress(func(filters.BackfillProgress) {})
+		}
+	}()
+
+	if _, err := el.BackfillFromRPC(context.Background(), fetcher, 100, 200); err != nil {
+		t.Fatalf("BackfillFromRPC: %v", err)
+	}
+	wg.Wait()
+}
+
+func TestEventListenerLogsDiscoveryFields(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000003001")
+
+	var out bytes.Buffer
+	cf := newSyncFilter(t)
+	el := filters.NewEventListener(cf, factory, filters.WithListenerLogger(slog.New(slog.NewJSONHandler(&out, nil))))
+	el.ProcessLog(creationLog(t, 100, 0, contract))
+
+	var record struct {
+		Msg     string `json:"msg"`
+		Address string `json:"address"`
+		Block   uint64 `json:"block"`
+	}
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if record.Msg == "Discovered new NFT contract" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("no discovery logged in %q", out.String())
+	}
+	if record.Address != contract.Hex() || record.Block != 100 {
+		t.Errorf("logged address=%s block=%d, want address=%s block=100", record.Address, record.Block, contract.Hex())
+	}
+}
+
+func TestParseNFTContractCreatedEventTopicCount(t *testing.T) {
+	valid := creationLog(t, 100, 0, common.HexToAddress("0x0000000000000000000000000000000000000d01"))
+
+	tests := []struct {
+		name    string
+		topics  int
+		wantErr error
+		wantMsg string
+	}{
+		{name: "signature only", topics: 1, wantErr: filters.ErrUnexpectedTopicCount, wantMsg: "expected 3 or 4, got 1"},
+		{name: "fewer indexed arguments", topics: 2, wantErr: filters.ErrUnexpectedTopicCount, wantMsg: "expected 3 or 4, got 2"},
+		{name: "extra indexed argument", topics: 5, wantErr: filters.ErrUnexpectedTopicCount, wantMsg: "expected 3 or 4, got 5"},
+		{name: "indexed collectionId", topics: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := valid
+			log.Topics = append([]common.Hash(nil), valid.Topics...)
+			for len(log.Topics) < tt.topics {
+				log.Topics = append(log.Topics, common.Hash{})
+			}
+			log.Topics = log.Topics[:tt.topics]
+
+			_, err := filters.ParseNFTContractCreatedEvent(log)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ParseNFTContractCreatedEvent: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error %q does not mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestAddCreationEventSignature(t *testing.T) {
+	alternate := crypto.Keccak256Hash([]byte("CollectionCreated(uint256,address,address,string,string,string)"))
+	original := common.HexToAddress("0x0000000000000000000000000000000000004001")
+	renamed := common.HexToAddress("0x0000000000000000000000000000000000004002")
+
+	// alternateLog is a creation log emitted under the alternate event name
+	alternateLog := func() types.Log {
+		log := creationLog(t, 101, 0, renamed)
+		log.Topics[0] = alternate
+		return log
+	}
+
+	tests := []struct {
+		name     string
+		register bool
+		want     map[common.Address]bool
+		wantSigs []common.Hash
+	}{
+		{
+			name:     "only the default signature",
+			want:     map[common.Address]bool{original: true, renamed: false},
+			wantSigs: []common.Hash{filters.NFTContractCreatedSignature},
+		},
+		{
+			name:     "second signature registered",
+			register: true,
+			want:     map[common.Address]bool{original: true, renamed: true},
+			wantSigs: []common.Hash{filters.NFTContractCreatedSignature, alternate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			if tt.register {
+				el.AddCreationEventSignature(alternate)
+			}
+
+			el.ProcessLog(creationLog(t, 100, 0, original))
+			el.ProcessLog(alternateLog())
+
+			for addr, want := range tt.want {
+				if got := cf.Contains(addr); got != want {
+					t.Errorf("Contains(%s) = %v, want %v", addr.Hex(), got, want)
+				}
+			}
+			if got := el.CreationEventSignatures(); !reflect.DeepEqual(got, tt.wantSigs) {
+				t.Errorf("CreationEventSignatures = %v, want %v", got, tt.wantSigs)
+			}
+		})
+	}
+}
+
+func TestProcessLogCreationLayouts(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000005001")
+	creator := common.HexToAddress("0x000000000000000000000000000000000000c0de")
+
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalData, err := abi.Arguments{{Type: uint256Type}, {Type: stringType}, {Type: stringType}, {Type: stringType}}.
+		Pack(big.NewInt(7), "Collection", "COL", "ipfs://base/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The original layout indexes the contract address first, with collectionId in the data
+	original := types.Log{
+		Address: factory,
+		Topics: []common.Hash{
+			filters.NFTContractCreatedSignature,
+			common.BytesToHash(contract.Bytes()),
+			common.BytesToHash(creator.Bytes()),
+		},
+		Data:        originalData,
+		BlockNumber: 7,
+	}
+
+	tests := []struct {
+		name             string
+		log              types.Log
+		wantCollectionID *big.Int
+	}{
+		{name: "address-indexed-first layout", log: original, wantCollectionID: big.NewInt(7)},
+		// creationLog uses the block number as the indexed collectionId
+		{name: "collectionId-indexed-first layout", log: creationLog(t, 9, 0, contract), wantCollectionID: big.NewInt(9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			var got []*filters.NFTContractCreatedEvent
+			el.SetOnDiscover(func(event *filters.NFTContractCreatedEvent) { got = append(got, event) })
+
+			if !el.ProcessLog(tt.log) {
+				t.Fatalf("ProcessLog did not discover the contract")
+			}
+			if !cf.Contains(contract) {
+				t.Errorf("contract %s not watched", contract.Hex())
+			}
+			if cf.Contains(creator) {
+				t.Errorf("creator %s watched as a contract", creator.Hex())
+			}
+			if len(got) != 1 {
+				t.Fatalf("hook fired %d times, want 1", len(got))
+			}
+			if got[0].ContractAddress != contract || got[0].Creator != creator || got[0].CollectionID.Cmp(tt.wantCollectionID) != 0 {
+				t.Errorf("discovered %+v, want contract %s by %s in collection %s",
+					got[0], contract.Hex(), creator.Hex(), tt.wantCollectionID)
+			}
+		})
+	}
+}
+
+func TestSetFactoryAddress(t *testing.T) {
+	redeployed := common.HexToAddress("0x00000000000000000000000000000000000000f2")
+	fromOld := common.HexToAddress("0x0000000000000000000000000000000000006001")
+	fromNew := common.HexToAddress("0x0000000000000000000000000000000000006002")
+
+	// creationFrom is a creation log of contract emitted by factoryAddr
+	creationFrom := func(factoryAddr common.Address, block uint64, contract common.Address) types.Log {
+		log := creationLog(t, block, 0, contract)
+		log.Address = factoryAddr
+		return log
+	}
+
+	tests := []struct {
+		name          string
+		set           func(el *filters.EventListener)
+		wantFactories []common.Address
+		want          map[common.Address]bool
+	}{
+		{
+			name:          "factory replaced",
+			set:           func(el *filters.EventListener) { el.SetFactoryAddress(redeployed) },
+			wantFactories: []common.Address{redeployed},
+			want:          map[common.Address]bool{fromOld: false, fromNew: true},
+		},
+		{
+			name:          "factory added",
+			set:           func(el *filters.EventListener) { el.SetFactoryAddresses([]common.Address{factory, redeployed}) },
+			wantFactories: []common.Address{factory, redeployed},
+			want:          map[common.Address]bool{fromOld: true, fromNew: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, el, _ := newListener(t)
+			if el.ProcessLog(creationFrom(redeployed, 100, fromNew)) {
+				t.Fatalf("creation from %s processed before it was configured", redeployed.Hex())
+			}
+
+			tt.set(el)
+
+			if got := el.ProcessLog(creationFrom(factory, 101, fromOld)); got != tt.want[fromOld] {
+				t.Errorf("ProcessLog(creation from old factory) = %v, want %v", got, tt.want[fromOld])
+			}
+			if got := el.ProcessLog(creationFrom(redeployed, 102, fromNew)); got != tt.want[fromNew] {
+				t.Errorf("ProcessLog(creation from new factory) = %v, want %v", got, tt.want[fromNew])
+			}
+			for addr, want := range tt.want {
+				if got := cf.Contains(addr); got != want {
+					t.Errorf("Contains(%s) = %v, want %v", addr.Hex(), got, want)
+				}
+			}
+
+			// The contract filter follows the listener's factories
+			if got := cf.GetFactoryAddresses(); !reflect.DeepEqual(got, tt.wantFactories) {
+				t.Errorf("GetFactoryAddresses = %v, want %v", got, tt.wantFactories)
+			}
+			if !cf.ShouldIndexLog(redeployed) {
+				t.Errorf("logs of the new factory are not indexed")
+			}
+			if got, want := cf.ShouldIndexLog(factory), tt.want[fromOld]; got != want {
+				t.Errorf("ShouldIndexLog(old factory) = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package filters
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EventParser decodes a log into a typed event
+type EventParser func(log types.Log) (interface{}, error)
+
+// TransferEventSignature is the ERC-721 Transfer event
+const TransferEventSignature = "Transfer(address,address,uint256)"
+
+// TransferSignature is the topic0 of TransferEventSignature
+var TransferSignature = crypto.Keccak256Hash([]byte(TransferEventSignature))
+
+// TransferEvent represents a parsed ERC-721 Transfer event
+type TransferEvent struct {
+	Contract    common.Address
+	From        common.Address
+	To          common.Address
+	TokenID     *big.Int
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+}
+
+// RegisterEvent registers a parser for logs whose topic0 is the hash of
+// signature, e.g. "Transfer(address,address,uint256)", replacing any parser
+// already registered for it
+// Parsed events are delivered to the hook set with SetOnEvent. Safe to call
+// while logs are being processed
+func (el *EventListener) RegisterEvent(signature string, parser EventParser) common.Hash {
+	topic := crypto.Keccak256Hash([]byte(signature))
+
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+	el.parsers[topic] = parser
+
+	return topic
+}
+
+// SetOnEvent sets a hook invoked for every log with a registered parser other
+// than contract creation events, which are reported through SetOnDiscover instead
+// Removed (reorged) logs are delivered too; check log.Removed to tell them apart
+func (el *EventListener) SetOnEvent(fn func(log types.Log, event interface{})) {
+	el.hooksMu.Lock()
+	defer el.hooksMu.Unlock()
+	el.onEvent = fn
+}
+
+// parser returns the parser registered for topic
+func (el *EventListener) parser(topic common.Hash) (EventParser, bool) {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+
+	parser, ok := el.parsers[topic]
+	return parser, ok
+}
+
+// eventHook returns the hook set with SetOnEvent
+func (el *EventListener) eventHook() func(log types.Log, event interface{}) {
+	el.hooksMu.RLock()
+	defer el.hooksMu.RUnlock()
+	return el.onEvent
+}
+
+// ParseLog decodes a log using the parser registered for its topic0
+func (el *EventListener) ParseLog(log types.Log) (interface{}, error) {
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics: %w", ErrUnexpectedTopicCount)
+	}
+
+	parser, ok := el.parser(log.Topics[0])
+	if !ok {
+		return nil, fmt.Errorf("no parser registered for topic %s", log.Topics[0].Hex())
+	}
+	return parser(log)
+}
+
+// dispatchEvent parses a non-creation log with its registered parser and
+// hands the result to the event hook
+func (el *EventListener) dispatchEvent(log types.Log) {
+	hook := el.eventHook()
+	if hook == nil || len(log.Topics) == 0 || el.isCreationSig(log.Topics[0]) {
+		return
+	}
+	if _, ok := el.parser(log.Topics[0]); !ok {
+		return
+	}
+	// Payment events are only trusted from the configured payment contract
+	if isPaymentEvent(log.Topics[0]) && log.Address != el.contractFilter.GetPaymentAddress() {
+		return
+	}
+	// ERC-20 transfers share the Transfer topic0 but are not NFT events
+	if log.Topics[0] == TransferSignature && len(log.Topics) != 4 {
+		return
+	}
+
+	event, err := el.ParseLog(log)
+	if err != nil {
+		el.logger.Warn("Failed to parse event", "topic", log.Topics[0].Hex(), "tx", log.TxHash.Hex(), "error", err)
+		return
+	}
+	hook(log, event)
+}
+
+// parseNFTContractCreated adapts ParseNFTContractCreatedEvent to EventParser
+func parseNFTContractCreated(log types.Log) (interface{}, error) {
+	event, err := ParseNFTContractCreatedEvent(log)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ParseTransferEvent parses an ERC-721 Transfer event
+// All three parameters are indexed, so the token ID is read from Topic[3]
+func ParseTransferEvent(log types.Log) (*TransferEvent, error) {
+	if len(log.Topics) == 0 || log.Topics[0] != TransferSignature {
+		return nil, fmt.Errorf("%w: not a Transfer event", errdefs.ErrInvalidEvent)
+	}
+
+	// ERC-20 transfers share topic0 but carry the amount in data
+	if len(log.Topics) != 4 {
+		return nil, fmt.Errorf("invalid ERC-721 Transfer event: %w: expected 4, got %d", ErrUnexpectedTopicCount, len(log.Topics))
+	}
+
+	return &TransferEvent{
+		Contract:    log.Address,
+		From:        common.BytesToAddress(log.Topics[1].Bytes()),
+		To:          common.BytesToAddress(log.Topics[2].Bytes()),
+		TokenID:     new(big.Int).SetBytes(log.Topics[3].Bytes()),
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+	}, nil
+}
+
+// TransferEventParser adapts ParseTransferEvent for RegisterEvent
+// It is registered by default, so Transfer logs reach the SetOnEvent hook
+func TransferEventParser(log types.Log) (interface{}, error) {
+	event, err := ParseTransferEvent(log)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,155 @@
+package filters_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseTransferEvent(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	from := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000b02")
+	amount := common.LeftPadBytes(big.NewInt(1500).Bytes(), 32)
+
+	tests := []struct {
+		name    string
+		log     types.Log
+		want    *filters.TransferEvent
+		wantErr error
+	}{
+		{
+			name: "erc721 transfer",
+			log: types.Log{
+				Address:     contract,
+				Topics:      []common.Hash{filters.TransferSignature, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(42))},
+				BlockNumber: 7,
+				TxHash:      common.HexToHash("0x01"),
+				Index:       3,
+			},
+			want: &filters.TransferEvent{
+				Contract:    contract,
+				From:        from,
+				To:          to,
+				TokenID:     big.NewInt(42),
+				BlockNumber: 7,
+				TxHash:      common.HexToHash("0x01"),
+				LogIndex:    3,
+			},
+		},
+		{
+			name: "mint from the zero address",
+			log: types.Log{
+				Address: contract,
+				Topics:  []common.Hash{filters.TransferSignature, {}, common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(1))},
+			},
+			want: &filters.TransferEvent{Contract: contract, To: to, TokenID: big.NewInt(1)},
+		},
+		{
+			name:    "erc20 transfer",
+			log:     types.Log{Topics: []common.Hash{filters.TransferSignature, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}, Data: amount},
+			wantErr: filters.ErrUnexpectedTopicCount,
+		},
+		{
+			name:    "another event",
+			log:     types.Log{Topics: []common.Hash{filters.NFTContractCreatedSignature, {}, {}, {}}},
+			wantErr: errdefs.ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filters.ParseTransferEvent(tt.log)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v does not match %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterEvent(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	const approval = "Approval(address,address,uint256)"
+	approvalLog := types.Log{
+		Address: contract,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte(approval)), {}, {}, common.BigToHash(big.NewInt(9))},
+	}
+
+	tests := []struct {
+		name     string
+		register bool
+		log      types.Log
+		want     interface{}
+	}{
+		{
+			name: "transfer parser is registered by default",
+			log:  transferLog(contract, 10, 0),
+			want: &filters.TransferEvent{
+				Contract:    contract,
+				To:          common.HexToAddress("0x0000000000000000000000000000000000000b0b"),
+				TokenID:     big.NewInt(1),
+				BlockNumber: 10,
+			},
+		},
+		{
+			name:     "registered parser is dispatched",
+			register: true,
+			log:      approvalLog,
+			want:     "approval of token 9",
+		},
+		{
+			name: "unregistered event is not dispatched",
+			log:  approvalLog,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newSyncFilter(t)
+			cf.AddNFTContract(contract)
+			el := filters.NewEventListener(cf, factory, filters.WithListenerLogger(discardLogger()))
+
+			if tt.register {
+				topic := el.RegisterEvent(approval, func(log types.Log) (interface{}, error) {
+					return "approval of token " + log.Topics[3].Big().String(), nil
+				})
+				if topic != tt.log.Topics[0] {
+					t.Fatalf("RegisterEvent topic = %s, want %s", topic.Hex(), tt.log.Topics[0].Hex())
+				}
+			}
+
+			var got []interface{}
+			el.SetOnEvent(func(log types.Log, event interface{}) { got = append(got, event) })
+			el.ProcessLogs([]types.Log{tt.log})
+
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Fatalf("dispatched %v, want nothing", got)
+				}
+				if _, err := el.ParseLog(tt.log); err == nil {
+					t.Fatalf("ParseLog succeeded without a registered parser")
+				}
+				return
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Fatalf("dispatched %v, want [%v]", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package filters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ExportWatchedCSV writes the watched addresses as CSV with an
+// "address,role" header, role being factory, payment or nft
+// Rows are ordered by role (factories, payment, NFT contracts) and then by
+// address, so exports of the same watch set are identical
+func (cf *ContractFilter) ExportWatchedCSV(w io.Writer) error {
+	cf.mu.RLock()
+	addresses := make([]common.Address, 0, len(cf.factoryAddresses)+1)
+	for _, factory := range cf.factoryAddresses {
+		if factory != (common.Address{}) {
+			addresses = append(addresses, factory)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Cmp(addresses[j]) < 0 })
+	if cf.paymentAddress != (common.Address{}) && !cf.isFactoryLocked(cf.paymentAddress) {
+		addresses = append(addresses, cf.paymentAddress)
+	}
+	addresses = append(addresses, cf.sortedNFTContractsLocked()...)
+
+	rows := make([][]string, 0, len(addresses)+1)
+	rows = append(rows, []string{"address", "role"})
+	for _, addr := range addresses {
+		rows = append(rows, []string{addr.Hex(), cf.roleLocked(addr)})
+	}
+	cf.mu.RUnlock()
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write watched contracts CSV: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package filters
+
+import "time"
+
+// NextSyncDelay exposes nextSyncDelay to the external test package
+func (cf *ContractFilter) NextSyncDelay() time.Duration {
+	return cf.nextSyncDelay()
+}
@@ -0,0 +1,22 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/contracts/factory"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// factoryFilterer decodes factory event logs with the generated binding
+// It is not bound to an address or backend, so only its Parse methods are used
+var factoryFilterer = mustFactoryFilterer()
+
+// mustFactoryFilterer creates the unbound factory filterer, panicking if the
+// embedded ABI is invalid
+func mustFactoryFilterer() *factory.FactoryFilterer {
+	filterer, err := factory.NewFactoryFilterer(common.Address{}, nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid factory binding: %v", err))
+	}
+	return filterer
+}
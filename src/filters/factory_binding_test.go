@@ -0,0 +1,85 @@
+package filters_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	factorybinding "github.com/A8-Tim/dopamint-indexer-insight/src/contracts/factory"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestFactoryBindingDecodesCreationLog(t *testing.T) {
+	factoryABI, err := factorybinding.FactoryMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("GetAbi: %v", err)
+	}
+	event, ok := factoryABI.Events["NFTContractCreated"]
+	if !ok {
+		t.Fatalf("factory ABI has no NFTContractCreated event")
+	}
+	if event.ID != filters.NFTContractCreatedSignature {
+		t.Fatalf("ABI event ID %s differs from NFTContractCreatedSignature %s", event.ID.Hex(), filters.NFTContractCreatedSignature.Hex())
+	}
+
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	creator := common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	filterer, err := factorybinding.NewFactoryFilterer(factoryAddr, nil)
+	if err != nil {
+		t.Fatalf("NewFactoryFilterer: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		collectionID *big.Int
+		meta         [3]string // name, symbol and baseURI
+	}{
+		{name: "typical collection", collectionID: big.NewInt(42), meta: [3]string{"Collection", "COL", "ipfs://base/"}},
+		{name: "long metadata", collectionID: new(big.Int).Lsh(big.NewInt(1), 200), meta: [3]string{string(make([]byte, 100)), "", "ipfs://" + string(make([]byte, 64))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Encode the log exactly as the contract emits it
+			data, err := event.Inputs.NonIndexed().Pack(tt.collectionID, tt.meta[0], tt.meta[1], tt.meta[2])
+			if err != nil {
+				t.Fatalf("Pack: %v", err)
+			}
+			log := types.Log{
+				Address:     factoryAddr,
+				Topics:      []common.Hash{event.ID, common.BytesToHash(contract.Bytes()), common.BytesToHash(creator.Bytes())},
+				Data:        data,
+				BlockNumber: 7,
+				Index:       3,
+			}
+
+			decoded, err := filterer.ParseNFTContractCreated(log)
+			if err != nil {
+				t.Fatalf("ParseNFTContractCreated: %v", err)
+			}
+			got, err := filters.ParseNFTContractCreatedEvent(log)
+			if err != nil {
+				t.Fatalf("ParseNFTContractCreatedEvent: %v", err)
+			}
+
+			want := &filters.NFTContractCreatedEvent{
+				CollectionID:    decoded.CollectionId,
+				ContractAddress: decoded.ContractAddress,
+				Creator:         decoded.Creator,
+				Name:            decoded.Name,
+				Symbol:          decoded.Symbol,
+				BaseURI:         decoded.BaseURI,
+				BlockNumber:     decoded.Raw.BlockNumber,
+				LogIndex:        decoded.Raw.Index,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseNFTContractCreatedEvent = %+v, binding decoded %+v", got, want)
+			}
+			if decoded.ContractAddress != contract || decoded.Creator != creator || decoded.CollectionId.Cmp(tt.collectionID) != 0 || decoded.Name != tt.meta[0] {
+				t.Errorf("binding decoded %+v", decoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package filterstest provides test doubles for the filters package
+package filterstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MockMongoDBClient is an in-memory filters.MongoDBClient for exercising the
+// MongoDB sync loop without a database
+type MockMongoDBClient struct {
+	mu        sync.Mutex
+	addresses []common.Address
+	active    []common.Address
+	byChain   map[int64][]common.Address
+	err       error
+	calls     int
+	onFetch   func(call int)
+}
+
+var (
+	_ filters.MongoDBClient           = (*MockMongoDBClient)(nil)
+	_ filters.MultiChainMongoDBClient = (*MockMongoDBClient)(nil)
+)
+
+// NewMockMongoDBClient creates a mock returning the given addresses
+func NewMockMongoDBClient(addresses ...common.Address) *MockMongoDBClient {
+	m := &MockMongoDBClient{}
+	m.SetAddresses(addresses...)
+	return m
+}
+
+// SetAddresses replaces the addresses returned by subsequent fetches, both
+// as the non-deleted and the active set
+func (m *MockMongoDBClient) SetAddresses(addresses ...common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addresses = append([]common.Address(nil), addresses...)
+	m.active = append([]common.Address(nil), addresses...)
+}
+
+// SetActiveAddresses replaces only the active set, e.g. to simulate contracts
+// being deactivated while still stored
+func (m *MockMongoDBClient) SetActiveAddresses(addresses ...common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.active = append([]common.Address(nil), addresses...)
+}
+
+// SetChainAddresses replaces the active addresses returned for one chain by
+// GetActiveNFTContractAddressesByChain
+func (m *MockMongoDBClient) SetChainAddresses(chainID int64, addresses ...common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.byChain == nil {
+		m.byChain = make(map[int64][]common.Address)
+	}
+	m.byChain[chainID] = append([]common.Address(nil), addresses...)
+}
+
+// SetError makes subsequent fetches fail with err; nil clears it
+func (m *MockMongoDBClient) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+}
+
+// SetOnFetch sets a hook invoked at the start of every fetch with its 1-based
+// call number, before the result is read
+// The hook may call SetAddresses or SetError to change what that fetch and
+// later ones return, e.g. to simulate contracts appearing between sync ticks
+func (m *MockMongoDBClient) SetOnFetch(fn func(call int)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onFetch = fn
+}
+
+// Calls returns how many fetches have been made
+func (m *MockMongoDBClient) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+// GetNFTContractAddresses returns the configured addresses or error
+func (m *MockMongoDBClient) GetNFTContractAddresses(ctx context.Context) ([]common.Address, error) {
+	return m.fetch(ctx, func() []common.Address { return m.addresses })
+}
+
+// GetActiveNFTContractAddresses returns the configured active addresses or error
+func (m *MockMongoDBClient) GetActiveNFTContractAddresses(ctx context.Context) ([]common.Address, error) {
+	return m.fetch(ctx, func() []common.Address { return m.active })
+}
+
+// GetActiveNFTContractAddressesByChain returns the per-chain addresses set
+// with SetChainAddresses or the configured error, counting as a single fetch
+func (m *MockMongoDBClient) GetActiveNFTContractAddressesByChain(ctx context.Context) (map[int64][]common.Address, error) {
+	if err := m.beginFetch(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	byChain := make(map[int64][]common.Address, len(m.byChain))
+	for chainID, addresses := range m.byChain {
+		byChain[chainID] = append([]common.Address(nil), addresses...)
+	}
+	return byChain, nil
+}
+
+// fetch runs the fetch hook and returns a copy of the selected addresses
+// or the injected error; selected is called with m.mu held
+func (m *MockMongoDBClient) fetch(ctx context.Context, selected func() []common.Address) ([]common.Address, error) {
+	if err := m.beginFetch(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	return append([]common.Address(nil), selected()...), nil
+}
+
+// beginFetch counts a fetch and runs the fetch hook without holding m.mu
+func (m *MockMongoDBClient) beginFetch(ctx context.Context) error {
+	m.mu.Lock()
+	m.calls++
+	call := m.calls
+	onFetch := m.onFetch
+	m.mu.Unlock()
+
+	if onFetch != nil {
+		onFetch(call)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,251 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MultiChainMongoDBClient fetches active contract addresses for every chain at once
+type MultiChainMongoDBClient interface {
+	GetActiveNFTContractAddressesByChain(ctx context.Context) (map[int64][]common.Address, error)
+}
+
+// MultiChainFilter routes filtering decisions to one ContractFilter per chain,
+// keyed by the chain ID of each filter's config
+type MultiChainFilter struct {
+	mu      sync.RWMutex
+	filters map[int64]*ContractFilter
+	logger  logging.Logger
+}
+
+// MultiChainStats aggregates the stats of every chain filter
+type MultiChainStats struct {
+	Chains       map[int64]FilterStats `json:"chains"`
+	TotalWatched int                   `json:"total_watched"`
+}
+
+// NewMultiChainFilter creates a multi-chain filter from per-chain filters
+// Each filter must have a distinct, non-zero chain ID
+func NewMultiChainFilter(filters ...*ContractFilter) (*MultiChainFilter, error) {
+	mcf := &MultiChainFilter{
+		filters: make(map[int64]*ContractFilter, len(filters)),
+		logger:  logging.Default("MultiChainFilter"),
+	}
+	for _, cf := range filters {
+		if err := mcf.AddChain(cf); err != nil {
+			return nil, err
+		}
+	}
+
+	return mcf, nil
+}
+
+// NewMultiChainFilterFromConfigs creates a multi-chain filter with one filter
+// per config file
+func NewMultiChainFilterFromConfigs(configPaths []string, opts ...ContractFilterOption) (*MultiChainFilter, error) {
+	filters := make([]*ContractFilter, 0, len(configPaths))
+	for _, path := range configPaths {
+		cf, err := NewContractFilter(path, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load filter config %s: %w", path, err)
+		}
+		filters = append(filters, cf)
+	}
+
+	return NewMultiChainFilter(filters...)
+}
+
+// AddChain registers the filter for its chain
+func (mcf *MultiChainFilter) AddChain(cf *ContractFilter) error {
+	chainID := cf.ChainID()
+	if chainID == 0 {
+		return fmt.Errorf("%w: filter has no chain ID configured", errdefs.ErrInvalidConfig)
+	}
+
+	mcf.mu.Lock()
+	defer mcf.mu.Unlock()
+
+	if _, exists := mcf.filters[chainID]; exists {
+		return fmt.Errorf("%w: duplicate filter for chain %d", errdefs.ErrInvalidConfig, chainID)
+	}
+	mcf.filters[chainID] = cf
+
+	return nil
+}
+
+// Filter returns the filter of a chain
+func (mcf *MultiChainFilter) Filter(chainID int64) (*ContractFilter, bool) {
+	mcf.mu.RLock()
+	defer mcf.mu.RUnlock()
+
+	cf, ok := mcf.filters[chainID]
+	return cf, ok
+}
+
+// ChainIDs returns the configured chain IDs in ascending order
+func (mcf *MultiChainFilter) ChainIDs() []int64 {
+	mcf.mu.RLock()
+	defer mcf.mu.RUnlock()
+
+	chainIDs := make([]int64, 0, len(mcf.filters))
+	for chainID := range mcf.filters {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+
+	return chainIDs
+}
+
+// ShouldIndexLog determines if a log on the given chain should be indexed
+// Logs from chains without a filter are never indexed
+func (mcf *MultiChainFilter) ShouldIndexLog(chainID int64, address common.Address) bool {
+	cf, ok := mcf.Filter(chainID)
+	return ok && cf.ShouldIndexLog(address)
+}
+
+// ShouldIndexEvent determines if a log on the given chain with the given
+// event signature should be indexed
+func (mcf *MultiChainFilter) ShouldIndexEvent(chainID int64, address common.Address, topic0 common.Hash) bool {
+	cf, ok := mcf.Filter(chainID)
+	return ok && cf.ShouldIndexEvent(address, topic0)
+}
+
+// Stats returns the stats of every chain filter and the total watched count
+func (mcf *MultiChainFilter) Stats() MultiChainStats {
+	mcf.mu.RLock()
+	defer mcf.mu.RUnlock()
+
+	stats := MultiChainStats{Chains: make(map[int64]FilterStats, len(mcf.filters))}
+	for chainID, cf := range mcf.filters {
+		chainStats := cf.FilterStats()
+		stats.Chains[chainID] = chainStats
+		stats.TotalWatched += chainStats.TotalWatched
+	}
+
+	return stats
+}
+
+// syncFilters returns the filters that have MongoDB sync enabled
+func (mcf *MultiChainFilter) syncFilters() map[int64]*ContractFilter {
+	mcf.mu.RLock()
+	defer mcf.mu.RUnlock()
+
+	filters := make(map[int64]*ContractFilter, len(mcf.filters))
+	for chainID, cf := range mcf.filters {
+		if cf.syncEnabled() {
+			filters[chainID] = cf
+		}
+	}
+
+	return filters
+}
+
+// SyncFromMongoDB fetches the active contracts of all chains in one query and
+// reconciles each chain filter against its own partition
+func (mcf *MultiChainFilter) SyncFromMongoDB(ctx context.Context, mongoClient MultiChainMongoDBClient) error {
+	filters := mcf.syncFilters()
+	if len(filters) == 0 {
+		return nil
+	}
+
+	byChain, err := mongoClient.GetActiveNFTContractAddressesByChain(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to fetch NFT contracts from MongoDB: %w", err)
+		for _, cf := range filters {
+			cf.recordSyncResult(err)
+		}
+		return err
+	}
+
+	for _, cf := range filters {
+		cf.applySync(byChain[cf.ChainID()])
+		cf.recordSyncResult(nil)
+	}
+
+	return nil
+}
+
+// StartMongoDBSync syncs all chains from MongoDB at the shortest interval
+// configured among the chain filters with sync enabled, backing off like
+// ContractFilter.StartMongoDBSync while syncs keep failing
+// The returned channel is closed once the goroutine has exited after the
+// context is cancelled; it is closed immediately when no chain has sync enabled
+func (mcf *MultiChainFilter) StartMongoDBSync(ctx context.Context, mongoClient MultiChainMongoDBClient) <-chan struct{} {
+	done := make(chan struct{})
+
+	filters := mcf.syncFilters()
+	if len(filters) == 0 {
+		mcf.logger.Info("MongoDB sync is disabled for all chains")
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+
+		mcf.logger.Info("Starting MongoDB sync", "chains", len(filters), "interval", nextSyncDelay(filters))
+		if err := mcf.SyncFromMongoDB(ctx, mongoClient); err != nil {
+			mcf.logger.Error("Initial MongoDB sync failed", "error", err)
+		}
+
+		timer := time.NewTimer(nextSyncDelay(mcf.syncFilters()))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				mcf.logger.Info("MongoDB sync stopped")
+				return
+			case <-timer.C:
+				if err := mcf.SyncFromMongoDB(ctx, mongoClient); err != nil {
+					mcf.logger.Error("MongoDB sync failed", "error", err)
+				}
+
+				// Re-read the filters so config reloads and added chains take effect
+				filters := mcf.syncFilters()
+				delay := nextSyncDelay(filters)
+				if failures := consecutiveSyncFailures(filters); failures > 0 {
+					mcf.logger.Warn("Consecutive MongoDB sync failures", "failures", failures, "nextAttempt", delay)
+				}
+				timer.Reset(delay)
+			}
+		}
+	}()
+
+	return done
+}
+
+// nextSyncDelay returns the shortest backed-off sync delay among filters, or
+// the default interval when there are none
+func nextSyncDelay(filters map[int64]*ContractFilter) time.Duration {
+	delay := time.Duration(0)
+	for _, cf := range filters {
+		if chainDelay := cf.nextSyncDelay(); delay == 0 || chainDelay < delay {
+			delay = chainDelay
+		}
+	}
+	if delay == 0 {
+		delay = defaultSyncInterval
+	}
+
+	return delay
+}
+
+// consecutiveSyncFailures returns the largest consecutive failure count among filters
+func consecutiveSyncFailures(filters map[int64]*ContractFilter) int {
+	failures := 0
+	for _, cf := range filters {
+		if chainFailures := cf.ConsecutiveSyncFailures(); chainFailures > failures {
+			failures = chainFailures
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,94 @@
+package filters_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters/filterstest"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	chainA int64 = 8453
+	chainB int64 = 84532
+)
+
+// newMultiChainFilter creates a filter for chainA and chainB
+func newMultiChainFilter(t *testing.T) *filters.MultiChainFilter {
+	t.Helper()
+
+	mcf, err := filters.NewMultiChainFilter(newChainFilter(t, chainA), newChainFilter(t, chainB))
+	if err != nil {
+		t.Fatalf("NewMultiChainFilter: %v", err)
+	}
+	return mcf
+}
+
+func TestMultiChainFilterRoutesByChain(t *testing.T) {
+	onA := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	onB := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+
+	mcf := newMultiChainFilter(t)
+	mock := filterstest.NewMockMongoDBClient()
+	mock.SetChainAddresses(chainA, onA)
+	mock.SetChainAddresses(chainB, onB)
+	if err := mcf.SyncFromMongoDB(context.Background(), mock); err != nil {
+		t.Fatalf("SyncFromMongoDB: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		chainID int64
+		address common.Address
+		want    bool
+	}{
+		{name: "contract on its chain", chainID: chainA, address: onA, want: true},
+		{name: "contract on the other chain", chainID: chainB, address: onA, want: false},
+		{name: "second chain contract", chainID: chainB, address: onB, want: true},
+		{name: "second chain contract on first chain", chainID: chainA, address: onB, want: false},
+		{name: "unknown chain", chainID: 1, address: onA, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mcf.ShouldIndexLog(tt.chainID, tt.address); got != tt.want {
+				t.Fatalf("ShouldIndexLog(%d, %s) = %v, want %v", tt.chainID, tt.address.Hex(), got, tt.want)
+			}
+		})
+	}
+
+	if got := mcf.Stats().TotalWatched; got < 2 {
+		t.Fatalf("TotalWatched = %d, want at least 2", got)
+	}
+}
+
+func TestMultiChainSyncTracksFailures(t *testing.T) {
+	mcf := newMultiChainFilter(t)
+	mock := filterstest.NewMockMongoDBClient()
+	mock.SetError(errors.New("connection refused"))
+
+	for i := 0; i < 3; i++ {
+		if err := mcf.SyncFromMongoDB(context.Background(), mock); err == nil {
+			t.Fatalf("SyncFromMongoDB succeeded with a failing client")
+		}
+	}
+	for _, chainID := range mcf.ChainIDs() {
+		cf, _ := mcf.Filter(chainID)
+		if got := cf.ConsecutiveSyncFailures(); got != 3 {
+			t.Fatalf("chain %d: ConsecutiveSyncFailures = %d, want 3", chainID, got)
+		}
+	}
+
+	mock.SetError(nil)
+	if err := mcf.SyncFromMongoDB(context.Background(), mock); err != nil {
+		t.Fatalf("SyncFromMongoDB: %v", err)
+	}
+	for _, chainID := range mcf.ChainIDs() {
+		cf, _ := mcf.Filter(chainID)
+		if got := cf.ConsecutiveSyncFailures(); got != 0 {
+			t.Fatalf("chain %d: ConsecutiveSyncFailures after success = %d, want 0", chainID, got)
+		}
+	}
+}
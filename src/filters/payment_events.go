@@ -0,0 +1,159 @@
+package filters
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Payment contract event signatures
+var (
+	// PaymentReceived(address indexed payer, uint256 amount, uint256 indexed genID)
+	PaymentReceivedSignature = crypto.Keccak256Hash([]byte("PaymentReceived(address,uint256,uint256)"))
+	// Withdrawal(address indexed to, uint256 amount)
+	WithdrawalSignature = crypto.Keccak256Hash([]byte("Withdrawal(address,uint256)"))
+)
+
+// paymentEventsABIJSON is the payment contract ABI fragment for its events
+const paymentEventsABIJSON = `[{
+	"anonymous": false,
+	"name": "PaymentReceived",
+	"type": "event",
+	"inputs": [
+		{"indexed": true, "internalType": "address", "name": "payer", "type": "address"},
+		{"indexed": false, "internalType": "uint256", "name": "amount", "type": "uint256"},
+		{"indexed": true, "internalType": "uint256", "name": "genID", "type": "uint256"}
+	]
+}, {
+	"anonymous": false,
+	"name": "Withdrawal",
+	"type": "event",
+	"inputs": [
+		{"indexed": true, "internalType": "address", "name": "to", "type": "address"},
+		{"indexed": false, "internalType": "uint256", "name": "amount", "type": "uint256"}
+	]
+}]`
+
+// paymentEventsABI is the parsed payment event ABI used to decode log data
+var paymentEventsABI = mustParseABI(paymentEventsABIJSON)
+
+// PaymentReceivedEvent represents a parsed PaymentReceived event
+// GenID identifies the AI generation the payment is for, which links the
+// payment to the mint of the generated token
+type PaymentReceivedEvent struct {
+	Payer       common.Address
+	Amount      *big.Int
+	GenID       *big.Int
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+}
+
+// WithdrawalEvent represents a parsed Withdrawal event
+type WithdrawalEvent struct {
+	To          common.Address
+	Amount      *big.Int
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+}
+
+// isPaymentEvent reports whether topic0 is one of the payment contract events
+func isPaymentEvent(topic0 common.Hash) bool {
+	return topic0 == PaymentReceivedSignature || topic0 == WithdrawalSignature
+}
+
+// unpackAmount decodes the single non-indexed amount of a payment event
+func unpackAmount(eventName string, data []byte) (*big.Int, error) {
+	values, err := paymentEventsABI.Unpack(eventName, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s data: %w: %w", eventName, ErrInvalidEventData, err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("invalid %s data: %w: expected 1 value, got %d", eventName, ErrInvalidEventData, len(values))
+	}
+
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s data: %w: amount is %T", eventName, ErrInvalidEventData, values[0])
+	}
+	return amount, nil
+}
+
+// ParsePaymentReceivedEvent parses a PaymentReceived event
+// Topic layout:
+// Topic[0] = event signature
+// Topic[1] = payer (indexed)
+// Topic[2] = genID (indexed)
+// The amount is the only data field
+func ParsePaymentReceivedEvent(log types.Log) (*PaymentReceivedEvent, error) {
+	if len(log.Topics) == 0 || log.Topics[0] != PaymentReceivedSignature {
+		return nil, fmt.Errorf("%w: not a PaymentReceived event", errdefs.ErrInvalidEvent)
+	}
+
+	if len(log.Topics) < 3 {
+		return nil, fmt.Errorf("invalid PaymentReceived event: %w: got %d", ErrUnexpectedTopicCount, len(log.Topics))
+	}
+
+	amount, err := unpackAmount("PaymentReceived", log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentReceivedEvent{
+		Payer:       common.BytesToAddress(log.Topics[1].Bytes()),
+		Amount:      amount,
+		GenID:       new(big.Int).SetBytes(log.Topics[2].Bytes()),
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+	}, nil
+}
+
+// ParseWithdrawalEvent parses a Withdrawal event
+// Topic[1] is the recipient and the amount is the only data field
+func ParseWithdrawalEvent(log types.Log) (*WithdrawalEvent, error) {
+	if len(log.Topics) == 0 || log.Topics[0] != WithdrawalSignature {
+		return nil, fmt.Errorf("%w: not a Withdrawal event", errdefs.ErrInvalidEvent)
+	}
+
+	if len(log.Topics) < 2 {
+		return nil, fmt.Errorf("invalid Withdrawal event: %w: got %d", ErrUnexpectedTopicCount, len(log.Topics))
+	}
+
+	amount, err := unpackAmount("Withdrawal", log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WithdrawalEvent{
+		To:          common.BytesToAddress(log.Topics[1].Bytes()),
+		Amount:      amount,
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+	}, nil
+}
+
+// parsePaymentReceived adapts ParsePaymentReceivedEvent to EventParser
+func parsePaymentReceived(log types.Log) (interface{}, error) {
+	event, err := ParsePaymentReceivedEvent(log)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// parseWithdrawal adapts ParseWithdrawalEvent to EventParser
+func parseWithdrawal(log types.Log) (interface{}, error) {
+	event, err := ParseWithdrawalEvent(log)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
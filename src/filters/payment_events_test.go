@@ -0,0 +1,129 @@
+package filters_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParsePaymentEvents(t *testing.T) {
+	payer := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	amount := common.LeftPadBytes(big.NewInt(1500).Bytes(), 32)
+	addressTopic := common.BytesToHash(payer.Bytes())
+	genTopic := common.BigToHash(big.NewInt(42))
+
+	tests := []struct {
+		name    string
+		parse   func(log types.Log) (interface{}, error)
+		log     types.Log
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name:  "payment received",
+			parse: func(log types.Log) (interface{}, error) { return filters.ParsePaymentReceivedEvent(log) },
+			log: types.Log{
+				Topics:      []common.Hash{filters.PaymentReceivedSignature, addressTopic, genTopic},
+				Data:        amount,
+				BlockNumber: 7,
+				Index:       2,
+			},
+			want: &filters.PaymentReceivedEvent{Payer: payer, Amount: big.NewInt(1500), GenID: big.NewInt(42), BlockNumber: 7, LogIndex: 2},
+		},
+		{
+			name:    "payment received without genID",
+			parse:   func(log types.Log) (interface{}, error) { return filters.ParsePaymentReceivedEvent(log) },
+			log:     types.Log{Topics: []common.Hash{filters.PaymentReceivedSignature, addressTopic}, Data: amount},
+			wantErr: filters.ErrUnexpectedTopicCount,
+		},
+		{
+			name:    "payment received with truncated data",
+			parse:   func(log types.Log) (interface{}, error) { return filters.ParsePaymentReceivedEvent(log) },
+			log:     types.Log{Topics: []common.Hash{filters.PaymentReceivedSignature, addressTopic, genTopic}, Data: amount[:16]},
+			wantErr: filters.ErrInvalidEventData,
+		},
+		{
+			name:  "withdrawal",
+			parse: func(log types.Log) (interface{}, error) { return filters.ParseWithdrawalEvent(log) },
+			log:   types.Log{Topics: []common.Hash{filters.WithdrawalSignature, addressTopic}, Data: amount},
+			want:  &filters.WithdrawalEvent{To: payer, Amount: big.NewInt(1500)},
+		},
+		{
+			name:    "withdrawal with another signature",
+			parse:   func(log types.Log) (interface{}, error) { return filters.ParseWithdrawalEvent(log) },
+			log:     types.Log{Topics: []common.Hash{filters.PaymentReceivedSignature, addressTopic}, Data: amount},
+			wantErr: errdefs.ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.log)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v does not match %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentEventsDispatch(t *testing.T) {
+	payer := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	paymentLog := func(address common.Address) types.Log {
+		return types.Log{
+			Address: address,
+			Topics:  []common.Hash{filters.PaymentReceivedSignature, common.BytesToHash(payer.Bytes()), common.BigToHash(big.NewInt(42))},
+			Data:    common.LeftPadBytes(big.NewInt(1500).Bytes(), 32),
+		}
+	}
+
+	tests := []struct {
+		name string
+		log  types.Log
+		want interface{}
+	}{
+		{
+			name: "from the payment contract",
+			log:  paymentLog(paymentAddr),
+			want: &filters.PaymentReceivedEvent{Payer: payer, Amount: big.NewInt(1500), GenID: big.NewInt(42)},
+		},
+		{
+			name: "from another watched contract",
+			log:  paymentLog(other),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, filterConfig)
+			cf.AddNFTContract(other)
+			el := filters.NewEventListener(cf, factoryAddr, filters.WithListenerLogger(discardLogger()))
+
+			var got []interface{}
+			el.SetOnEvent(func(log types.Log, event interface{}) { got = append(got, event) })
+			el.ProcessLogs([]types.Log{tt.log})
+
+			switch {
+			case tt.want == nil && len(got) != 0:
+				t.Fatalf("dispatched %v, want nothing", got)
+			case tt.want != nil && (len(got) != 1 || !reflect.DeepEqual(got[0], tt.want)):
+				t.Fatalf("dispatched %v, want [%+v]", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FilterSnapshot is the persisted form of the ContractFilter watch set
+type FilterSnapshot struct {
+	FactoryAddresses []string  `json:"factoryAddresses"`
+	PaymentAddress   string    `json:"paymentAddress"`
+	NFTContracts     []string  `json:"nftContracts"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// SaveSnapshot writes the current watch set to path as JSON
+// The file is written to a temporary file first and renamed into place
+func (cf *ContractFilter) SaveSnapshot(path string) error {
+	cf.mu.RLock()
+	snapshot := FilterSnapshot{
+		FactoryAddresses: make([]string, 0, len(cf.factoryAddresses)),
+		PaymentAddress:   cf.paymentAddress.Hex(),
+		NFTContracts:     make([]string, 0, len(cf.nftContracts)),
+		Timestamp:        time.Now().UTC(),
+	}
+	for _, addr := range cf.factoryAddresses {
+		snapshot.FactoryAddresses = append(snapshot.FactoryAddresses, addr.Hex())
+	}
+	for addr := range cf.nftContracts {
+		snapshot.NFTContracts = append(snapshot.NFTContracts, addr.Hex())
+	}
+	cf.mu.RUnlock()
+
+	sort.Strings(snapshot.NFTContracts)
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	cf.logger.Info("Saved snapshot", "path", path, "nftContracts", len(snapshot.NFTContracts))
+	return nil
+}
+
+// LoadSnapshot merges the NFT contracts from a snapshot into the watch set
+// Existing contracts are kept and malformed entries are skipped. Factory and
+// payment addresses still come from the config; a mismatch is only reported
+func (cf *ContractFilter) LoadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot FilterSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	addresses := make([]common.Address, 0, len(snapshot.NFTContracts))
+	skipped := 0
+	for _, addr := range snapshot.NFTContracts {
+		if !common.IsHexAddress(addr) {
+			skipped++
+			continue
+		}
+		addresses = append(addresses, common.HexToAddress(addr))
+	}
+
+	cf.mu.RLock()
+	paymentMismatch := snapshot.PaymentAddress != "" && common.HexToAddress(snapshot.PaymentAddress) != cf.paymentAddress
+	cf.mu.RUnlock()
+	if paymentMismatch {
+		cf.logger.Warn("Snapshot payment address differs from config", "paymentAddress", snapshot.PaymentAddress)
+	}
+
+	cf.AddNFTContracts(addresses)
+
+	cf.logger.Info("Loaded snapshot", "path", path, "takenAt", snapshot.Timestamp.Format(time.RFC3339),
+		"nftContracts", len(addresses), "skipped", skipped)
+	return nil
+}
@@ -0,0 +1,66 @@
+package filters_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// sortedAddresses returns addresses sorted by their hex form
+func sortedAddresses(addresses []common.Address) []common.Address {
+	sorted := append([]common.Address(nil), addresses...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Hex() < sorted[j].Hex() })
+	return sorted
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	contracts := nftAddresses(5)
+	existing := common.HexToAddress("0x000000000000000000000000000000000000b001")
+
+	source := newFilter(t, filterConfig)
+	source.AddNFTContracts(contracts)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := source.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+
+	restored := newFilter(t, filterConfig)
+	restored.AddNFTContract(existing)
+	if err := restored.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	want := sortedAddresses(append(source.GetNFTContractAddresses(), existing))
+	if got := sortedAddresses(restored.GetNFTContractAddresses()); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored NFT contracts = %v, want %v", got, want)
+	}
+	want = sortedAddresses(append(source.GetWatchedAddresses(), existing))
+	if got := sortedAddresses(restored.GetWatchedAddresses()); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored watch set = %v, want %v", got, want)
+	}
+}
+
+func TestLoadSnapshotSkipsMalformedEntries(t *testing.T) {
+	valid := common.HexToAddress("0x000000000000000000000000000000000000b002")
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `{"nftContracts": ["` + valid.Hex() + `", "not-an-address", "0x1234", ""]}`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf := newFilter(t, filterConfig)
+	if err := cf.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if got := cf.GetNFTContractAddresses(); !reflect.DeepEqual(got, []common.Address{valid}) {
+		t.Errorf("NFT contracts = %v, want only %s", got, valid.Hex())
+	}
+
+	if err := cf.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadSnapshot of a missing file succeeded")
+	}
+}
@@ -0,0 +1,376 @@
+package filters_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters/filterstest"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const syncConfig = `{
+	"network": "base-sepolia",
+	"chainId": %d,
+	"contracts": {
+		"factory": {"address": "0x00000000000000000000000000000000000000f1"},
+		"payment": {"address": "0x00000000000000000000000000000000000000e1"}
+	},
+	"eventFilters": {"enabled": true},
+	"syncSettings": {"mongodbSync": {"enabled": true, "intervalSeconds": 1}}
+}`
+
+// newSyncFilter creates a filter syncing from MongoDB every second
+func newSyncFilter(t *testing.T, opts ...filters.ContractFilterOption) *filters.ContractFilter {
+	t.Helper()
+	return newChainFilter(t, 84532, opts...)
+}
+
+// newChainFilter creates a filter for chainID syncing from MongoDB every second
+func newChainFilter(t *testing.T, chainID int64, opts ...filters.ContractFilterOption) *filters.ContractFilter {
+	t.Helper()
+	return newFilter(t, fmt.Sprintf(syncConfig, chainID), opts...)
+}
+
+// discardLogger returns a logger dropping all output
+func discardLogger() logging.Logger {
+	return logging.New(io.Discard, "test", slog.LevelDebug)
+}
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMongoDBSyncPicksUpNewAddressesAcrossTicks(t *testing.T) {
+	first := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	second := common.HexToAddress("0x0000000000000000000000000000000000000a02")
+
+	cf := newSyncFilter(t)
+	mock := filterstest.NewMockMongoDBClient(first)
+	// The second contract appears in MongoDB between the initial sync and the first tick
+	mock.SetOnFetch(func(call int) {
+		if call == 2 {
+			mock.SetAddresses(first, second)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := cf.StartMongoDBSync(ctx, mock)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor(t, 5*time.Second, "initial sync", func() bool { return cf.Contains(first) })
+	if mock.Calls() == 1 && cf.Contains(second) {
+		t.Fatalf("second contract watched before it was stored")
+	}
+
+	waitFor(t, 5*time.Second, "second tick", func() bool { return cf.Contains(second) })
+	if calls := mock.Calls(); calls < 2 {
+		t.Fatalf("got %d fetches, want at least 2", calls)
+	}
+	if !cf.Contains(first) {
+		t.Fatalf("first contract dropped by the second sync")
+	}
+	if err := cf.LastSyncError(); err != nil {
+		t.Fatalf("LastSyncError = %v", err)
+	}
+}
+
+func TestMongoDBSyncKeepsPendingDiscoveries(t *testing.T) {
+	stored := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	discovered := common.HexToAddress("0x0000000000000000000000000000000000000b02")
+
+	tests := []struct {
+		name      string
+		grace     time.Duration
+		synced    []common.Address
+		wantKept  bool
+		secondRun []common.Address // addresses of a follow-up sync, if any
+		wantAfter bool
+	}{
+		{name: "within grace period", grace: time.Hour, synced: []common.Address{stored}, wantKept: true},
+		{name: "grace period disabled", grace: 0, synced: []common.Address{stored}, wantKept: false},
+		{name: "empty sync within grace period", grace: time.Hour, wantKept: true},
+		{
+			name:      "removed once stored and then deactivated",
+			grace:     time.Hour,
+			synced:    []common.Address{stored, discovered},
+			wantKept:  true,
+			secondRun: []common.Address{stored},
+			wantAfter: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newSyncFilter(t, filters.WithDiscoveryGracePeriod(tt.grace))
+			if !cf.AddDiscoveredNFTContract(discovered) {
+				t.Fatalf("AddDiscoveredNFTContract returned false")
+			}
+
+			mock := filterstest.NewMockMongoDBClient(tt.synced...)
+			if err := cf.WarmFromMongoDB(context.Background(), mock); err != nil {
+				t.Fatalf("WarmFromMongoDB: %v", err)
+			}
+			if got := cf.Contains(discovered); got != tt.wantKept {
+				t.Fatalf("Contains(discovered) = %v, want %v", got, tt.wantKept)
+			}
+
+			if tt.secondRun == nil {
+				return
+			}
+			mock.SetAddresses(tt.secondRun...)
+			if err := cf.WarmFromMongoDB(context.Background(), mock); err != nil {
+				t.Fatalf("WarmFromMongoDB: %v", err)
+			}
+			if got := cf.Contains(discovered); got != tt.wantAfter {
+				t.Fatalf("Contains(discovered) after second sync = %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestMongoDBSyncRemovesStaleContracts(t *testing.T) {
+	kept := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	dropped := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	configured := common.HexToAddress("0x0000000000000000000000000000000000000c03")
+
+	config := fmt.Sprintf(`{
+	"network": "base-sepolia",
+	"chainId": 84532,
+	"contracts": {
+		"factory": {"address": "0x00000000000000000000000000000000000000f1"},
+		"payment": {"address": "0x00000000000000000000000000000000000000e1"},
+		"nftContracts": [%q]
+	},
+	"eventFilters": {"enabled": true}
+}`, configured.Hex())
+
+	tests := []struct {
+		name  string
+		syncs [][]common.Address // results of the syncs following the initial one
+		want  map[common.Address]bool
+	}{
+		{
+			name:  "contract disappears from MongoDB",
+			syncs: [][]common.Address{{kept}},
+			want:  map[common.Address]bool{kept: true, dropped: false, configured: true},
+		},
+		{
+			name:  "single empty result keeps the watch set",
+			syncs: [][]common.Address{{}},
+			want:  map[common.Address]bool{kept: true, dropped: true, configured: true},
+		},
+		{
+			name:  "confirmed empty result removes synced contracts",
+			syncs: [][]common.Address{{}, {}},
+			want:  map[common.Address]bool{kept: false, dropped: false, configured: true},
+		},
+		{
+			name:  "non-empty result resets the confirmation",
+			syncs: [][]common.Address{{}, {kept}, {}},
+			want:  map[common.Address]bool{kept: true, dropped: false, configured: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, config, filters.WithDiscoveryGracePeriod(0))
+			mock := filterstest.NewMockMongoDBClient(kept, dropped)
+			if err := cf.WarmFromMongoDB(context.Background(), mock); err != nil {
+				t.Fatalf("WarmFromMongoDB: %v", err)
+			}
+
+			for _, addresses := range tt.syncs {
+				mock.SetAddresses(addresses...)
+				if err := cf.WarmFromMongoDB(context.Background(), mock); err != nil {
+					t.Fatalf("WarmFromMongoDB: %v", err)
+				}
+			}
+
+			for addr, want := range tt.want {
+				if got := cf.Contains(addr); got != want {
+					t.Errorf("Contains(%s) = %v, want %v", addr.Hex(), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStartMongoDBSyncStopsMidInterval(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	errDown := errors.New("mongodb unavailable")
+	// A one minute interval so cancellation always lands between ticks
+	config := strings.Replace(fmt.Sprintf(syncConfig, 84532), `"intervalSeconds": 1`, `"intervalSeconds": 60`, 1)
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "successful sync"},
+		{name: "failed sync", err: errDown, wantErr: errDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, config)
+			mock := filterstest.NewMockMongoDBClient(addr)
+			mock.SetError(tt.err)
+
+			if !cf.LastSyncTime().IsZero() {
+				t.Fatalf("LastSyncTime set before the first sync")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := cf.StartMongoDBSync(ctx, mock)
+
+			waitFor(t, 5*time.Second, "initial sync", func() bool { return !cf.LastSyncTime().IsZero() })
+			if err := cf.LastSyncError(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LastSyncError = %v, want %v", err, tt.wantErr)
+			}
+
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("sync goroutine did not exit after cancellation")
+			}
+			if calls := mock.Calls(); calls != 1 {
+				t.Fatalf("got %d fetches, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestStartMongoDBSyncDisabled(t *testing.T) {
+	cf := newFilter(t, `{"network": "base-sepolia", "chainId": 84532}`)
+
+	select {
+	case <-cf.StartMongoDBSync(context.Background(), filterstest.NewMockMongoDBClient()):
+	default:
+		t.Fatalf("done channel not closed when sync is disabled")
+	}
+}
+
+func TestMongoDBSyncBackoff(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	errDown := errors.New("mongodb unavailable")
+
+	cf := newSyncFilter(t)
+	mock := filterstest.NewMockMongoDBClient(addr)
+
+	// Each step runs one sync and checks the delay before the next attempt
+	steps := []struct {
+		name         string
+		err          error
+		wantFailures int
+		wantDelay    time.Duration
+	}{
+		{name: "first failure", err: errDown, wantFailures: 1, wantDelay: 2 * time.Second},
+		{name: "second failure", err: errDown, wantFailures: 2, wantDelay: 4 * time.Second},
+		{name: "third failure", err: errDown, wantFailures: 3, wantDelay: 8 * time.Second},
+		{name: "success resets", wantFailures: 0, wantDelay: time.Second},
+		{name: "failure after reset", err: errDown, wantFailures: 1, wantDelay: 2 * time.Second},
+	}
+
+	for _, step := range steps {
+		mock.SetError(step.err)
+		err := cf.WarmFromMongoDB(context.Background(), mock)
+		if !errors.Is(err, step.err) {
+			t.Fatalf("%s: WarmFromMongoDB = %v, want %v", step.name, err, step.err)
+		}
+		if got := cf.ConsecutiveSyncFailures(); got != step.wantFailures {
+			t.Errorf("%s: ConsecutiveSyncFailures = %d, want %d", step.name, got, step.wantFailures)
+		}
+		if got := cf.NextSyncDelay(); got != step.wantDelay {
+			t.Errorf("%s: NextSyncDelay = %v, want %v", step.name, got, step.wantDelay)
+		}
+	}
+
+	// A long outage stops growing at the cap
+	mock.SetError(errDown)
+	for i := 0; i < 20; i++ {
+		_ = cf.WarmFromMongoDB(context.Background(), mock)
+	}
+	if got := cf.NextSyncDelay(); got != 30*time.Minute {
+		t.Errorf("NextSyncDelay after 20 failures = %v, want 30m", got)
+	}
+}
+
+func TestWarmFromMongoDB(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	errDown := errors.New("mongodb unavailable")
+	// A one minute interval so only the initial sync can run during the test
+	config := strings.Replace(fmt.Sprintf(syncConfig, 84532), `"intervalSeconds": 1`, `"intervalSeconds": 60`, 1)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantWatched bool
+		// wantCalls is the number of fetches once the sync loop has started
+		wantCalls int
+	}{
+		{name: "warmed", wantWatched: true, wantCalls: 1},
+		{name: "warm-up failed", err: errDown, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFilter(t, config)
+			mock := filterstest.NewMockMongoDBClient(addr)
+			mock.SetError(tt.err)
+
+			err := cf.WarmFromMongoDB(context.Background(), mock)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("WarmFromMongoDB = %v, want %v", err, tt.err)
+			}
+			// No waiting: the load must be complete once WarmFromMongoDB returns
+			if got := cf.Contains(addr); got != tt.wantWatched {
+				t.Fatalf("Contains after warm-up = %v, want %v", got, tt.wantWatched)
+			}
+			if got := cf.ShouldIndexLog(addr); got != tt.wantWatched {
+				t.Fatalf("ShouldIndexLog after warm-up = %v, want %v", got, tt.wantWatched)
+			}
+			if calls := mock.Calls(); calls != 1 {
+				t.Fatalf("got %d fetches during warm-up, want 1", calls)
+			}
+
+			mock.SetError(nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := cf.StartMongoDBSync(ctx, mock)
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			waitFor(t, 5*time.Second, "initial sync", func() bool { return mock.Calls() >= tt.wantCalls })
+			time.Sleep(100 * time.Millisecond)
+			if calls := mock.Calls(); calls != tt.wantCalls {
+				t.Fatalf("got %d fetches after starting the sync loop, want %d", calls, tt.wantCalls)
+			}
+			if !cf.Contains(addr) {
+				t.Fatalf("contract not watched after the sync loop started")
+			}
+		})
+	}
+}
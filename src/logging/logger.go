@@ -0,0 +1,127 @@
+// Package logging defines the structured logger used by the Dopamint
+// indexer components and its default human-readable implementation
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Logger is a leveled logger taking alternating key-value fields
+// *slog.Logger satisfies it, so any slog handler (e.g. JSON) can be used
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Default returns the default logger for a component, writing
+// "[Component] message key=value ..." lines to stdout at info level and above
+func Default(component string) Logger {
+	return New(os.Stdout, component, slog.LevelInfo)
+}
+
+// New returns a logger writing "[component] message key=value ..." lines to w
+// for records at or above level
+func New(w io.Writer, component string, level slog.Leveler) Logger {
+	return slog.New(&prefixHandler{
+		mu:     &sync.Mutex{},
+		w:      w,
+		prefix: "[" + component + "] ",
+		level:  level,
+	})
+}
+
+// prefixHandler is a slog.Handler producing the indexer's traditional
+// "[Component] message" output followed by logfmt-style fields
+type prefixHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	level  slog.Leveler
+	attrs  string // preformatted fields added with WithAttrs
+	group  string // key prefix of the current group
+}
+
+// Enabled reports whether records at level are written
+func (h *prefixHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle writes a single record as one line
+func (h *prefixHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString(h.prefix)
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&b, h.group, attr)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// WithAttrs returns a handler that includes attrs in every record
+func (h *prefixHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, attr := range attrs {
+		appendAttr(&b, h.group, attr)
+	}
+
+	clone := *h
+	clone.attrs = b.String()
+	return &clone
+}
+
+// WithGroup returns a handler that qualifies subsequent keys with name
+func (h *prefixHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group = h.group + name + "."
+	return &clone
+}
+
+// appendAttr writes " key=value", flattening groups and quoting values that
+// contain spaces or quotes
+func appendAttr(b *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			appendAttr(b, prefix, member)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(group)
+	b.WriteString(attr.Key)
+	b.WriteByte('=')
+	b.WriteString(value)
+}
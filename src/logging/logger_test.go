@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestLoggerOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(logger Logger)
+		level slog.Level
+		want  string
+	}{
+		{
+			name: "message with fields",
+			log:  func(logger Logger) { logger.Info("Synced contracts", "count", 3, "chain", "base") },
+			want: "[Test] Synced contracts count=3 chain=base\n",
+		},
+		{
+			name: "quoted values",
+			log:  func(logger Logger) { logger.Warn("Failed", "error", "connection refused", "empty", "") },
+			want: "[Test] Failed error=\"connection refused\" empty=\"\"\n",
+		},
+		{
+			name: "below level",
+			log:  func(logger Logger) { logger.Debug("Hidden", "key", "value") },
+			want: "",
+		},
+		{
+			name:  "debug enabled",
+			log:   func(logger Logger) { logger.Debug("Shown") },
+			level: slog.LevelDebug,
+			want:  "[Test] Shown\n",
+		},
+		{
+			name: "attrs and groups",
+			log: func(logger Logger) {
+				logger.(*slog.Logger).With("endpoint", "a").WithGroup("rpc").Error("Failed", "attempt", 2)
+			},
+			want: "[Test] Failed endpoint=a rpc.attempt=2\n",
+		},
+		{
+			name: "group attribute",
+			log:  func(logger Logger) { logger.Info("Stats", slog.Group("filter", "watched", 5)) },
+			want: "[Test] Stats filter.watched=5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The zero level is slog.LevelInfo
+			var buf bytes.Buffer
+			tt.log(New(&buf, "Test", tt.level))
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package server exposes a small HTTP endpoint for health checks and
+// introspection of a running indexer
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/database"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultCheckTimeout bounds the dependency calls made by a single request
+const defaultCheckTimeout = 5 * time.Second
+
+// MongoStatus is the part of the MongoDB client used by the status server
+type MongoStatus interface {
+	Ping(ctx context.Context) error
+	ContractStats(ctx context.Context, opts ...database.QueryOption) (*database.ContractStats, error)
+}
+
+// FilterStatus is the part of the contract filter used by the status server
+type FilterStatus interface {
+	FilterStats() filters.FilterStats
+	GetWatchedAddresses() []common.Address
+}
+
+// RPCStatus is the part of the RPC client used by the status server
+type RPCStatus interface {
+	GetBlockNumber(ctx context.Context) (uint64, error)
+}
+
+// StatusServer serves /healthz, /stats and /watched for a running indexer
+type StatusServer struct {
+	mongo  MongoStatus
+	filter FilterStatus
+	rpc    RPCStatus
+	logger logging.Logger
+	mux    *http.ServeMux
+}
+
+// StatusServerOption configures optional StatusServer behaviour
+type StatusServerOption func(*StatusServer)
+
+// WithStatusLogger sets the logger used by the status server
+func WithStatusLogger(logger logging.Logger) StatusServerOption {
+	return func(s *StatusServer) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// NewStatusServer creates a status server for the given dependencies
+// mongo may be nil when the indexer runs without MongoDB; it is then left out
+// of the health check and the stats
+func NewStatusServer(mongo MongoStatus, filter FilterStatus, rpc RPCStatus, opts ...StatusServerOption) *StatusServer {
+	s := &StatusServer{
+		mongo:  mongo,
+		filter: filter,
+		rpc:    rpc,
+		logger: logging.Default("Status"),
+		mux:    http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/stats", s.handleStats)
+	s.mux.HandleFunc("/watched", s.handleWatched)
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the status endpoints
+func (s *StatusServer) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe serves the status endpoints on addr until it fails
+func (s *StatusServer) ListenAndServe(addr string) error {
+	s.logger.Info("Status server listening", "addr", addr)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: defaultCheckTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+// healthResponse is the body returned by /healthz
+type healthResponse struct {
+	Status      string `json:"status"`
+	BlockNumber uint64 `json:"block_number,omitempty"`
+	MongoError  string `json:"mongo_error,omitempty"`
+	RPCError    string `json:"rpc_error,omitempty"`
+}
+
+// handleHealthz returns 200 when MongoDB answers a ping and the RPC head can
+// be read, and 503 otherwise
+func (s *StatusServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), defaultCheckTimeout)
+	defer cancel()
+
+	resp := healthResponse{Status: "ok"}
+	if s.mongo != nil {
+		if err := s.mongo.Ping(ctx); err != nil {
+			resp.MongoError = err.Error()
+		}
+	}
+
+	head, err := s.rpc.GetBlockNumber(ctx)
+	if err != nil {
+		resp.RPCError = err.Error()
+	} else {
+		resp.BlockNumber = head
+	}
+
+	status := http.StatusOK
+	if resp.MongoError != "" || resp.RPCError != "" {
+		resp.Status = "unhealthy"
+		status = http.StatusServiceUnavailable
+		s.logger.Warn("Health check failed", "mongoError", resp.MongoError, "rpcError", resp.RPCError)
+	}
+
+	s.writeJSON(w, status, resp)
+}
+
+// statsResponse is the body returned by /stats
+type statsResponse struct {
+	Filter filters.FilterStats     `json:"filter"`
+	Mongo  *database.ContractStats `json:"mongo,omitempty"`
+}
+
+// handleStats returns the filter and MongoDB statistics
+func (s *StatusServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	resp := statsResponse{Filter: s.filter.FilterStats()}
+	if s.mongo != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultCheckTimeout)
+		defer cancel()
+
+		stats, err := s.mongo.ContractStats(ctx)
+		if err != nil {
+			s.logger.Error("Failed to get MongoDB stats", "error", err)
+			http.Error(w, "failed to get mongodb stats", http.StatusServiceUnavailable)
+			return
+		}
+		resp.Mongo = stats
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleWatched returns the watched addresses in their stable order
+func (s *StatusServer) handleWatched(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	watched := s.filter.GetWatchedAddresses()
+	addresses := make([]string, len(watched))
+	for i, addr := range watched {
+		addresses[i] = addr.Hex()
+	}
+
+	s.writeJSON(w, http.StatusOK, addresses)
+}
+
+// allowGet rejects anything but GET and HEAD requests
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func (s *StatusServer) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("Failed to write response", "error", err)
+	}
+}
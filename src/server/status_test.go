@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/database"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeMongo struct {
+	pingErr  error
+	stats    *database.ContractStats
+	statsErr error
+}
+
+func (f *fakeMongo) Ping(ctx context.Context) error { return f.pingErr }
+
+func (f *fakeMongo) ContractStats(ctx context.Context, opts ...database.QueryOption) (*database.ContractStats, error) {
+	return f.stats, f.statsErr
+}
+
+type fakeFilter struct {
+	stats   filters.FilterStats
+	watched []common.Address
+}
+
+func (f *fakeFilter) FilterStats() filters.FilterStats { return f.stats }
+
+func (f *fakeFilter) GetWatchedAddresses() []common.Address { return f.watched }
+
+type fakeRPC struct {
+	head uint64
+	err  error
+}
+
+func (f *fakeRPC) GetBlockNumber(ctx context.Context) (uint64, error) { return f.head, f.err }
+
+func TestStatusServer(t *testing.T) {
+	watched := []common.Address{common.HexToAddress("0x00000000000000000000000000000000000000f1")}
+	stats := &database.ContractStats{TotalContracts: 4, ActiveContracts: 3}
+
+	tests := []struct {
+		name       string
+		mongo      MongoStatus
+		rpc        *fakeRPC
+		method     string
+		path       string
+		wantStatus int
+		check      func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "healthy",
+			mongo:      &fakeMongo{},
+			rpc:        &fakeRPC{head: 42},
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp healthResponse
+				decode(t, body, &resp)
+				if resp.Status != "ok" || resp.BlockNumber != 42 {
+					t.Errorf("got %+v, want ok at block 42", resp)
+				}
+			},
+		},
+		{
+			name:       "mongo down",
+			mongo:      &fakeMongo{pingErr: errors.New("no reachable servers")},
+			rpc:        &fakeRPC{head: 42},
+			path:       "/healthz",
+			wantStatus: http.StatusServiceUnavailable,
+			check: func(t *testing.T, body []byte) {
+				var resp healthResponse
+				decode(t, body, &resp)
+				if resp.Status != "unhealthy" || resp.MongoError == "" {
+					t.Errorf("got %+v, want unhealthy with a mongo error", resp)
+				}
+			},
+		},
+		{
+			name:       "rpc down without mongo",
+			rpc:        &fakeRPC{err: errors.New("dial refused")},
+			path:       "/healthz",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "stats",
+			mongo:      &fakeMongo{stats: stats},
+			rpc:        &fakeRPC{},
+			path:       "/stats",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp statsResponse
+				decode(t, body, &resp)
+				if resp.Mongo == nil || resp.Mongo.ActiveContracts != 3 || resp.Filter.TotalWatched != 1 {
+					t.Errorf("got %+v, want mongo and filter stats", resp)
+				}
+			},
+		},
+		{
+			name:       "stats with mongo failing",
+			mongo:      &fakeMongo{statsErr: errors.New("timeout")},
+			rpc:        &fakeRPC{},
+			path:       "/stats",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "watched",
+			rpc:        &fakeRPC{},
+			path:       "/watched",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var addresses []string
+				decode(t, body, &addresses)
+				if len(addresses) != 1 || addresses[0] != watched[0].Hex() {
+					t.Errorf("got %v, want %s", addresses, watched[0].Hex())
+				}
+			},
+		},
+		{
+			name:       "method not allowed",
+			rpc:        &fakeRPC{},
+			method:     http.MethodPost,
+			path:       "/watched",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &fakeFilter{stats: filters.FilterStats{TotalWatched: 1}, watched: watched}
+			s := NewStatusServer(tt.mongo, filter, tt.rpc, WithStatusLogger(logging.New(io.Discard, "test", slog.LevelDebug)))
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(method, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+// decode unmarshals a JSON response body into v
+func decode(t *testing.T, body []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", body, err)
+	}
+}
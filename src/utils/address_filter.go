@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UpdateAddressFilter replaces the address filter
+// Passing the set already in use is a no-op, so periodic syncs that find no
+// change neither log nor bump the filter generation
+// It is safe to call while logs are being fetched from other goroutines
+func (d *DopamintRPCClient) UpdateAddressFilter(addresses []common.Address) {
+	d.filterMu.Lock()
+	defer d.filterMu.Unlock()
+
+	if sameAddressSet(d.addressFilter, addresses) {
+		return
+	}
+
+	// Copied so the caller reusing its slice cannot change queries underneath us
+	d.addressFilter = append([]common.Address(nil), addresses...)
+	gen := d.bumpFilterLocked()
+	d.logger.Info("Updated address filter", "addresses", len(addresses), "generation", gen)
+}
+
+// ApplyAddressDiff adds and removes addresses from the address filter
+// Addresses already present are not added twice and an address both added
+// and removed ends up removed. The filter is only replaced, logged and its
+// generation bumped when the diff actually changes it
+func (d *DopamintRPCClient) ApplyAddressDiff(added, removed []common.Address) {
+	d.filterMu.Lock()
+	defer d.filterMu.Unlock()
+
+	removeSet := make(map[common.Address]bool, len(removed))
+	for _, addr := range removed {
+		removeSet[addr] = true
+	}
+
+	current := d.addressFilter
+	present := make(map[common.Address]bool, len(current)+len(added))
+	next := make([]common.Address, 0, len(current)+len(added))
+	removedCount := 0
+	for _, addr := range current {
+		if removeSet[addr] {
+			removedCount++
+			continue
+		}
+		present[addr] = true
+		next = append(next, addr)
+	}
+
+	addedCount := 0
+	for _, addr := range added {
+		if present[addr] || removeSet[addr] {
+			continue
+		}
+		present[addr] = true
+		next = append(next, addr)
+		addedCount++
+	}
+
+	if addedCount == 0 && removedCount == 0 {
+		return
+	}
+
+	// A new slice is installed rather than editing in place, since queries
+	// built earlier may still reference the previous one
+	d.addressFilter = next
+	gen := d.bumpFilterLocked()
+	d.logger.Info("Applied address filter diff", "added", addedCount, "removed", removedCount,
+		"addresses", len(next), "generation", gen)
+}
+
+// bumpFilterLocked advances the filter generation and wakes up everyone
+// waiting on filterChanged; caller must hold d.filterMu for writing
+func (d *DopamintRPCClient) bumpFilterLocked() uint64 {
+	close(d.filterChanged)
+	d.filterChanged = make(chan struct{})
+	return d.filterGen.Add(1)
+}
+
+// filterChanges returns a channel that is closed once the address filter
+// changes after the call
+func (d *DopamintRPCClient) filterChanges() <-chan struct{} {
+	d.filterMu.RLock()
+	defer d.filterMu.RUnlock()
+	return d.filterChanged
+}
+
+// watchedAddresses returns the current address filter
+// The returned slice is never modified in place, so it may be used after the
+// lock is released
+func (d *DopamintRPCClient) watchedAddresses() []common.Address {
+	d.filterMu.RLock()
+	defer d.filterMu.RUnlock()
+	return d.addressFilter
+}
+
+// FilterGeneration returns the current address filter generation, which
+// increases every time the filter changes
+func (d *DopamintRPCClient) FilterGeneration() uint64 {
+	return d.filterGen.Load()
+}
+
+// FilterChangedSince reports whether the address filter has changed since
+// generation gen was observed, e.g. so a subscription knows to re-subscribe
+func (d *DopamintRPCClient) FilterChangedSince(gen uint64) bool {
+	return d.filterGen.Load() != gen
+}
+
+// sameAddressSet reports whether a and b contain the same addresses,
+// ignoring order and duplicates
+func sameAddressSet(a, b []common.Address) bool {
+	set := make(map[common.Address]bool, len(a))
+	for _, addr := range a {
+		set[addr] = true
+	}
+
+	seen := make(map[common.Address]bool, len(b))
+	for _, addr := range b {
+		if !set[addr] {
+			return false
+		}
+		seen[addr] = true
+	}
+	return len(seen) == len(set)
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	addrA = common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	addrB = common.HexToAddress("0x0000000000000000000000000000000000000a02")
+	addrC = common.HexToAddress("0x0000000000000000000000000000000000000a03")
+)
+
+func TestSameAddressSet(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []common.Address
+		want bool
+	}{
+		{"both empty", nil, []common.Address{}, true},
+		{"reordered", []common.Address{addrA, addrB}, []common.Address{addrB, addrA}, true},
+		{"duplicates", []common.Address{addrA, addrA, addrB}, []common.Address{addrB, addrA}, true},
+		{"missing", []common.Address{addrA, addrB}, []common.Address{addrA}, false},
+		{"extra", []common.Address{addrA}, []common.Address{addrA, addrC}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameAddressSet(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameAddressSet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyAddressDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		added       []common.Address
+		removed     []common.Address
+		want        []common.Address
+		wantChanged bool
+	}{
+		{"add", []common.Address{addrC}, nil, []common.Address{addrA, addrB, addrC}, true},
+		{"remove", nil, []common.Address{addrA}, []common.Address{addrB}, true},
+		{"already present", []common.Address{addrA}, nil, []common.Address{addrA, addrB}, false},
+		{"removing unknown", nil, []common.Address{addrC}, []common.Address{addrA, addrB}, false},
+		{"added and removed", []common.Address{addrC}, []common.Address{addrC}, []common.Address{addrA, addrB}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFakeRPCClient(t, &fakeEthClient{}, []common.Address{addrA, addrB})
+			gen := d.FilterGeneration()
+
+			d.ApplyAddressDiff(tt.added, tt.removed)
+
+			if got := d.watchedAddresses(); !sameAddressSet(got, tt.want) || len(got) != len(tt.want) {
+				t.Errorf("watched = %v, want %v", got, tt.want)
+			}
+			if changed := d.FilterChangedSince(gen); changed != tt.wantChanged {
+				t.Errorf("FilterChangedSince() = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+// Run with -race to catch unsynchronized access to the address filter
+func TestAddressFilterConcurrentUpdateAndFetch(t *testing.T) {
+	fake := &fakeEthClient{filterLogs: func(ethereum.FilterQuery) ([]types.Log, error) { return nil, nil }}
+	d := newFakeRPCClient(t, fake, []common.Address{addrA})
+
+	// Every query must see one of the filters the updater installs, never a
+	// partially applied one
+	states := [][]common.Address{{addrA}, {addrA, addrB}, {addrB, addrC}}
+
+	const rounds = 200
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			d.UpdateAddressFilter(states[1])
+			d.ApplyAddressDiff([]common.Address{addrC}, []common.Address{addrA})
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				if _, err := d.GetFilteredLogs(context.Background(), big.NewInt(1), big.NewInt(2)); err != nil {
+					t.Errorf("GetFilteredLogs: %v", err)
+					return
+				}
+				d.FilterChangedSince(d.FilterGeneration())
+			}
+		}()
+	}
+	wg.Wait()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.logQueries) != 2*rounds {
+		t.Fatalf("made %d queries, want %d", len(fake.logQueries), 2*rounds)
+	}
+	for _, query := range fake.logQueries {
+		valid := false
+		for _, state := range states {
+			if sameAddressSet(query.Addresses, state) && len(query.Addresses) == len(state) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			t.Fatalf("query used a torn address filter %v", query.Addresses)
+		}
+	}
+}
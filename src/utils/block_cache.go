@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"container/list"
+	"context"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// defaultTimestampCacheSize is the number of block timestamps kept by default
+const defaultTimestampCacheSize = 4096
+
+// timestampEntry is one cached block timestamp
+type timestampEntry struct {
+	number    uint64
+	timestamp time.Time
+}
+
+// timestampCache is a bounded LRU cache of block timestamps by block number
+type timestampCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[uint64]*list.Element
+}
+
+// newTimestampCache creates a cache holding up to size timestamps
+func newTimestampCache(size int) *timestampCache {
+	if size <= 0 {
+		size = defaultTimestampCacheSize
+	}
+	return &timestampCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[uint64]*list.Element, size),
+	}
+}
+
+// get returns the cached timestamp of a block, marking it recently used
+func (c *timestampCache) get(number uint64) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[number]
+	if !ok {
+		return time.Time{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*timestampEntry).timestamp, true
+}
+
+// add caches the timestamp of a block, evicting the least recently used one
+// when the cache is full
+func (c *timestampCache) add(number uint64, timestamp time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[number]; ok {
+		elem.Value.(*timestampEntry).timestamp = timestamp
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[number] = c.order.PushFront(&timestampEntry{number: number, timestamp: timestamp})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*timestampEntry).number)
+	}
+}
+
+// WithTimestampCacheSize sets how many block timestamps GetBlockTimestamp keeps
+func WithTimestampCacheSize(size int) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.timestamps = newTimestampCache(size)
+	}
+}
+
+// GetBlockTimestamp returns the timestamp of a block, fetching only its header
+// Timestamps are cached, so repeated lookups for the same block are free
+func (d *DopamintRPCClient) GetBlockTimestamp(ctx context.Context, number uint64) (time.Time, error) {
+	if timestamp, ok := d.timestamps.get(number); ok {
+		return timestamp, nil
+	}
+
+	var timestamp time.Time
+	err := d.call(ctx, "HeaderByNumber", func(client EthClient) error {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return err
+		}
+		timestamp = time.Unix(int64(header.Time), 0).UTC()
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	d.timestamps.add(number, timestamp)
+	return timestamp, nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestGetBlockTimestampCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		cacheSize   int
+		lookups     []uint64
+		wantFetches map[uint64]int
+	}{
+		{
+			name:        "repeated lookup is cached",
+			cacheSize:   4,
+			lookups:     []uint64{10, 10, 10},
+			wantFetches: map[uint64]int{10: 1},
+		},
+		{
+			name:        "distinct blocks are fetched once each",
+			cacheSize:   4,
+			lookups:     []uint64{10, 11, 10, 11},
+			wantFetches: map[uint64]int{10: 1, 11: 1},
+		},
+		{
+			name:      "least recently used block is evicted",
+			cacheSize: 2,
+			// 10 is used again before 12 is added, so 11 is evicted
+			lookups:     []uint64{10, 11, 10, 12, 10, 11},
+			wantFetches: map[uint64]int{10: 1, 11: 2, 12: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			fetches := make(map[uint64]int)
+			fake := &fakeEthClient{header: func(number uint64) (*types.Header, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				fetches[number]++
+				return &types.Header{Time: 1_700_000_000 + number}, nil
+			}}
+			d := newFakeRPCClient(t, fake, nil, WithTimestampCacheSize(tt.cacheSize))
+
+			for _, number := range tt.lookups {
+				got, err := d.GetBlockTimestamp(context.Background(), number)
+				if err != nil {
+					t.Fatalf("GetBlockTimestamp(%d): %v", number, err)
+				}
+				if want := time.Unix(int64(1_700_000_000+number), 0).UTC(); !got.Equal(want) {
+					t.Errorf("GetBlockTimestamp(%d) = %v, want %v", number, got, want)
+				}
+			}
+			if !reflect.DeepEqual(fetches, tt.wantFetches) {
+				t.Errorf("header fetches = %v, want %v", fetches, tt.wantFetches)
+			}
+		})
+	}
+}
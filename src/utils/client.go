@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// EthClient is the part of an Ethereum client DopamintRPCClient calls
+// *ethclient.Client satisfies it; a fake can be injected with WithDialer
+type EthClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	Close()
+}
+
+var _ EthClient = (*ethclient.Client)(nil)
+
+// Dialer connects to the RPC endpoint at url
+type Dialer func(ctx context.Context, url string) (EthClient, error)
+
+// dialEthClient is the default Dialer, connecting with ethclient
+func dialEthClient(ctx context.Context, url string) (EthClient, error) {
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// WithDialer sets how endpoints are connected to, both initially and when
+// re-dialed after a dropped subscription, e.g. to use an in-memory client
+func WithDialer(dial Dialer) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		if dial != nil {
+			d.dial = dial
+		}
+	}
+}
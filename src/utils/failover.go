@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+)
+
+// rpcEndpoint tracks the health of a single RPC endpoint in the pool
+type rpcEndpoint struct {
+	url            string
+	client         EthClient
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// call runs fn against the endpoint pool, failing over between endpoints and
+// retrying the whole pass according to the retry policy
+func (d *DopamintRPCClient) call(ctx context.Context, op string, fn func(client EthClient) error) error {
+	return d.withRetry(ctx, op, func() error {
+		return d.tryEndpoints(ctx, op, fn)
+	})
+}
+
+// tryEndpoints runs fn on each endpoint in priority order until one succeeds
+// Permanent errors are returned immediately since another endpoint would
+// reject the request the same way; any other error, including unrecognized
+// ones, moves on to the next endpoint
+func (d *DopamintRPCClient) tryEndpoints(ctx context.Context, op string, fn func(client EthClient) error) error {
+	var lastErr error
+	for i, ep := range d.orderedEndpoints() {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s cancelled: %w", op, err)
+		}
+		if err := d.waitRateLimit(ctx, op); err != nil {
+			return err
+		}
+
+		err := fn(d.endpointClient(ep))
+		if err == nil {
+			d.markSuccess(ep)
+			if i > 0 {
+				d.logger.Warn("Served by fallback endpoint", "op", op, "endpoint", ep.url)
+			}
+			return nil
+		}
+
+		// A cancelled call says nothing about the endpoint's health, and a
+		// range error is handled by the caller shrinking the range
+		if isPermanentError(err) || d.isRangeError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		d.markFailure(ep)
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// orderedEndpoints returns healthy endpoints in priority order, or every
+// endpoint when none is currently healthy
+func (d *DopamintRPCClient) orderedEndpoints() []*rpcEndpoint {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*rpcEndpoint, 0, len(d.endpoints))
+	for _, ep := range d.endpoints {
+		if now.After(ep.unhealthyUntil) {
+			healthy = append(healthy, ep)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return d.endpoints
+	}
+	return healthy
+}
+
+// markSuccess resets the failure count of an endpoint and records it as the
+// one that served the latest request
+func (d *DopamintRPCClient) markSuccess(ep *rpcEndpoint) {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+
+	ep.failures = 0
+	ep.unhealthyUntil = time.Time{}
+	d.lastEndpoint = ep.url
+}
+
+// markFailure records a failed request and puts the endpoint on cooldown
+// once it reaches the failover threshold
+func (d *DopamintRPCClient) markFailure(ep *rpcEndpoint) {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+
+	ep.failures++
+	if d.failoverThreshold > 0 && ep.failures >= d.failoverThreshold {
+		ep.unhealthyUntil = time.Now().Add(d.failoverCooldown)
+		ep.failures = 0
+		d.logger.Warn("Endpoint marked unhealthy", "endpoint", ep.url, "cooldown", d.failoverCooldown)
+	}
+}
+
+// endpointClient returns the current eth client of an endpoint, which
+// changes when the endpoint is re-dialed
+func (d *DopamintRPCClient) endpointClient(ep *rpcEndpoint) EthClient {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+	return ep.client
+}
+
+// redial replaces the connection of an endpoint with a fresh one, closing the
+// old connection. Calls in flight on the old connection fail and are retried
+func (d *DopamintRPCClient) redial(ctx context.Context, ep *rpcEndpoint) (EthClient, error) {
+	client, err := d.dial(ctx, ep.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redial %s: %w: %w", ep.url, errdefs.ErrConnection, err)
+	}
+
+	d.endpointMu.Lock()
+	old := ep.client
+	ep.client = client
+	d.endpointMu.Unlock()
+
+	old.Close()
+	d.logger.Info("Redialed RPC endpoint", "endpoint", ep.url)
+
+	return client, nil
+}
+
+// LastEndpoint returns the URL of the endpoint that served the latest request
+func (d *DopamintRPCClient) LastEndpoint() string {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+	return d.lastEndpoint
+}
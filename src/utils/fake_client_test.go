@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errNotImplemented = errors.New("not implemented by fake client")
+
+// fakeEthClient is an in-memory EthClient; unset hooks fail with errNotImplemented
+type fakeEthClient struct {
+	mu          sync.Mutex
+	blockNumber func() (uint64, error)
+	header      func(number uint64) (*types.Header, error)
+	filterLogs  func(query ethereum.FilterQuery) ([]types.Log, error)
+	logQueries  []ethereum.FilterQuery
+	subQueries  []ethereum.FilterQuery
+	subs        []*fakeSubscription
+	subscribe   func(query ethereum.FilterQuery) error
+	codeAt      func(account common.Address, block uint64) ([]byte, error)
+	receipt     func(txHash common.Hash) (*types.Receipt, error)
+	transaction func(hash common.Hash) (*types.Transaction, bool, error)
+	sender      func(tx *types.Transaction, block common.Hash, index uint) (common.Address, error)
+	closed      bool
+}
+
+var _ EthClient = (*fakeEthClient)(nil)
+
+func (f *fakeEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	if f.blockNumber == nil {
+		return 0, errNotImplemented
+	}
+	return f.blockNumber()
+}
+
+func (f *fakeEthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return nil, errNotImplemented
+}
+
+func (f *fakeEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if f.header == nil {
+		return nil, errNotImplemented
+	}
+	return f.header(number.Uint64())
+}
+
+func (f *fakeEthClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	f.logQueries = append(f.logQueries, query)
+	f.mu.Unlock()
+
+	if f.filterLogs == nil {
+		return nil, errNotImplemented
+	}
+	return f.filterLogs(query)
+}
+
+func (f *fakeEthClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subQueries = append(f.subQueries, query)
+	if f.subscribe != nil {
+		if err := f.subscribe(query); err != nil {
+			return nil, err
+		}
+	}
+	sub := &fakeSubscription{ch: ch, err: make(chan error, 1), done: make(chan struct{})}
+	f.subs = append(f.subs, sub)
+	return sub, nil
+}
+
+func (f *fakeEthClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if f.transaction == nil {
+		return nil, false, errNotImplemented
+	}
+	return f.transaction(hash)
+}
+
+func (f *fakeEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if f.receipt == nil {
+		return nil, errNotImplemented
+	}
+	return f.receipt(txHash)
+}
+
+func (f *fakeEthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	if f.sender == nil {
+		return common.Address{}, errNotImplemented
+	}
+	return f.sender(tx, block, index)
+}
+
+func (f *fakeEthClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if f.codeAt == nil {
+		return nil, errNotImplemented
+	}
+	return f.codeAt(account, blockNumber.Uint64())
+}
+
+func (f *fakeEthClient) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+}
+
+// subscriptions returns the subscriptions opened so far and their queries
+func (f *fakeEthClient) subscriptions() ([]*fakeSubscription, []ethereum.FilterQuery) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*fakeSubscription(nil), f.subs...), append([]ethereum.FilterQuery(nil), f.subQueries...)
+}
+
+// fakeSubscription is an upstream subscription the test can drop
+type fakeSubscription struct {
+	ch       chan<- types.Log
+	err      chan error
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (s *fakeSubscription) Unsubscribe() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+func (s *fakeSubscription) Err() <-chan error {
+	return s.err
+}
+
+// unsubscribed reports whether Unsubscribe was called
+func (s *fakeSubscription) unsubscribed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// newFakeRPCClient creates a client with a single endpoint served by fake
+func newFakeRPCClient(t *testing.T, fake *fakeEthClient, addresses []common.Address, opts ...RPCClientOption) *DopamintRPCClient {
+	t.Helper()
+	return newFakeRPCClientPool(t, []string{"ws://primary"}, map[string]*fakeEthClient{"ws://primary": fake}, addresses, opts...)
+}
+
+// newFakeRPCClientPool creates a client over urls in priority order
+func newFakeRPCClientPool(t *testing.T, urls []string, clients map[string]*fakeEthClient, addresses []common.Address, opts ...RPCClientOption) *DopamintRPCClient {
+	t.Helper()
+
+	dial := func(ctx context.Context, url string) (EthClient, error) {
+		client, ok := clients[url]
+		if !ok {
+			return nil, errors.New("unknown endpoint " + url)
+		}
+		return client, nil
+	}
+	opts = append([]RPCClientOption{
+		WithDialer(dial),
+		WithLogger(logging.New(io.Discard, "test", slog.LevelDebug)),
+	}, opts...)
+
+	d, err := NewDopamintRPCClientPool(urls, addresses, true, opts...)
+	if err != nil {
+		t.Fatalf("NewDopamintRPCClientPool: %v", err)
+	}
+	return d
+}
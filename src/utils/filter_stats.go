@@ -0,0 +1,64 @@
+package utils
+
+import "sync"
+
+// defaultFilterStatsSamples is the ring buffer size used when
+// NewFilterStatsTracker is given a non-positive capacity
+const defaultFilterStatsSamples = 1024
+
+// filterSample is the outcome of one log fetch
+type filterSample struct {
+	received    int64
+	afterFilter int64
+	blocks      int64
+}
+
+// FilterStatsTracker keeps the most recent per-batch filter samples so filter
+// efficiency can be measured over a recent window instead of lifetime totals,
+// which early backfill would otherwise dominate
+type FilterStatsTracker struct {
+	mu      sync.Mutex
+	samples []filterSample
+	next    int
+	count   int
+}
+
+// NewFilterStatsTracker creates a tracker keeping up to capacity samples
+func NewFilterStatsTracker(capacity int) *FilterStatsTracker {
+	if capacity <= 0 {
+		capacity = defaultFilterStatsSamples
+	}
+	return &FilterStatsTracker{samples: make([]filterSample, capacity)}
+}
+
+// Record adds the outcome of one log fetch, overwriting the oldest sample
+// once the buffer is full
+func (t *FilterStatsTracker) Record(received, afterFilter, blocks int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples[t.next] = filterSample{received: received, afterFilter: afterFilter, blocks: blocks}
+	t.next = (t.next + 1) % len(t.samples)
+	if t.count < len(t.samples) {
+		t.count++
+	}
+}
+
+// RollingEfficiency returns the filter efficiency over the most recent samples
+// covering at least windowBlocks blocks, or all retained samples if they cover
+// fewer blocks
+// The result uses the same scale as CalculateFilterEfficiency
+func (t *FilterStatsTracker) RollingEfficiency(windowBlocks int) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stats LogFilterStats
+	for i := 0; i < t.count && stats.BlocksProcessed < int64(windowBlocks); i++ {
+		sample := t.samples[(t.next-1-i+len(t.samples))%len(t.samples)]
+		stats.TotalLogsReceived += sample.received
+		stats.LogsAfterFilter += sample.afterFilter
+		stats.BlocksProcessed += sample.blocks
+	}
+
+	return CalculateFilterEfficiency(stats)
+}
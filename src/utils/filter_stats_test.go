@@ -0,0 +1,61 @@
+package utils
+
+import "testing"
+
+func TestFilterStatsTrackerRollingEfficiency(t *testing.T) {
+	// Each sample is {received, afterFilter, blocks}
+	backfill := [3]int64{1000, 900, 500} // 10% efficient
+	live := [3]int64{100, 10, 100}       // 90% efficient
+
+	tests := []struct {
+		name     string
+		capacity int
+		samples  [][3]int64
+		window   int
+		want     float64
+	}{
+		{name: "no samples", capacity: 4, window: 1000, want: 0},
+		{name: "window covers everything", capacity: 4, samples: [][3]int64{live, live}, window: 1000, want: 90},
+		{
+			name:     "window drops older samples",
+			capacity: 8,
+			samples:  [][3]int64{backfill, live, live},
+			window:   200,
+			want:     90,
+		},
+		{
+			name:     "window reaching into older samples",
+			capacity: 8,
+			samples:  [][3]int64{backfill, live},
+			window:   101,
+			want:     (1 - 910.0/1100) * 100,
+		},
+		{
+			name:     "full buffer overwrites the oldest sample",
+			capacity: 2,
+			samples:  [][3]int64{backfill, live, live},
+			window:   10000,
+			want:     90,
+		},
+		{
+			name:     "default capacity",
+			capacity: 0,
+			samples:  [][3]int64{backfill, live},
+			window:   100,
+			want:     90,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewFilterStatsTracker(tt.capacity)
+			for _, s := range tt.samples {
+				tracker.Record(s[0], s[1], s[2])
+			}
+
+			if got := tracker.RollingEfficiency(tt.window); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("RollingEfficiency(%d) = %v, want %v", tt.window, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// followChunkSize is the window size FollowLogs uses to catch up on ranges
+// spanning many blocks
+const followChunkSize = 2000
+
+// FollowLogs tails the chain, fetching filtered logs from startBlock up to
+// head - confirmations and sending each non-empty batch to out in block order
+// After every fetch the cursor advances past the last fetched block; when no
+// new confirmed blocks are available it waits pollInterval before polling
+// again. Fetch errors are logged and retried on the next poll, resuming after
+// the last block fetched successfully. It returns once ctx is cancelled
+func (d *DopamintRPCClient) FollowLogs(ctx context.Context, startBlock uint64, confirmations uint64, pollInterval time.Duration, out chan<- []types.Log) error {
+	if pollInterval <= 0 {
+		return fmt.Errorf("invalid poll interval: %s", pollInterval)
+	}
+
+	next := startBlock
+	d.logger.Info("Following chain head", "startBlock", startBlock, "confirmations", confirmations, "pollInterval", pollInterval)
+
+	for {
+		caughtUp, err := d.followStep(ctx, &next, confirmations, out)
+		if ctx.Err() != nil {
+			d.logger.Info("Stopped following chain head", "nextBlock", next)
+			return ctx.Err()
+		}
+		if err != nil {
+			d.logger.Warn("Failed to follow chain head, retrying", "nextBlock", next, "error", err)
+		}
+		if !caughtUp && err == nil {
+			// More confirmed blocks may already be available
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Stopped following chain head", "nextBlock", next)
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// followStep fetches the logs from *next up to the latest confirmed block,
+// sends them to out and advances *next past the fetched blocks
+// caughtUp reports whether there were no new confirmed blocks to fetch
+func (d *DopamintRPCClient) followStep(ctx context.Context, next *uint64, confirmations uint64, out chan<- []types.Log) (caughtUp bool, err error) {
+	head, err := d.GetBlockNumber(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch chain head: %w", err)
+	}
+
+	safe := safeBlockNumber(head, confirmations)
+	if safe < *next {
+		return true, nil
+	}
+
+	result, fetchErr := d.GetFilteredLogsChunked(ctx, new(big.Int).SetUint64(*next), new(big.Int).SetUint64(safe), big.NewInt(followChunkSize))
+	if result == nil {
+		return false, fetchErr
+	}
+
+	// A failed fetch still returns the logs of the windows before the failure
+	if logs := normalizeLogs(result.Logs); len(logs) > 0 {
+		select {
+		case out <- logs:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	*next = result.NextBlock.Uint64()
+
+	return false, fetchErr
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestFollowLogs(t *testing.T) {
+	watched := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+
+	tests := []struct {
+		name      string
+		heads     []uint64 // head returned by successive polls, the last one repeating
+		failFetch int      // number of initial fetches that fail
+		wantLast  uint64   // last block expected with 2 confirmations
+	}{
+		{name: "head advances across polls", heads: []uint64{105, 105, 110, 110, 120}, wantLast: 118},
+		{name: "head behind the start block", heads: []uint64{95, 101, 104}, wantLast: 102},
+		{name: "resumes after a failed fetch", heads: []uint64{105, 110}, failFetch: 2, wantLast: 108},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			polls, fetches := 0, 0
+			fake := &fakeEthClient{
+				blockNumber: func() (uint64, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					head := tt.heads[len(tt.heads)-1]
+					if polls < len(tt.heads) {
+						head = tt.heads[polls]
+					}
+					polls++
+					return head, nil
+				},
+				filterLogs: func(query ethereum.FilterQuery) ([]types.Log, error) {
+					mu.Lock()
+					fetches++
+					failed := fetches <= tt.failFetch
+					mu.Unlock()
+					if failed {
+						return nil, errors.New("connection reset by peer")
+					}
+
+					var logs []types.Log
+					for block := query.FromBlock.Uint64(); block <= query.ToBlock.Uint64(); block++ {
+						logs = append(logs, types.Log{Address: watched, BlockNumber: block, BlockHash: common.BigToHash(new(big.Int).SetUint64(block))})
+					}
+					return logs, nil
+				},
+			}
+			d := newFakeRPCClient(t, fake, []common.Address{watched}, WithRetryConfig(RetryConfig{MaxAttempts: 1}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			out := make(chan []types.Log)
+			done := make(chan error, 1)
+			go func() { done <- d.FollowLogs(ctx, 100, 2, 5*time.Millisecond, out) }()
+
+			next := uint64(100)
+			timeout := time.After(5 * time.Second)
+			for next <= tt.wantLast {
+				select {
+				case batch := <-out:
+					for _, log := range batch {
+						if log.BlockNumber != next {
+							t.Fatalf("got block %d, want %d", log.BlockNumber, next)
+						}
+						next++
+					}
+				case <-timeout:
+					t.Fatalf("timed out at block %d, want up to %d", next, tt.wantLast)
+				}
+			}
+
+			// Nothing beyond head - confirmations is emitted
+			select {
+			case batch := <-out:
+				t.Fatalf("unexpected batch from block %d", batch[0].BlockNumber)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("FollowLogs = %v, want context.Canceled", err)
+			}
+		})
+	}
+}
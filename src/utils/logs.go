@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// addressGroups splits addresses into consecutive groups of at most size
+// addresses. A non-positive size yields a single group
+func addressGroups(addresses []common.Address, size int) [][]common.Address {
+	if size <= 0 || len(addresses) <= size {
+		return [][]common.Address{addresses}
+	}
+
+	groups := make([][]common.Address, 0, (len(addresses)+size-1)/size)
+	for start := 0; start < len(addresses); start += size {
+		end := start + size
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		groups = append(groups, addresses[start:end])
+	}
+	return groups
+}
+
+// logKey identifies a log within the chain
+type logKey struct {
+	blockHash common.Hash
+	index     uint
+}
+
+// normalizeLogs sorts logs gathered from several requests by block number and
+// log index, dropping duplicates of the same (block hash, log index)
+// Every fetch path that combines multiple requests passes its result through
+// it, so callers always receive an ordered stream without repeats
+// The input slice is reordered in place
+func normalizeLogs(logs []types.Log) []types.Log {
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		if logs[i].Index != logs[j].Index {
+			return logs[i].Index < logs[j].Index
+		}
+		// Same position in competing blocks after a reorg; order by hash to stay deterministic
+		return bytes.Compare(logs[i].BlockHash.Bytes(), logs[j].BlockHash.Bytes()) < 0
+	})
+
+	seen := make(map[logKey]bool, len(logs))
+	unique := logs[:0]
+	for _, log := range logs {
+		key := logKey{blockHash: log.BlockHash, index: log.Index}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, log)
+	}
+	return unique
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestAddressGroups(t *testing.T) {
+	addresses := make([]common.Address, 5)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	tests := []struct {
+		name  string
+		size  int
+		sizes []int
+	}{
+		{"unlimited", 0, []int{5}},
+		{"fits", 5, []int{5}},
+		{"even split", 1, []int{1, 1, 1, 1, 1}},
+		{"remainder", 2, []int{2, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := addressGroups(addresses, tt.size)
+			if len(groups) != len(tt.sizes) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.sizes))
+			}
+
+			var flat []common.Address
+			for i, group := range groups {
+				if len(group) != tt.sizes[i] {
+					t.Errorf("group %d has %d addresses, want %d", i, len(group), tt.sizes[i])
+				}
+				flat = append(flat, group...)
+			}
+			for i := range addresses {
+				if flat[i] != addresses[i] {
+					t.Fatalf("address %d = %s, want %s", i, flat[i].Hex(), addresses[i].Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeLogs(t *testing.T) {
+	hashA := common.HexToHash("0x0a")
+	hashB := common.HexToHash("0x0b")
+
+	type pos struct {
+		block uint64
+		index uint
+		hash  common.Hash
+	}
+
+	tests := []struct {
+		name string
+		logs []pos
+		want []pos
+	}{
+		{
+			name: "orders by block and index",
+			logs: []pos{{2, 0, hashA}, {1, 3, hashA}, {1, 1, hashA}},
+			want: []pos{{1, 1, hashA}, {1, 3, hashA}, {2, 0, hashA}},
+		},
+		{
+			name: "drops duplicates",
+			logs: []pos{{1, 1, hashA}, {1, 1, hashA}, {1, 2, hashA}},
+			want: []pos{{1, 1, hashA}, {1, 2, hashA}},
+		},
+		{
+			name: "shuffled with duplicates",
+			logs: []pos{{3, 0, hashB}, {1, 2, hashA}, {3, 0, hashB}, {1, 0, hashA}, {1, 2, hashA}},
+			want: []pos{{1, 0, hashA}, {1, 2, hashA}, {3, 0, hashB}},
+		},
+		{
+			name: "keeps the same position in competing blocks",
+			logs: []pos{{1, 1, hashB}, {1, 1, hashA}},
+			want: []pos{{1, 1, hashA}, {1, 1, hashB}},
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := make([]types.Log, len(tt.logs))
+			for i, p := range tt.logs {
+				logs[i] = types.Log{BlockNumber: p.block, Index: p.index, BlockHash: p.hash}
+			}
+
+			got := normalizeLogs(logs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d logs, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].BlockNumber != want.block || got[i].Index != want.index || got[i].BlockHash != want.hash {
+					t.Errorf("log %d = (%d, %d, %s), want (%d, %d, %s)", i,
+						got[i].BlockNumber, got[i].Index, got[i].BlockHash.Hex(), want.block, want.index, want.hash.Hex())
+				}
+			}
+		})
+	}
+}
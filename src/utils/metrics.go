@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsRecorder exposes log filtering statistics as Prometheus metrics
+type MetricsRecorder struct {
+	mu       sync.Mutex
+	stats    LogFilterStats
+	baseline LogFilterStats // fetches where the unfiltered log count is known
+
+	logsReceived     prometheus.Counter
+	logsAfterFilter  prometheus.Counter
+	blocksProcessed  prometheus.Counter
+	contractsWatched prometheus.Gauge
+	filterEfficiency prometheus.Gauge
+}
+
+// NewMetricsRecorder creates a recorder registered with the default Prometheus registry
+func NewMetricsRecorder() *MetricsRecorder {
+	return NewMetricsRecorderWithRegisterer(prometheus.DefaultRegisterer)
+}
+
+// NewMetricsRecorderWithRegisterer creates a recorder registered with reg
+func NewMetricsRecorderWithRegisterer(reg prometheus.Registerer) *MetricsRecorder {
+	m := &MetricsRecorder{
+		logsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "dopamint",
+			Name:      "logs_received_total",
+			Help:      "Total number of logs received from the RPC provider.",
+		}),
+		logsAfterFilter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "dopamint",
+			Name:      "logs_after_filter_total",
+			Help:      "Total number of logs from watched contracts.",
+		}),
+		blocksProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "dopamint",
+			Name:      "blocks_processed_total",
+			Help:      "Total number of blocks whose logs have been fetched.",
+		}),
+		contractsWatched: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "dopamint",
+			Name:      "contracts_watched",
+			Help:      "Number of contract addresses in the log filter.",
+		}),
+		filterEfficiency: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "dopamint",
+			Name:      "filter_efficiency_percent",
+			Help:      "Percentage of logs dropped by the contract filter, measured on fetches with an unfiltered baseline.",
+		}),
+	}
+
+	reg.MustRegister(m.logsReceived, m.logsAfterFilter, m.blocksProcessed, m.contractsWatched, m.filterEfficiency)
+	return m
+}
+
+// ObserveLogs records the outcome of one log fetch
+// Logs filtered by address at the RPC arrive already filtered, so received
+// equals afterFilter for them; filter efficiency is tracked separately with
+// ObserveBaseline
+func (m *MetricsRecorder) ObserveLogs(received, afterFilter int, blocks int64, contractsWatched int) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats.TotalLogsReceived += int64(received)
+	m.stats.LogsAfterFilter += int64(afterFilter)
+	m.stats.BlocksProcessed += blocks
+	m.stats.ContractsWatched = contractsWatched
+
+	m.logsReceived.Add(float64(received))
+	m.logsAfterFilter.Add(float64(afterFilter))
+	m.blocksProcessed.Add(float64(blocks))
+	m.contractsWatched.Set(float64(contractsWatched))
+}
+
+// ObserveBaseline records a fetch for which the unfiltered log count is
+// known, with matched of the unfiltered logs coming from watched contracts,
+// and updates the filter efficiency from all baselines recorded so far
+func (m *MetricsRecorder) ObserveBaseline(unfiltered, matched int) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.baseline.TotalLogsReceived += int64(unfiltered)
+	m.baseline.LogsAfterFilter += int64(matched)
+	m.filterEfficiency.Set(CalculateFilterEfficiency(m.baseline))
+}
+
+// FilterEfficiency returns the filter efficiency measured on the baselines
+// recorded so far, and false if none has been recorded yet
+func (m *MetricsRecorder) FilterEfficiency() (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.baseline.TotalLogsReceived == 0 {
+		return 0, false
+	}
+	return CalculateFilterEfficiency(m.baseline), true
+}
+
+// Stats returns the cumulative statistics recorded so far
+func (m *MetricsRecorder) Stats() LogFilterStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BuildFilterQuery builds the log query for a block range from the current
+// state of a contract filter: its watched addresses, unless filtering is
+// disabled, and the known Dopamint event signatures as topic0, matching
+// WithEventTopics without arguments
+func BuildFilterQuery(cf *filters.ContractFilter, fromBlock, toBlock *big.Int) ethereum.FilterQuery {
+	query := ethereum.FilterQuery{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Topics:    [][]common.Hash{filters.GetEventSignatures()},
+	}
+
+	if cf.IsEnabled() {
+		query.Addresses = cf.GetWatchedAddresses()
+	}
+
+	return query
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBuildFilterQuery(t *testing.T) {
+	factory := common.HexToAddress("0x00000000000000000000000000000000000000f1")
+	payment := common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	nft := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+
+	tests := []struct {
+		name          string
+		enabled       bool
+		wantAddresses []common.Address
+	}{
+		{name: "filtering enabled", enabled: true, wantAddresses: []common.Address{factory, payment, nft}},
+		{name: "filtering disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := `{
+				"contracts": {
+					"factory": {"address": "` + factory.Hex() + `"},
+					"payment": {"address": "` + payment.Hex() + `"}
+				},
+				"eventFilters": {"enabled": ` + strconv.FormatBool(tt.enabled) + `}
+			}`
+			path := filepath.Join(t.TempDir(), "contracts.json")
+			if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cf, err := filters.NewContractFilter(path, filters.WithFilterLogger(logging.New(io.Discard, "test", slog.LevelDebug)))
+			if err != nil {
+				t.Fatalf("NewContractFilter: %v", err)
+			}
+			cf.AddNFTContract(nft)
+
+			query := BuildFilterQuery(cf, big.NewInt(100), big.NewInt(200))
+
+			if query.FromBlock.Int64() != 100 || query.ToBlock.Int64() != 200 {
+				t.Errorf("range = %s-%s, want 100-200", query.FromBlock, query.ToBlock)
+			}
+			if !reflect.DeepEqual(query.Addresses, tt.wantAddresses) {
+				t.Errorf("Addresses = %v, want %v", query.Addresses, tt.wantAddresses)
+			}
+			if len(query.Topics) != 1 || !reflect.DeepEqual(query.Topics[0], filters.GetEventSignatures()) {
+				t.Fatalf("Topics = %v, want [%v]", query.Topics, filters.GetEventSignatures())
+			}
+			for _, sig := range []common.Hash{filters.NFTContractCreatedSignature, filters.TransferSignature, filters.PaymentReceivedSignature} {
+				found := false
+				for _, topic := range query.Topics[0] {
+					found = found || topic == sig
+				}
+				if !found {
+					t.Errorf("topic %s missing from the query", sig.Hex())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit caps outgoing RPC requests at rps requests per second with
+// bursts of up to burst requests, counting every attempt including retries
+// and failovers. A non-positive rps disables rate limiting
+func WithRateLimit(rps float64, burst int) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		if rps <= 0 {
+			d.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		d.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// waitRateLimit blocks until the rate limiter allows another request
+// It fails immediately if the wait would outlast the context deadline
+func (d *DopamintRPCClient) waitRateLimit(ctx context.Context, op string) error {
+	if d.limiter == nil {
+		return nil
+	}
+
+	if err := d.limiter.Wait(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s cancelled while waiting for rate limiter: %w", op, ctxErr)
+		}
+		return fmt.Errorf("%s rate limiter wait would exceed context deadline: %w", op, context.DeadlineExceeded)
+	}
+	return nil
+}
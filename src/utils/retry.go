@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RetryConfig controls how failed RPC calls are retried
+type RetryConfig struct {
+	MaxAttempts int           // total attempts including the first one
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration // upper bound for the backoff delay
+	Jitter      bool          // randomize delays to avoid retry storms
+}
+
+// DefaultRetryConfig returns the retry policy used when none is configured
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      true,
+	}
+}
+
+// backoff returns the delay before the given retry (1-based)
+func (r RetryConfig) backoff(retry int) time.Duration {
+	delay := r.BaseDelay
+	for i := 1; i < retry && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter && delay > 0 {
+		// Equal jitter: keep half the delay and randomize the rest
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+
+	return delay
+}
+
+// permanentErrors are error fragments that will not succeed on retry
+var permanentErrors = []string{
+	"invalid argument",
+	"invalid params",
+	"method not found",
+	"execution reverted",
+}
+
+// retryableErrors are error fragments for transient provider or network failures
+var retryableErrors = []string{
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"eof",
+	"429",
+	"too many requests",
+	"rate limit",
+	"502",
+	"503",
+	"504",
+	"service unavailable",
+	"temporarily unavailable",
+}
+
+// isPermanentError reports whether an RPC error means the request itself is
+// bad, so neither a retry nor another endpoint can serve it
+func isPermanentError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 && httpErr.StatusCode != 429 {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range permanentErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableError reports whether an RPC error is worth retrying
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || isPermanentError(err) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range retryableErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldRetry reports whether a failed call should be retried as is
+// Range errors are excluded even when they look transient (e.g. a provider
+// query timeout), since only a smaller range can succeed
+func (d *DopamintRPCClient) shouldRetry(err error) bool {
+	return isRetryableError(err) && !d.isRangeError(err)
+}
+
+// withRetry runs fn according to the client's retry policy
+func (d *DopamintRPCClient) withRetry(ctx context.Context, op string, fn func() error) error {
+	attempts := d.retryConfig.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s cancelled: %w", op, err)
+		}
+
+		err := fn()
+		if err == nil || attempt >= attempts || !d.shouldRetry(err) {
+			return err
+		}
+
+		delay := d.retryConfig.backoff(attempt)
+		d.logger.Warn("RPC call failed, retrying", "op", op, "attempt", attempt, "maxAttempts", attempts, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s retry aborted: %w", op, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), false},
+		{"eof", fmt.Errorf("read: %w", io.EOF), true},
+		{"connection refused", errors.New("dial tcp: connection refused"), true},
+		{"rate limited", errors.New("429 Too Many Requests"), true},
+		{"http 503", rpc.HTTPError{StatusCode: 503, Status: "Unavailable"}, true},
+		{"http 400", rpc.HTTPError{StatusCode: 400, Status: "Bad Request"}, false},
+		{"permanent wins", errors.New("invalid params: timeout must be positive"), false},
+		{"reverted", errors.New("execution reverted"), false},
+		{"unknown", errors.New("something odd"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	config := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{20, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("retry %d", tt.retry), func(t *testing.T) {
+			if got := config.backoff(tt.retry); got != tt.want {
+				t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+
+	jittered := config
+	jittered.Jitter = true
+	for retry := 1; retry <= 5; retry++ {
+		want := config.backoff(retry)
+		if got := jittered.backoff(retry); got < want/2 || got > want {
+			t.Errorf("jittered backoff(%d) = %v, want within [%v, %v]", retry, got, want/2, want)
+		}
+	}
+}
+
+func TestCallRetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "succeeds first time", wantCalls: 1},
+		{name: "transient errors are retried", failures: 2, err: errors.New("connection reset by peer"), wantCalls: 3},
+		{name: "gives up after max attempts", failures: 5, err: errors.New("429 Too Many Requests"), wantErr: true, wantCalls: 3},
+		{name: "permanent error is not retried", failures: 5, err: errors.New("invalid params"), wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fake := &fakeEthClient{blockNumber: func() (uint64, error) {
+				calls++
+				if calls <= tt.failures {
+					return 0, tt.err
+				}
+				return 42, nil
+			}}
+			d := newFakeRPCClient(t, fake, nil, WithRetryConfig(RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+			got, err := d.GetBlockNumber(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBlockNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != 42 {
+				t.Errorf("GetBlockNumber() = %d, want 42", got)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCallRetriesAndFailsOver(t *testing.T) {
+	fastRetry := WithRetryConfig(RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	tests := []struct {
+		name          string
+		primary       func() (uint64, error)
+		fallback      func() (uint64, error)
+		want          uint64
+		wantErr       bool
+		wantEndpoint  string
+		wantCallsMain int
+	}{
+		{
+			name:          "primary healthy",
+			primary:       func() (uint64, error) { return 10, nil },
+			fallback:      func() (uint64, error) { return 20, nil },
+			want:          10,
+			wantEndpoint:  "ws://primary",
+			wantCallsMain: 1,
+		},
+		{
+			name:          "transient error fails over",
+			primary:       func() (uint64, error) { return 0, errors.New("503 service unavailable") },
+			fallback:      func() (uint64, error) { return 20, nil },
+			want:          20,
+			wantEndpoint:  "ws://fallback",
+			wantCallsMain: 1,
+		},
+		{
+			name:          "unknown error fails over",
+			primary:       func() (uint64, error) { return 0, errors.New("something odd") },
+			fallback:      func() (uint64, error) { return 20, nil },
+			want:          20,
+			wantEndpoint:  "ws://fallback",
+			wantCallsMain: 1,
+		},
+		{
+			name:          "unknown error on every endpoint is not retried",
+			primary:       func() (uint64, error) { return 0, errors.New("something odd") },
+			fallback:      func() (uint64, error) { return 0, errors.New("something odd") },
+			wantErr:       true,
+			wantCallsMain: 1,
+		},
+		{
+			name:          "permanent error is not retried",
+			primary:       func() (uint64, error) { return 0, errors.New("method not found") },
+			fallback:      func() (uint64, error) { return 20, nil },
+			wantErr:       true,
+			wantCallsMain: 1,
+		},
+		{
+			name:          "every endpoint failing is retried",
+			primary:       func() (uint64, error) { return 0, errors.New("connection reset by peer") },
+			fallback:      func() (uint64, error) { return 0, errors.New("connection reset by peer") },
+			wantErr:       true,
+			wantCallsMain: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			primary := &fakeEthClient{blockNumber: func() (uint64, error) {
+				calls++
+				return tt.primary()
+			}}
+			fallback := &fakeEthClient{blockNumber: tt.fallback}
+
+			// A high threshold keeps the primary in rotation across retries
+			d := newFakeRPCClientPool(t, []string{"ws://primary", "ws://fallback"},
+				map[string]*fakeEthClient{"ws://primary": primary, "ws://fallback": fallback},
+				nil, fastRetry, WithFailoverPolicy(10, time.Minute))
+
+			got, err := d.GetBlockNumber(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBlockNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetBlockNumber() = %d, want %d", got, tt.want)
+			}
+			if !tt.wantErr && d.LastEndpoint() != tt.wantEndpoint {
+				t.Errorf("LastEndpoint() = %q, want %q", d.LastEndpoint(), tt.wantEndpoint)
+			}
+			if calls != tt.wantCallsMain {
+				t.Errorf("primary called %d times, want %d", calls, tt.wantCallsMain)
+			}
+		})
+	}
+}
+
+func TestFailoverCooldown(t *testing.T) {
+	primaryCalls := 0
+	primary := &fakeEthClient{blockNumber: func() (uint64, error) {
+		primaryCalls++
+		return 0, errors.New("connection refused")
+	}}
+	fallback := &fakeEthClient{blockNumber: func() (uint64, error) { return 20, nil }}
+
+	d := newFakeRPCClientPool(t, []string{"ws://primary", "ws://fallback"},
+		map[string]*fakeEthClient{"ws://primary": primary, "ws://fallback": fallback},
+		nil, WithRetryConfig(RetryConfig{MaxAttempts: 1}), WithFailoverPolicy(2, time.Minute))
+
+	for i := 0; i < 4; i++ {
+		if _, err := d.GetBlockNumber(context.Background()); err != nil {
+			t.Fatalf("GetBlockNumber() call %d: %v", i, err)
+		}
+	}
+
+	// Two failures reach the threshold; later calls skip the primary
+	if primaryCalls != 2 {
+		t.Errorf("primary called %d times, want 2", primaryCalls)
+	}
+}
+
+func TestIsPermanentError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"invalid params", errors.New("invalid params: bad block"), true},
+		{"http 400", rpc.HTTPError{StatusCode: 400, Status: "Bad Request"}, true},
+		{"http 429", rpc.HTTPError{StatusCode: 429, Status: "Too Many Requests"}, false},
+		{"http 503", rpc.HTTPError{StatusCode: 503, Status: "Unavailable"}, false},
+		{"unknown", errors.New("something odd"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPermanentError(tt.err); got != tt.want {
+				t.Errorf("isPermanentError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
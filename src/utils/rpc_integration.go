@@ -2,98 +2,739 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/logging"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/time/rate"
 )
 
 // DopamintRPCClient wraps the standard RPC client with Dopamint-specific filtering
 type DopamintRPCClient struct {
-	client         *ethclient.Client
-	addressFilter  []common.Address
-	filterEnabled  bool
+	endpoints         []*rpcEndpoint
+	dial              Dialer
+	endpointMu        sync.Mutex
+	lastEndpoint      string
+	failoverThreshold int
+	failoverCooldown  time.Duration
+	filterMu          sync.RWMutex // guards addressFilter and filterChanged
+	addressFilter     []common.Address
+	filterGen         atomic.Uint64 // bumped on every address filter change
+	filterChanged     chan struct{} // closed and replaced on every address filter change
+	filterEnabled     bool
+	topics            [][]common.Hash
+	retryConfig       RetryConfig
+	metrics           *MetricsRecorder
+	baselineEvery     uint64
+	baselineFetches   atomic.Uint64
+	timestamps        *timestampCache
+	clampToHead       bool
+	confirmations     uint64
+	logger            logging.Logger
+	limiter           *rate.Limiter
+	maxAddresses      int
+	rangeErrors       []string
+	minChunkSize      uint64
+}
+
+// RPCClientOption configures optional DopamintRPCClient behaviour
+type RPCClientOption func(*DopamintRPCClient)
+
+// WithRetryConfig sets the retry policy applied to every RPC call
+func WithRetryConfig(config RetryConfig) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.retryConfig = config
+	}
+}
+
+// WithEventTopics restricts log queries and subscriptions to the given event
+// signatures. When called without signatures, the known Dopamint event
+// signatures are used. Without this option all events are returned
+func WithEventTopics(signatures ...common.Hash) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		if len(signatures) == 0 {
+			signatures = filters.GetEventSignatures()
+		}
+		d.topics = [][]common.Hash{signatures}
+	}
+}
+
+// WithMetrics records log fetch statistics on the given recorder
+// A nil recorder disables metrics
+func WithMetrics(recorder *MetricsRecorder) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.metrics = recorder
+	}
+}
+
+// WithFilterEfficiencySampling measures filter efficiency on every nth
+// address-filtered fetch by also counting the logs the same query returns
+// without the address filter. This costs one extra, unfiltered request per
+// sample, which can be large on busy chains, so it is off by default; without
+// it efficiency is only measured on fetches made without an address filter
+func WithFilterEfficiencySampling(every int) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		if every < 0 {
+			every = 0
+		}
+		d.baselineEvery = uint64(every)
+	}
+}
+
+// WithLogger sets the logger of the client
+func WithLogger(logger logging.Logger) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.logger = logger
+	}
+}
+
+// WithFailoverPolicy sets how many consecutive failures mark an endpoint
+// unhealthy and how long it is skipped afterwards
+func WithFailoverPolicy(threshold int, cooldown time.Duration) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.failoverThreshold = threshold
+		d.failoverCooldown = cooldown
+	}
+}
+
+// WithClampToHead makes log queries clamp toBlock to the current chain head
+// and reject ranges starting beyond it, at the cost of one extra BlockNumber
+// call per query. Without it explicit ranges are used as given
+func WithClampToHead() RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.clampToHead = true
+	}
+}
+
+// WithConfirmations sets how many blocks GetSafeBlockNumber stays behind the
+// chain head, so logs that may still be reorged out are not indexed
+func WithConfirmations(confirmations uint64) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.confirmations = confirmations
+	}
+}
+
+// WithMaxAddressesPerQuery caps the number of addresses sent in a single
+// eth_getLogs request, for providers that limit filter size. Larger watch sets
+// are split into groups queried separately. Zero means no cap
+func WithMaxAddressesPerQuery(limit int) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.maxAddresses = limit
+	}
+}
+
+// WithRangeErrors adds provider error fragments that mean a log query covered
+// too large a block range, on top of the default set. Matching is case-insensitive
+func WithRangeErrors(fragments ...string) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		for _, fragment := range fragments {
+			if fragment = strings.ToLower(strings.TrimSpace(fragment)); fragment != "" {
+				d.rangeErrors = append(d.rangeErrors, fragment)
+			}
+		}
+	}
+}
+
+// WithMinChunkSize sets the smallest window GetFilteredLogsChunked bisects
+// down to before giving up on a range error. Defaults to a single block
+func WithMinChunkSize(blocks uint64) RPCClientOption {
+	return func(d *DopamintRPCClient) {
+		d.minChunkSize = blocks
+	}
 }
 
 // NewDopamintRPCClient creates a new Dopamint RPC client
-func NewDopamintRPCClient(rpcURL string, addresses []common.Address, filterEnabled bool) (*DopamintRPCClient, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+func NewDopamintRPCClient(rpcURL string, addresses []common.Address, filterEnabled bool, opts ...RPCClientOption) (*DopamintRPCClient, error) {
+	return NewDopamintRPCClientPool([]string{rpcURL}, addresses, filterEnabled, opts...)
+}
+
+// NewDopamintRPCClientPool creates a Dopamint RPC client backed by a
+// prioritized list of endpoints, failing over to the next one on errors
+func NewDopamintRPCClientPool(urls []string, addresses []common.Address, filterEnabled bool, opts ...RPCClientOption) (*DopamintRPCClient, error) {
+	d := &DopamintRPCClient{
+		addressFilter:     addresses,
+		filterChanged:     make(chan struct{}),
+		filterEnabled:     filterEnabled,
+		retryConfig:       DefaultRetryConfig(),
+		failoverThreshold: 3,
+		failoverCooldown:  30 * time.Second,
+		timestamps:        newTimestampCache(defaultTimestampCacheSize),
+		logger:            logging.Default("DopamintRPC"),
+		rangeErrors:       append([]string(nil), defaultRangeErrors...),
+		minChunkSize:      1,
+		dial:              dialEthClient,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	for _, url := range urls {
+		client, err := d.dial(context.Background(), url)
+		if err != nil {
+			d.logger.Warn("Failed to connect to RPC endpoint", "endpoint", url, "error", err)
+			continue
+		}
+		d.endpoints = append(d.endpoints, &rpcEndpoint{url: url, client: client})
 	}
 
-	return &DopamintRPCClient{
-		client:        client,
-		addressFilter: addresses,
-		filterEnabled: filterEnabled,
-	}, nil
+	if len(d.endpoints) == 0 {
+		return nil, fmt.Errorf("failed to connect to RPC: %w: no reachable endpoint among %d", errdefs.ErrConnection, len(urls))
+	}
+
+	return d, nil
 }
 
 // GetFilteredLogs fetches logs with address filtering
 func (d *DopamintRPCClient) GetFilteredLogs(ctx context.Context, fromBlock, toBlock *big.Int) ([]types.Log, error) {
-	if !d.filterEnabled || len(d.addressFilter) == 0 {
+	return d.getFilteredLogs(ctx, d.filterQuery(fromBlock, toBlock))
+}
+
+// GetFilteredLogsWithTopics fetches logs with address filtering, restricted
+// to the given topics instead of the client's configured ones
+// Passing nil topics returns all events from the watched contracts
+func (d *DopamintRPCClient) GetFilteredLogsWithTopics(ctx context.Context, fromBlock, toBlock *big.Int, topics [][]common.Hash) ([]types.Log, error) {
+	query := d.filterQuery(fromBlock, toBlock)
+	query.Topics = topics
+	return d.getFilteredLogs(ctx, query)
+}
+
+// getFilteredLogs runs a filter query, logging a summary for address-filtered queries
+func (d *DopamintRPCClient) getFilteredLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var err error
+	if query.FromBlock, query.ToBlock, err = d.validateRange(ctx, query.FromBlock, query.ToBlock); err != nil {
+		return nil, err
+	}
+
+	if len(query.Addresses) == 0 {
 		// No filtering, fetch all logs
-		query := ethereum.FilterQuery{
-			FromBlock: fromBlock,
-			ToBlock:   toBlock,
+		logs, err := d.filterLogs(ctx, query)
+		if err != nil {
+			return nil, d.wrapLogsError("failed to fetch logs", err)
 		}
-		return d.client.FilterLogs(ctx, query)
+		d.recordMetrics(ctx, query, logs)
+		return logs, nil
 	}
 
 	// Fetch logs only from Dopamint contracts
+	logs, err := d.filterLogs(ctx, query)
+	if err != nil {
+		return nil, d.wrapLogsError("failed to fetch filtered logs", err)
+	}
+
+	d.recordMetrics(ctx, query, logs)
+
+	d.logger.Info("Fetched filtered logs", "logs", len(logs), "contracts", len(query.Addresses),
+		"fromBlock", query.FromBlock.String(), "toBlock", query.ToBlock.String())
+
+	return logs, nil
+}
+
+// wrapLogsError wraps a log fetch error with msg, marking provider range
+// errors with ErrRangeTooLarge so callers can retry with a smaller window
+func (d *DopamintRPCClient) wrapLogsError(msg string, err error) error {
+	if d.isRangeError(err) {
+		return fmt.Errorf("%s: %w: %w", msg, ErrRangeTooLarge, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// filterLogs calls FilterLogs with retries and failover
+// When the query has more addresses than allowed per request, one request is
+// made per address group and the results are merged in block order
+func (d *DopamintRPCClient) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	groups := addressGroups(query.Addresses, d.maxAddresses)
+	if len(groups) <= 1 {
+		return d.filterLogsOnce(ctx, query)
+	}
+
+	var merged []types.Log
+	for i, group := range groups {
+		groupQuery := query
+		groupQuery.Addresses = group
+
+		logs, err := d.filterLogsOnce(ctx, groupQuery)
+		if err != nil {
+			return nil, fmt.Errorf("address group %d/%d: %w", i+1, len(groups), err)
+		}
+		merged = append(merged, logs...)
+	}
+
+	return normalizeLogs(merged), nil
+}
+
+// filterLogsOnce runs a single FilterLogs request with retries and failover
+func (d *DopamintRPCClient) filterLogsOnce(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := d.call(ctx, "FilterLogs", func(client EthClient) error {
+		var err error
+		logs, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+// recordMetrics reports a completed fetch to the metrics recorder, counting
+// the logs that come from addresses in the address filter
+// A fetch without an address filter doubles as a filter efficiency baseline;
+// address-filtered fetches are sampled as configured by
+// WithFilterEfficiencySampling
+func (d *DopamintRPCClient) recordMetrics(ctx context.Context, query ethereum.FilterQuery, logs []types.Log) {
+	if d.metrics == nil {
+		return
+	}
+
+	addresses := d.watchedAddresses()
+	afterFilter := len(logs)
+	if len(query.Addresses) == 0 && len(addresses) > 0 {
+		afterFilter = countWatchedLogs(logs, addresses)
+		d.metrics.ObserveBaseline(len(logs), afterFilter)
+	}
+
+	var blocks int64
+	if query.FromBlock != nil && query.ToBlock != nil {
+		blocks = new(big.Int).Sub(query.ToBlock, query.FromBlock).Int64() + 1
+	}
+
+	d.metrics.ObserveLogs(len(logs), afterFilter, blocks, len(addresses))
+
+	if len(query.Addresses) > 0 {
+		d.sampleBaseline(ctx, query)
+	}
+}
+
+// sampleBaseline re-runs every nth address-filtered query without its address
+// filter to record a filter efficiency baseline. Failures only skip the sample
+func (d *DopamintRPCClient) sampleBaseline(ctx context.Context, query ethereum.FilterQuery) {
+	if d.baselineEvery == 0 || d.baselineFetches.Add(1)%d.baselineEvery != 0 {
+		return
+	}
+
+	unfiltered := query
+	unfiltered.Addresses = nil
+	logs, err := d.filterLogsOnce(ctx, unfiltered)
+	if err != nil {
+		d.logger.Debug("Failed to sample unfiltered logs for filter efficiency", "error", err)
+		return
+	}
+
+	d.metrics.ObserveBaseline(len(logs), countWatchedLogs(logs, query.Addresses))
+}
+
+// countWatchedLogs returns how many logs come from one of addresses
+func countWatchedLogs(logs []types.Log, addresses []common.Address) int {
+	watched := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		watched[addr] = true
+	}
+
+	count := 0
+	for _, log := range logs {
+		if watched[log.Address] {
+			count++
+		}
+	}
+	return count
+}
+
+// filterQuery builds the FilterQuery for a block range, restricted to the
+// address filter when filtering is enabled and to the configured topics
+func (d *DopamintRPCClient) filterQuery(fromBlock, toBlock *big.Int) ethereum.FilterQuery {
 	query := ethereum.FilterQuery{
 		FromBlock: fromBlock,
 		ToBlock:   toBlock,
-		Addresses: d.addressFilter,
+		Topics:    d.topics,
+	}
+
+	if addresses := d.watchedAddresses(); d.filterEnabled && len(addresses) > 0 {
+		query.Addresses = addresses
 	}
 
-	logs, err := d.client.FilterLogs(ctx, query)
+	return query
+}
+
+// validateRange checks a log query range, returning the range to use
+// A nil bound means latest and is left as is. With clampToHead enabled, toBlock
+// is lowered to the chain head and a fromBlock beyond the head is rejected
+func (d *DopamintRPCClient) validateRange(ctx context.Context, fromBlock, toBlock *big.Int) (*big.Int, *big.Int, error) {
+	if fromBlock != nil && toBlock != nil && fromBlock.Cmp(toBlock) > 0 {
+		return nil, nil, fmt.Errorf("invalid block range: %s > %s", fromBlock.String(), toBlock.String())
+	}
+	if !d.clampToHead {
+		return fromBlock, toBlock, nil
+	}
+
+	number, err := d.GetBlockNumber(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch filtered logs: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch chain head: %w", err)
 	}
+	head := new(big.Int).SetUint64(number)
 
-	fmt.Printf("[DopamintRPC] Fetched %d logs from %d contracts (blocks %s-%s)\n",
-		len(logs), len(d.addressFilter), fromBlock.String(), toBlock.String())
+	if fromBlock != nil && fromBlock.Cmp(head) > 0 {
+		return nil, nil, fmt.Errorf("invalid block range: fromBlock %s is beyond chain head %d", fromBlock.String(), number)
+	}
+	if toBlock == nil || toBlock.Cmp(head) > 0 {
+		toBlock = head
+	}
 
-	return logs, nil
+	return fromBlock, toBlock, nil
+}
+
+// ChunkedLogsResult holds the outcome of a chunked log fetch
+// NextBlock is the first block that has not been fetched yet, so a failed or
+// cancelled fetch can be resumed from there
+type ChunkedLogsResult struct {
+	Logs       []types.Log
+	NextBlock  *big.Int
+	Chunks     int
+	Bisections int // number of windows split in two after a range error
 }
 
-// UpdateAddressFilter updates the address filter
-func (d *DopamintRPCClient) UpdateAddressFilter(addresses []common.Address) {
-	d.addressFilter = addresses
-	fmt.Printf("[DopamintRPC] Updated address filter: %d addresses\n", len(addresses))
+// ErrRangeTooLarge is wrapped by log fetch errors caused by the provider
+// rejecting the block range as too large or too slow to serve
+var ErrRangeTooLarge = errors.New("block range too large for provider")
+
+// defaultRangeErrors are provider error fragments indicating that a log query
+// matched too many results or took too long for the requested block range
+var defaultRangeErrors = []string{
+	"query returned more than",
+	"more than 10000 results",
+	"too many results",
+	"response size exceeded",
+	"log response size exceeded",
+	"exceed maximum block range",
+	"block range is too wide",
+	"block range too large",
+	"range too large",
+	"limit exceeded",
+	"query timeout exceeded",
+}
+
+// isRangeError reports whether err means the block range should be reduced
+func (d *DopamintRPCClient) isRangeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRangeTooLarge) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range d.rangeErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFilteredLogsChunked fetches logs over a large range in windows of
+// chunkSize blocks, preserving block order
+// When the provider rejects a window as too large, the window is bisected
+// recursively down to the minimum chunk size and later windows use the size
+// that succeeded. On error the partial result is returned alongside it
+func (d *DopamintRPCClient) GetFilteredLogsChunked(ctx context.Context, fromBlock, toBlock, chunkSize *big.Int) (*ChunkedLogsResult, error) {
+	if chunkSize == nil || chunkSize.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid chunk size: %v", chunkSize)
+	}
+	fromBlock, toBlock, err := d.validateRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock == nil || toBlock == nil {
+		return nil, fmt.Errorf("chunked fetch requires explicit fromBlock and toBlock")
+	}
+
+	one := big.NewInt(1)
+	size := new(big.Int).Set(chunkSize)
+	result := &ChunkedLogsResult{NextBlock: new(big.Int).Set(fromBlock)}
+
+	for result.NextBlock.Cmp(toBlock) <= 0 {
+		end := new(big.Int).Add(result.NextBlock, size)
+		end.Sub(end, one)
+		if end.Cmp(toBlock) > 0 {
+			end.Set(toBlock)
+		}
+
+		bisections := result.Bisections
+		leaf, err := d.fetchWindow(ctx, new(big.Int).Set(result.NextBlock), end, result)
+		if err != nil {
+			return result, err
+		}
+		if result.Bisections > bisections && leaf.Cmp(size) < 0 {
+			size = leaf
+			d.logger.Warn("Reduced chunk size after range errors", "chunkSize", size.String())
+		}
+	}
+
+	result.Logs = normalizeLogs(result.Logs)
+
+	d.logger.Info("Fetched chunked logs", "logs", len(result.Logs), "chunks", result.Chunks,
+		"bisections", result.Bisections, "fromBlock", fromBlock.String(), "toBlock", toBlock.String())
+
+	return result, nil
+}
+
+// fetchWindow fetches the logs of blocks from-to into result, bisecting the
+// window while the provider rejects it as too large
+// Sub-windows are fetched in order, so result.NextBlock always marks the first
+// block not fetched yet. It returns the size of the last window that succeeded
+func (d *DopamintRPCClient) fetchWindow(ctx context.Context, from, to *big.Int, result *ChunkedLogsResult) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("chunked fetch cancelled at block %s: %w", from.String(), err)
+	}
+
+	one := big.NewInt(1)
+	window := new(big.Int).Sub(to, from)
+	window.Add(window, one)
+
+	query := d.filterQuery(from, to)
+	logs, err := d.filterLogs(ctx, query)
+	if err == nil {
+		d.recordMetrics(ctx, query, logs)
+		result.Logs = append(result.Logs, logs...)
+		result.Chunks++
+		result.NextBlock = new(big.Int).Add(to, one)
+		return window, nil
+	}
+
+	if !d.isRangeError(err) || ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to fetch logs for blocks %s-%s: %w", from.String(), to.String(), err)
+	}
+	if window.Cmp(new(big.Int).SetUint64(d.minChunkSize)) <= 0 || window.Cmp(one) <= 0 {
+		return nil, fmt.Errorf("failed to fetch logs for blocks %s-%s at minimum chunk size %d: %w: %w",
+			from.String(), to.String(), d.minChunkSize, ErrRangeTooLarge, err)
+	}
+
+	// Split into [from, mid] and [mid+1, to]
+	mid := new(big.Int).Rsh(window, 1)
+	mid.Add(mid, from)
+	mid.Sub(mid, one)
+
+	result.Bisections++
+	d.logger.Warn("Block range rejected, bisecting", "fromBlock", from.String(), "toBlock", to.String(),
+		"midBlock", mid.String(), "error", err)
+
+	if _, err := d.fetchWindow(ctx, from, mid, result); err != nil {
+		return nil, err
+	}
+	return d.fetchWindow(ctx, new(big.Int).Add(mid, one), to, result)
 }
 
 // GetBlockNumber gets the latest block number
 func (d *DopamintRPCClient) GetBlockNumber(ctx context.Context) (uint64, error) {
-	return d.client.BlockNumber(ctx)
+	var number uint64
+	err := d.call(ctx, "BlockNumber", func(client EthClient) error {
+		var err error
+		number, err = client.BlockNumber(ctx)
+		return err
+	})
+	return number, err
+}
+
+// GetSafeBlockNumber returns the latest block with the configured number of
+// confirmations, i.e. head - confirmations floored at 0
+// Backfill and polling loops should use it as their toBlock ceiling
+func (d *DopamintRPCClient) GetSafeBlockNumber(ctx context.Context) (uint64, error) {
+	head, err := d.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return safeBlockNumber(head, d.confirmations), nil
+}
+
+// safeBlockNumber subtracts confirmations from head without underflowing
+func safeBlockNumber(head, confirmations uint64) uint64 {
+	if confirmations >= head {
+		return 0
+	}
+	return head - confirmations
 }
 
 // GetBlockByNumber gets a block by number
 func (d *DopamintRPCClient) GetBlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-	return d.client.BlockByNumber(ctx, number)
+	var block *types.Block
+	err := d.call(ctx, "BlockByNumber", func(client EthClient) error {
+		var err error
+		block, err = client.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+// GetLogsByTxHash fetches the logs of a single transaction from its receipt,
+// restricted to the address filter when filtering is enabled
+func (d *DopamintRPCClient) GetLogsByTxHash(ctx context.Context, txHash common.Hash) ([]types.Log, error) {
+	var receipt *types.Receipt
+	err := d.call(ctx, "TransactionReceipt", func(client EthClient) error {
+		var err error
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipt for %s: %w", txHash.Hex(), err)
+	}
+
+	query := d.filterQuery(nil, nil)
+	watched := make(map[common.Address]bool, len(query.Addresses))
+	for _, addr := range query.Addresses {
+		watched[addr] = true
+	}
+
+	logs := make([]types.Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		if len(watched) == 0 || watched[log.Address] {
+			logs = append(logs, *log)
+		}
+	}
+
+	d.logger.Info("Fetched transaction logs", "tx", txHash.Hex(), "logs", len(logs), "total", len(receipt.Logs))
+
+	return logs, nil
+}
+
+// GetTxSender returns the sender (tx origin) of a mined transaction
+func (d *DopamintRPCClient) GetTxSender(ctx context.Context, txHash common.Hash) (common.Address, error) {
+	var (
+		tx      *types.Transaction
+		pending bool
+	)
+	err := d.call(ctx, "TransactionByHash", func(client EthClient) error {
+		var err error
+		tx, pending, err = client.TransactionByHash(ctx, txHash)
+		return err
+	})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to fetch transaction %s: %w", txHash.Hex(), err)
+	}
+	if pending {
+		return common.Address{}, fmt.Errorf("transaction %s is still pending", txHash.Hex())
+	}
+
+	var receipt *types.Receipt
+	err = d.call(ctx, "TransactionReceipt", func(client EthClient) error {
+		var err error
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to fetch receipt for %s: %w", txHash.Hex(), err)
+	}
+
+	// The sender reported with the transaction is cached by the client, so
+	// this only costs a request when the node omitted it
+	var sender common.Address
+	err = d.call(ctx, "TransactionSender", func(client EthClient) error {
+		var err error
+		sender, err = client.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+		return err
+	})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get sender of %s: %w", txHash.Hex(), err)
+	}
+
+	return sender, nil
 }
 
-// Close closes the RPC connection
+// FillCreatorFromTx sets the creator of a discovered contract to the sender of
+// its creation transaction when the event carries no creator, as emitted by
+// factories that do not index the true creator
+func (d *DopamintRPCClient) FillCreatorFromTx(ctx context.Context, event *filters.NFTContractCreatedEvent) error {
+	if event.Creator != (common.Address{}) {
+		return nil
+	}
+
+	sender, err := d.GetTxSender(ctx, event.TxHash)
+	if err != nil {
+		return fmt.Errorf("failed to resolve creator of %s: %w", event.ContractAddress.Hex(), err)
+	}
+	event.Creator = sender
+
+	d.logger.Debug("Filled contract creator from transaction sender", "contract", event.ContractAddress.Hex(), "creator", sender.Hex())
+	return nil
+}
+
+// FindContractDeploymentBlock binary-searches for the earliest block at which
+// address has bytecode. Requires an archive node for historical state
+func (d *DopamintRPCClient) FindContractDeploymentBlock(ctx context.Context, address common.Address) (uint64, error) {
+	head, err := d.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	hasCode, err := d.hasCodeAt(ctx, address, head)
+	if err != nil {
+		return 0, err
+	}
+	if !hasCode {
+		return 0, fmt.Errorf("no contract code at %s as of block %d", address.Hex(), head)
+	}
+
+	low, high := uint64(0), head
+	for low < high {
+		mid := low + (high-low)/2
+		hasCode, err := d.hasCodeAt(ctx, address, mid)
+		if err != nil {
+			return 0, err
+		}
+		if hasCode {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	d.logger.Info("Found contract deployment block", "address", address.Hex(), "block", low)
+	return low, nil
+}
+
+// hasCodeAt reports whether address has bytecode at the given block
+func (d *DopamintRPCClient) hasCodeAt(ctx context.Context, address common.Address, block uint64) (bool, error) {
+	var code []byte
+	err := d.call(ctx, "CodeAt", func(client EthClient) error {
+		var err error
+		code, err = client.CodeAt(ctx, address, new(big.Int).SetUint64(block))
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code at block %d: %w", block, err)
+	}
+	return len(code) > 0, nil
+}
+
+// Close closes the RPC connections
 func (d *DopamintRPCClient) Close() {
-	d.client.Close()
+	for _, ep := range d.endpoints {
+		d.endpointClient(ep).Close()
+	}
 }
 
-// GetClient returns the underlying eth client
+// GetClient returns the underlying eth client of the preferred healthy endpoint
+// It returns nil when the endpoint was connected with a WithDialer client
+// other than *ethclient.Client
 func (d *DopamintRPCClient) GetClient() *ethclient.Client {
-	return d.client
+	client, _ := d.endpointClient(d.orderedEndpoints()[0]).(*ethclient.Client)
+	return client
 }
 
 // LogFilterStats represents filtering statistics
 type LogFilterStats struct {
-	TotalLogsReceived  int64
-	LogsAfterFilter    int64
-	BlocksProcessed    int64
-	ContractsWatched   int
-	FilterEnabled      bool
+	TotalLogsReceived int64
+	LogsAfterFilter   int64
+	BlocksProcessed   int64
+	ContractsWatched  int
+	FilterEnabled     bool
 }
 
 // CalculateFilterEfficiency calculates the efficiency of filtering
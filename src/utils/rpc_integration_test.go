@@ -0,0 +1,444 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/A8-Tim/dopamint-indexer-insight/src/errdefs"
+	"github.com/A8-Tim/dopamint-indexer-insight/src/filters"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNewClientPoolWithoutReachableEndpoint(t *testing.T) {
+	_, err := NewDopamintRPCClientPool([]string{"ws://a", "ws://b"}, nil, true,
+		WithDialer(func(ctx context.Context, url string) (EthClient, error) {
+			return nil, errors.New("dial refused")
+		}))
+	if !errors.Is(err, errdefs.ErrConnection) {
+		t.Fatalf("error %v does not match %v", err, errdefs.ErrConnection)
+	}
+}
+
+func TestGetFilteredLogsChunked(t *testing.T) {
+	watched := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	errProvider := errors.New("invalid argument 0: hex string without 0x prefix")
+
+	tests := []struct {
+		name           string
+		maxRange       uint64 // widest range the provider serves, 0 for unlimited
+		failFrom       uint64 // first block the provider fails on, 0 for none
+		minChunk       uint64
+		wantChunks     int
+		wantBisections int
+		wantNextBlock  uint64
+		wantErr        error
+	}{
+		{name: "fits in chunks", wantChunks: 4, wantNextBlock: 200},
+		// The first window is split into 12 and 13 blocks, and later windows reuse 13
+		{name: "bisects rejected windows", maxRange: 13, wantChunks: 8, wantBisections: 1, wantNextBlock: 200},
+		// 25 -> 12+13 -> 6+6 and 6+7 -> 3+3 and 3+4 -> 2+2, then later windows reuse
+		// the size of the last window that fit, 2
+		{name: "fails until the window is small enough", maxRange: 3, wantChunks: 47, wantBisections: 8, wantNextBlock: 200},
+		{name: "gives up at the minimum chunk size", maxRange: 3, minChunk: 10, wantBisections: 2, wantNextBlock: 100, wantErr: ErrRangeTooLarge},
+		{name: "reports progress on failure", failFrom: 150, wantChunks: 2, wantNextBlock: 150, wantErr: errProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{filterLogs: func(query ethereum.FilterQuery) ([]types.Log, error) {
+				from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+				if tt.maxRange > 0 && to-from+1 > tt.maxRange {
+					return nil, errors.New("query returned more than 10000 results")
+				}
+				if tt.failFrom > 0 && to >= tt.failFrom {
+					return nil, errProvider
+				}
+				// One log per block so every block is accounted for exactly once
+				logs := make([]types.Log, 0, to-from+1)
+				for block := from; block <= to; block++ {
+					logs = append(logs, types.Log{Address: watched, BlockNumber: block, BlockHash: common.BigToHash(new(big.Int).SetUint64(block))})
+				}
+				return logs, nil
+			}}
+
+			opts := []RPCClientOption{WithRetryConfig(RetryConfig{MaxAttempts: 1})}
+			if tt.minChunk > 0 {
+				opts = append(opts, WithMinChunkSize(tt.minChunk))
+			}
+			d := newFakeRPCClient(t, fake, []common.Address{watched}, opts...)
+
+			result, err := d.GetFilteredLogsChunked(context.Background(), big.NewInt(100), big.NewInt(199), big.NewInt(25))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v does not match %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("GetFilteredLogsChunked: %v", err)
+			}
+
+			wantLogs := int(tt.wantNextBlock - 100)
+			if len(result.Logs) != wantLogs {
+				t.Fatalf("got %d logs, want %d", len(result.Logs), wantLogs)
+			}
+			for i, log := range result.Logs {
+				if log.BlockNumber != uint64(100+i) {
+					t.Fatalf("log %d is from block %d, want %d", i, log.BlockNumber, 100+i)
+				}
+			}
+			if result.Chunks != tt.wantChunks || result.Bisections != tt.wantBisections {
+				t.Errorf("chunks = %d, bisections = %d, want %d and %d",
+					result.Chunks, result.Bisections, tt.wantChunks, tt.wantBisections)
+			}
+			if result.NextBlock.Uint64() != tt.wantNextBlock {
+				t.Errorf("NextBlock = %s, want %d", result.NextBlock, tt.wantNextBlock)
+			}
+		})
+	}
+}
+
+func TestGetFilteredLogsMarksRangeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		filtering bool
+		err       error
+		wantRange bool
+	}{
+		{name: "filtered range error", filtering: true, err: errors.New("query returned more than 10000 results"), wantRange: true},
+		{name: "unfiltered range error", err: errors.New("query returned more than 10000 results"), wantRange: true},
+		{name: "unfiltered other error", err: errors.New("invalid argument 0: hex string without 0x prefix")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{filterLogs: func(query ethereum.FilterQuery) ([]types.Log, error) {
+				return nil, tt.err
+			}}
+			d := newFakeRPCClient(t, fake, []common.Address{common.HexToAddress("0x0c01")}, WithRetryConfig(RetryConfig{MaxAttempts: 1}))
+			d.filterEnabled = tt.filtering
+
+			_, err := d.GetFilteredLogs(context.Background(), big.NewInt(100), big.NewInt(199))
+			if err == nil {
+				t.Fatalf("GetFilteredLogs succeeded, want an error")
+			}
+			if got := errors.Is(err, ErrRangeTooLarge); got != tt.wantRange {
+				t.Errorf("errors.Is(%v, ErrRangeTooLarge) = %v, want %v", err, got, tt.wantRange)
+			}
+		})
+	}
+}
+
+func TestFindContractDeploymentBlock(t *testing.T) {
+	const head = 10_000
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+
+	tests := []struct {
+		name     string
+		deployed uint64 // first block with code, above head for never
+		want     uint64
+		wantErr  bool
+	}{
+		{name: "genesis", deployed: 0, want: 0},
+		{name: "mid chain", deployed: 1234, want: 1234},
+		{name: "head block", deployed: head, want: head},
+		{name: "never deployed", deployed: head + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{
+				blockNumber: func() (uint64, error) { return head, nil },
+				codeAt: func(account common.Address, block uint64) ([]byte, error) {
+					if account != contract || block < tt.deployed {
+						return nil, nil
+					}
+					return []byte{0x60, 0x80}, nil
+				},
+			}
+			d := newFakeRPCClient(t, fake, nil)
+
+			got, err := d.FindContractDeploymentBlock(context.Background(), contract)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindContractDeploymentBlock error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindContractDeploymentBlock = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLogsByTxHash(t *testing.T) {
+	watched := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	txHash := common.HexToHash("0xfeed")
+
+	receipt := &types.Receipt{TxHash: txHash, Logs: []*types.Log{
+		{Address: other, Index: 0},
+		{Address: watched, Index: 1},
+		{Address: other, Index: 2},
+		{Address: watched, Index: 3},
+	}}
+
+	tests := []struct {
+		name          string
+		addresses     []common.Address
+		filterEnabled bool
+		wantIndexes   []uint
+	}{
+		{name: "filtering on", addresses: []common.Address{watched}, filterEnabled: true, wantIndexes: []uint{1, 3}},
+		{name: "filtering off", addresses: []common.Address{watched}, wantIndexes: []uint{0, 1, 2, 3}},
+		{name: "no watched addresses", filterEnabled: true, wantIndexes: []uint{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{receipt: func(hash common.Hash) (*types.Receipt, error) {
+				if hash != txHash {
+					return nil, ethereum.NotFound
+				}
+				return receipt, nil
+			}}
+			d := newFakeRPCClient(t, fake, tt.addresses)
+			d.filterEnabled = tt.filterEnabled
+
+			logs, err := d.GetLogsByTxHash(context.Background(), txHash)
+			if err != nil {
+				t.Fatalf("GetLogsByTxHash: %v", err)
+			}
+			var got []uint
+			for _, log := range logs {
+				got = append(got, log.Index)
+			}
+			if !reflect.DeepEqual(got, tt.wantIndexes) {
+				t.Errorf("got logs %v, want %v", got, tt.wantIndexes)
+			}
+		})
+	}
+
+	d := newFakeRPCClient(t, &fakeEthClient{receipt: func(common.Hash) (*types.Receipt, error) { return nil, ethereum.NotFound }}, nil)
+	if _, err := d.GetLogsByTxHash(context.Background(), txHash); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("GetLogsByTxHash of an unknown tx error = %v, want %v", err, ethereum.NotFound)
+	}
+}
+
+func TestGetFilteredLogsValidatesRange(t *testing.T) {
+	watched := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	const head = 1000
+
+	tests := []struct {
+		name     string
+		clamp    bool
+		from, to *big.Int
+		wantTo   *big.Int // toBlock sent to the provider
+		wantErr  bool
+	}{
+		{name: "explicit range honored without clamping", from: big.NewInt(900), to: big.NewInt(5000), wantTo: big.NewInt(5000)},
+		{name: "toBlock clamped to head", clamp: true, from: big.NewInt(900), to: big.NewInt(5000), wantTo: big.NewInt(head)},
+		{name: "latest resolved to head", clamp: true, from: big.NewInt(900), wantTo: big.NewInt(head)},
+		{name: "range below head unchanged", clamp: true, from: big.NewInt(900), to: big.NewInt(950), wantTo: big.NewInt(950)},
+		{name: "fromBlock beyond head", clamp: true, from: big.NewInt(head + 1), to: big.NewInt(head + 10), wantErr: true},
+		{name: "inverted range", from: big.NewInt(950), to: big.NewInt(900), wantErr: true},
+		{name: "inverted range with clamping", clamp: true, from: big.NewInt(950), to: big.NewInt(900), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{
+				blockNumber: func() (uint64, error) { return head, nil },
+				filterLogs:  func(query ethereum.FilterQuery) ([]types.Log, error) { return nil, nil },
+			}
+			opts := []RPCClientOption{WithRetryConfig(RetryConfig{MaxAttempts: 1})}
+			if tt.clamp {
+				opts = append(opts, WithClampToHead())
+			}
+			d := newFakeRPCClient(t, fake, []common.Address{watched}, opts...)
+
+			_, err := d.GetFilteredLogs(context.Background(), tt.from, tt.to)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetFilteredLogs succeeded, want an error")
+				}
+				if len(fake.logQueries) != 0 {
+					t.Fatalf("invalid range sent to the provider: %v", fake.logQueries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetFilteredLogs: %v", err)
+			}
+			if len(fake.logQueries) != 1 {
+				t.Fatalf("got %d queries, want 1", len(fake.logQueries))
+			}
+			query := fake.logQueries[0]
+			if query.FromBlock.Cmp(tt.from) != 0 || query.ToBlock.Cmp(tt.wantTo) != 0 {
+				t.Errorf("queried %s-%s, want %s-%s", query.FromBlock, query.ToBlock, tt.from, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestGetSafeBlockNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		head          uint64
+		confirmations uint64
+		want          uint64
+	}{
+		{name: "no confirmations", head: 1000, want: 1000},
+		{name: "behind head", head: 1000, confirmations: 12, want: 988},
+		{name: "confirmations equal to head", head: 12, confirmations: 12, want: 0},
+		{name: "underflow guard", head: 5, confirmations: 12, want: 0},
+		{name: "genesis", head: 0, confirmations: 12, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{blockNumber: func() (uint64, error) { return tt.head, nil }}
+			d := newFakeRPCClient(t, fake, nil, WithConfirmations(tt.confirmations))
+
+			got, err := d.GetSafeBlockNumber(context.Background())
+			if err != nil {
+				t.Fatalf("GetSafeBlockNumber: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetSafeBlockNumber = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFilteredLogsSplitsAddressGroups(t *testing.T) {
+	addresses := []common.Address{
+		common.HexToAddress("0x0000000000000000000000000000000000000a01"),
+		common.HexToAddress("0x0000000000000000000000000000000000000a02"),
+		common.HexToAddress("0x0000000000000000000000000000000000000a03"),
+	}
+	fake := &fakeEthClient{filterLogs: func(query ethereum.FilterQuery) ([]types.Log, error) {
+		if len(query.Addresses) > 2 {
+			return nil, errors.New("too many addresses in filter")
+		}
+		// Every group reports the same shared log besides its own, newest first
+		var logs []types.Log
+		for i := len(query.Addresses) - 1; i >= 0; i-- {
+			addr := query.Addresses[i]
+			logs = append(logs, types.Log{Address: addr, BlockNumber: 2, Index: uint(addr[common.AddressLength-1])})
+		}
+		return append(logs, types.Log{Address: addresses[0], BlockNumber: 1}), nil
+	}}
+	d := newFakeRPCClient(t, fake, addresses, WithMaxAddressesPerQuery(2), WithRetryConfig(RetryConfig{MaxAttempts: 1}))
+
+	logs, err := d.GetFilteredLogs(context.Background(), big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatalf("GetFilteredLogs: %v", err)
+	}
+
+	if queries := len(fake.logQueries); queries != 2 {
+		t.Errorf("made %d queries, want 2", queries)
+	}
+	want := []types.Log{
+		{Address: addresses[0], BlockNumber: 1},
+		{Address: addresses[0], BlockNumber: 2, Index: 1},
+		{Address: addresses[1], BlockNumber: 2, Index: 2},
+		{Address: addresses[2], BlockNumber: 2, Index: 3},
+	}
+	if !reflect.DeepEqual(logs, want) {
+		t.Errorf("got logs %+v, want the shared log once and one per address in order %+v", logs, want)
+	}
+}
+
+func TestGetTxSender(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	origin := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(8453))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(8453),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &common.Address{0xf1},
+	})
+	if err != nil {
+		t.Fatalf("SignNewTx: %v", err)
+	}
+	blockHash := common.HexToHash("0xb10c")
+
+	tests := []struct {
+		name    string
+		pending bool
+		lookup  error
+		wantErr bool
+	}{
+		{name: "mined transaction"},
+		{name: "pending transaction", pending: true, wantErr: true},
+		{name: "unknown transaction", lookup: ethereum.NotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{
+				transaction: func(hash common.Hash) (*types.Transaction, bool, error) {
+					if tt.lookup != nil {
+						return nil, false, tt.lookup
+					}
+					if hash != tx.Hash() {
+						return nil, false, ethereum.NotFound
+					}
+					return tx, tt.pending, nil
+				},
+				receipt: func(txHash common.Hash) (*types.Receipt, error) {
+					return &types.Receipt{TxHash: txHash, BlockHash: blockHash, TransactionIndex: 4}, nil
+				},
+				// Derive the sender from the signature as a node would
+				sender: func(got *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+					if block != blockHash || index != 4 {
+						return common.Address{}, fmt.Errorf("sender requested at %s/%d, want %s/4", block.Hex(), index, blockHash.Hex())
+					}
+					return types.Sender(signer, got)
+				},
+			}
+			d := newFakeRPCClient(t, fake, nil)
+
+			sender, err := d.GetTxSender(context.Background(), tx.Hash())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got sender %s, want error", sender.Hex())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTxSender: %v", err)
+			}
+			if sender != origin {
+				t.Fatalf("got sender %s, want %s", sender.Hex(), origin.Hex())
+			}
+
+			event := &filters.NFTContractCreatedEvent{ContractAddress: common.Address{0xc1}, TxHash: tx.Hash()}
+			if err := d.FillCreatorFromTx(context.Background(), event); err != nil {
+				t.Fatalf("FillCreatorFromTx: %v", err)
+			}
+			if event.Creator != origin {
+				t.Fatalf("filled creator %s, want %s", event.Creator.Hex(), origin.Hex())
+			}
+
+			indexed := common.Address{0xa1}
+			event = &filters.NFTContractCreatedEvent{ContractAddress: common.Address{0xc1}, Creator: indexed, TxHash: tx.Hash()}
+			if err := d.FillCreatorFromTx(context.Background(), event); err != nil {
+				t.Fatalf("FillCreatorFromTx: %v", err)
+			}
+			if event.Creator != indexed {
+				t.Fatalf("indexed creator replaced with %s", event.Creator.Hex())
+			}
+		})
+	}
+}
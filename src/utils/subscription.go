@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// resubscribeBackoff is the delay policy between resubscription attempts
+var resubscribeBackoff = RetryConfig{
+	BaseDelay: time.Second,
+	MaxDelay:  30 * time.Second,
+	Jitter:    true,
+}
+
+// logSubscription is a log subscription that survives transient disconnects
+// Only errors that resubscribing cannot fix are delivered on Err
+type logSubscription struct {
+	err       chan error
+	unsub     chan struct{}
+	unsubOnce sync.Once
+}
+
+// Unsubscribe stops the subscription and closes the error channel
+func (s *logSubscription) Unsubscribe() {
+	s.unsubOnce.Do(func() { close(s.unsub) })
+}
+
+// Err returns the channel receiving a fatal subscription error
+// It is closed once the subscription has stopped
+func (s *logSubscription) Err() <-chan error {
+	return s.err
+}
+
+// stopped reports whether the subscription was unsubscribed
+func (s *logSubscription) stopped() bool {
+	select {
+	case <-s.unsub:
+		return true
+	default:
+		return false
+	}
+}
+
+// SubscribeFilteredLogs streams logs matching the address filter into ch
+// The client must be connected over WebSocket. When the subscription drops,
+// it is re-established with the current address filter after a jittered
+// exponential backoff, re-dialing the endpoint if subscribing on the existing
+// connection fails, so ch keeps receiving logs transparently
+// When the address filter changes, e.g. through UpdateAddressFilter or
+// ApplyAddressDiff, a subscription with the new filter is opened before the
+// old one is dropped, so logs around the switch may be delivered twice
+// Errors that cannot be recovered by resubscribing, such as an endpoint
+// without subscription support, are delivered on the subscription's Err
+// channel, which is closed when the context is cancelled or the returned
+// subscription is unsubscribed
+func (d *DopamintRPCClient) SubscribeFilteredLogs(ctx context.Context, ch chan<- types.Log) (ethereum.Subscription, error) {
+	// Subscribe once up front so configuration errors surface to the caller
+	ep := d.orderedEndpoints()[0]
+	changed := d.filterChanges()
+	initial, err := d.endpointClient(ep).SubscribeFilterLogs(ctx, d.filterQuery(nil, nil), ch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+
+	d.logger.Info("Subscribed to logs", "contracts", len(d.watchedAddresses()))
+
+	sub := &logSubscription{
+		err:   make(chan error, 1),
+		unsub: make(chan struct{}),
+	}
+	go d.maintainSubscription(ctx, sub, initial, changed, ch)
+
+	return sub, nil
+}
+
+// maintainSubscription forwards the lifetime of the current upstream
+// subscription, resubscribing whenever it drops or the address filter
+// changes, until sub is stopped
+func (d *DopamintRPCClient) maintainSubscription(ctx context.Context, sub *logSubscription, current ethereum.Subscription, changed <-chan struct{}, ch chan<- types.Log) {
+	defer close(sub.err)
+
+	for {
+		var lastErr error
+		select {
+		case <-ctx.Done():
+			current.Unsubscribe()
+			return
+		case <-sub.unsub:
+			current.Unsubscribe()
+			return
+		case <-changed:
+			// Subscribe with the new filter before dropping the old
+			// subscription, so no logs are missed in between
+			next, nextChanged, err := d.subscribeLogs(ctx, ch)
+			if err == nil {
+				current.Unsubscribe()
+				current, changed = next, nextChanged
+				d.logger.Info("Resubscribed to logs with updated address filter",
+					"contracts", len(d.watchedAddresses()), "generation", d.FilterGeneration())
+				continue
+			}
+			lastErr = err
+		case lastErr = <-current.Err():
+		}
+		current.Unsubscribe()
+
+		if lastErr == nil {
+			lastErr = errors.New("subscription closed by server")
+		}
+		if isFatalSubscriptionError(lastErr) {
+			d.logger.Error("Log subscription failed", "error", lastErr)
+			sub.err <- lastErr
+			return
+		}
+
+		d.logger.Warn("Log subscription dropped, resubscribing", "error", lastErr)
+		next, nextChanged, err := d.resubscribe(ctx, sub, ch)
+		if err != nil {
+			if ctx.Err() == nil && !sub.stopped() {
+				d.logger.Error("Log subscription failed", "error", err)
+				sub.err <- err
+			}
+			return
+		}
+		current, changed = next, nextChanged
+	}
+}
+
+// resubscribe subscribes again with the current filter, backing off between
+// attempts until one succeeds, a fatal error occurs or sub is stopped
+func (d *DopamintRPCClient) resubscribe(ctx context.Context, sub *logSubscription, ch chan<- types.Log) (ethereum.Subscription, <-chan struct{}, error) {
+	for attempt := 1; ; attempt++ {
+		delay := resubscribeBackoff.backoff(attempt)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-sub.unsub:
+			timer.Stop()
+			return nil, nil, errors.New("subscription stopped")
+		case <-timer.C:
+		}
+
+		next, changed, err := d.subscribeLogs(ctx, ch)
+		if err == nil {
+			d.logger.Info("Resubscribed to logs", "attempt", attempt, "contracts", len(d.watchedAddresses()))
+			return next, changed, nil
+		}
+		if isFatalSubscriptionError(err) {
+			return nil, nil, fmt.Errorf("failed to resubscribe to logs: %w", err)
+		}
+
+		d.logger.Warn("Failed to resubscribe to logs", "attempt", attempt, "delay", delay, "error", err)
+	}
+}
+
+// subscribeLogs subscribes with the current filter on the preferred
+// endpoint, re-dialing it when subscribing on the existing connection fails
+// Also returns a channel that is closed once the filter used changes
+func (d *DopamintRPCClient) subscribeLogs(ctx context.Context, ch chan<- types.Log) (ethereum.Subscription, <-chan struct{}, error) {
+	ep := d.orderedEndpoints()[0]
+	changed := d.filterChanges()
+	query := d.filterQuery(nil, nil)
+
+	next, err := d.endpointClient(ep).SubscribeFilterLogs(ctx, query, ch)
+	if err == nil || isFatalSubscriptionError(err) {
+		return next, changed, err
+	}
+
+	client, dialErr := d.redial(ctx, ep)
+	if dialErr != nil {
+		return nil, nil, errors.Join(err, dialErr)
+	}
+	next, err = client.SubscribeFilterLogs(ctx, query, ch)
+	return next, changed, err
+}
+
+// isFatalSubscriptionError reports whether resubscribing cannot fix err
+func isFatalSubscriptionError(err error) bool {
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range permanentErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSubscriptionFollowsFilterChanges(t *testing.T) {
+	first := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	second := common.HexToAddress("0x0000000000000000000000000000000000000d02")
+
+	tests := []struct {
+		name   string
+		change func(d *DopamintRPCClient)
+		want   []common.Address
+	}{
+		{
+			name:   "UpdateAddressFilter",
+			change: func(d *DopamintRPCClient) { d.UpdateAddressFilter([]common.Address{second}) },
+			want:   []common.Address{second},
+		},
+		{
+			name:   "ApplyAddressDiff",
+			change: func(d *DopamintRPCClient) { d.ApplyAddressDiff([]common.Address{second}, nil) },
+			want:   []common.Address{first, second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEthClient{}
+			d := newFakeRPCClient(t, fake, []common.Address{first})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			sub, err := d.SubscribeFilteredLogs(ctx, make(chan types.Log))
+			if err != nil {
+				t.Fatalf("SubscribeFilteredLogs: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			gen := d.FilterGeneration()
+			tt.change(d)
+			if !d.FilterChangedSince(gen) {
+				t.Fatalf("filter generation not bumped")
+			}
+
+			waitFor(t, "resubscribe", func() bool {
+				subs, _ := fake.subscriptions()
+				return len(subs) == 2 && subs[0].unsubscribed()
+			})
+			subs, queries := fake.subscriptions()
+			if subs[1].unsubscribed() {
+				t.Fatalf("new subscription was dropped")
+			}
+			if !sameAddressSet(queries[1].Addresses, tt.want) || len(queries[1].Addresses) != len(tt.want) {
+				t.Fatalf("resubscribed with %v, want %v", queries[1].Addresses, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionIgnoresNoopFilterUpdates(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	fake := &fakeEthClient{}
+	d := newFakeRPCClient(t, fake, []common.Address{addr})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := d.SubscribeFilteredLogs(ctx, make(chan types.Log))
+	if err != nil {
+		t.Fatalf("SubscribeFilteredLogs: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	gen := d.FilterGeneration()
+	d.UpdateAddressFilter([]common.Address{addr})
+	d.ApplyAddressDiff([]common.Address{addr}, nil)
+	if d.FilterChangedSince(gen) {
+		t.Fatalf("no-op updates bumped the filter generation")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if subs, _ := fake.subscriptions(); len(subs) != 1 {
+		t.Fatalf("got %d subscriptions, want 1", len(subs))
+	}
+}
+
+func TestSubscriptionRecoversFromErrors(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	dropped := errors.New("connection reset by peer")
+
+	tests := []struct {
+		name string
+		// drops are delivered in order on the latest upstream subscription
+		drops []error
+		// failCalls fails the numbered SubscribeFilterLogs calls
+		failCalls map[int]error
+		wantSubs  int
+		wantFatal error
+	}{
+		{
+			name:     "errors twice before stabilizing",
+			drops:    []error{dropped, dropped},
+			wantSubs: 3,
+		},
+		{
+			name:      "errors twice with a failed resubscribe",
+			drops:     []error{dropped, dropped},
+			failCalls: map[int]error{2: errors.New("connection refused")},
+			wantSubs:  3,
+		},
+		{
+			name:      "fatal subscription error",
+			drops:     []error{rpc.ErrNotificationsUnsupported},
+			wantSubs:  1,
+			wantFatal: rpc.ErrNotificationsUnsupported,
+		},
+		{
+			name:      "fatal resubscribe error",
+			drops:     []error{dropped},
+			failCalls: map[int]error{2: rpc.ErrNotificationsUnsupported},
+			wantSubs:  1,
+			wantFatal: rpc.ErrNotificationsUnsupported,
+		},
+	}
+
+	backoff := resubscribeBackoff
+	resubscribeBackoff = RetryConfig{BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	t.Cleanup(func() { resubscribeBackoff = backoff })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fake := &fakeEthClient{
+				// Called with the fake's lock held
+				subscribe: func(ethereum.FilterQuery) error {
+					calls++
+					return tt.failCalls[calls]
+				},
+			}
+			d := newFakeRPCClient(t, fake, []common.Address{addr})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ch := make(chan types.Log, 1)
+			sub, err := d.SubscribeFilteredLogs(ctx, ch)
+			if err != nil {
+				t.Fatalf("SubscribeFilteredLogs: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			for i, drop := range tt.drops {
+				waitFor(t, "subscription", func() bool {
+					subs, _ := fake.subscriptions()
+					return len(subs) == i+1
+				})
+				subs, _ := fake.subscriptions()
+				subs[i].err <- drop
+			}
+
+			if tt.wantFatal != nil {
+				select {
+				case err := <-sub.Err():
+					if !errors.Is(err, tt.wantFatal) {
+						t.Fatalf("got fatal error %v, want %v", err, tt.wantFatal)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("timed out waiting for the fatal error")
+				}
+				if subs, _ := fake.subscriptions(); len(subs) != tt.wantSubs {
+					t.Fatalf("got %d subscriptions, want %d", len(subs), tt.wantSubs)
+				}
+				return
+			}
+
+			waitFor(t, "resubscribe", func() bool {
+				subs, _ := fake.subscriptions()
+				return len(subs) == tt.wantSubs
+			})
+			subs, queries := fake.subscriptions()
+			for i, s := range subs[:len(subs)-1] {
+				waitFor(t, "dropped subscription closed", s.unsubscribed)
+				if s.ch != ch {
+					t.Fatalf("subscription %d did not deliver to the caller's channel", i)
+				}
+			}
+			last := subs[len(subs)-1]
+			if last.unsubscribed() {
+				t.Fatalf("final subscription was dropped")
+			}
+			if got := queries[len(queries)-1].Addresses; len(got) != 1 || got[0] != addr {
+				t.Fatalf("resubscribed with %v, want [%s]", got, addr.Hex())
+			}
+
+			want := types.Log{Address: addr, BlockNumber: 7}
+			last.ch <- want
+			if got := <-ch; got.Address != want.Address || got.BlockNumber != want.BlockNumber {
+				t.Fatalf("got log %+v, want %+v", got, want)
+			}
+
+			select {
+			case err := <-sub.Err():
+				t.Fatalf("unexpected subscription error: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}